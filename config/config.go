@@ -0,0 +1,417 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/export"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/notifications"
+	"github.com/FaresAbuIram/COVID19-Statistics/ratelimit"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultPort = 8080
+
+// Environments the app runs in.
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+)
+
+// Config holds the settings of the app.
+type Config struct {
+	// Environment is EnvironmentDevelopment or EnvironmentProduction, which
+	// hides the GraphQL playground and the schema introspection.
+	Environment string
+	Port        int
+	// TokenSecret signs the JWT tokens given to the users.
+	TokenSecret string
+	// AdminUserIds are the users allowed to change the settings shared by
+	// everyone, such as the subdivision rollup of a country.
+	AdminUserIds []int
+	// SwaggerHost is the host the Swagger docs send the requests to.
+	SwaggerHost string
+	// GraphQLURL is where the REST controllers send their GraphQL queries.
+	GraphQLURL string
+	// AutoMigrate applies the pending migrations on startup.
+	AutoMigrate bool
+	// ShutdownTimeout is how long the in-flight requests and the background
+	// workers may take to finish on shutdown.
+	ShutdownTimeout time.Duration
+	Log             logger.Config
+	Tracing         tracing.Config
+	// TrustedProxies are the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header tells the client IP, none by default.
+	TrustedProxies []string
+	RateLimit      RateLimitConfig
+	GraphQL        graph.ServerConfig
+	Webhooks       webhooks.Config
+	Digests        notifications.Config
+	Exports        export.Config
+	Database       database.Config
+}
+
+// RateLimitConfig holds the limits of each group of routes.
+type RateLimitConfig struct {
+	// Auth limits /login and /register.
+	Auth ratelimit.Limit
+	// Query limits /query.
+	Query ratelimit.Limit
+	// API limits the authenticated routes.
+	API ratelimit.Limit
+	// APIKeys are the keys of the clients given their own limits.
+	APIKeys []string
+}
+
+// Load reads the settings from, by order of precedence, the environment, the
+// optional .env file, the optional YAML or TOML file named by CONFIG_FILE and
+// the defaults. In the file, the settings are named after the variables in
+// lower case and the sections are joined with an underscore, so that
+// db.max_open_conns is the DB_MAX_OPEN_CONNS variable.
+func Load() (*Config, error) {
+	dotenv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error loading .env file: %w", err)
+	}
+	source := &source{values: []map[string]string{environment(), dotenv}}
+
+	if path := source.string("CONFIG_FILE", ""); path != "" {
+		file, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source.values = append(source.values, file)
+	}
+
+	return source.config()
+}
+
+// Production tells whether the app runs in production.
+func (c *Config) Production() bool {
+	return c.Environment == EnvironmentProduction
+}
+
+// Validate checks the settings required to serve the API.
+func (c *Config) Validate() error {
+	if c.Environment != EnvironmentDevelopment && c.Environment != EnvironmentProduction {
+		return fmt.Errorf("invalid APP_ENV %s, expected %s or %s", c.Environment, EnvironmentDevelopment, EnvironmentProduction)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("invalid PORT %d, expected a number between 1 and 65535", c.Port)
+	}
+	if c.TokenSecret == "" {
+		return errors.New("TOKEN_SECRET is required")
+	}
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("invalid SHUTDOWN_TIMEOUT %s, expected a positive duration", c.ShutdownTimeout)
+	}
+	graphQLURL, err := url.Parse(c.GraphQLURL)
+	if err != nil || (graphQLURL.Scheme != "http" && graphQLURL.Scheme != "https") || graphQLURL.Host == "" {
+		return fmt.Errorf("invalid GRAPHQL_URL %s, expected an http or https URL", c.GraphQLURL)
+	}
+	if err := c.Log.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+	if err := c.Webhooks.Validate(); err != nil {
+		return err
+	}
+	if err := c.Digests.Validate(); err != nil {
+		return err
+	}
+	if err := c.Exports.Validate(); err != nil {
+		return err
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES %s, expected IP addresses or CIDRs", proxy)
+		}
+	}
+	return c.Database.Validate()
+}
+
+func (s *source) config() (*Config, error) {
+	config := &Config{
+		Environment:     strings.ToLower(s.string("APP_ENV", EnvironmentDevelopment)),
+		Port:            s.int("PORT", defaultPort),
+		TokenSecret:     s.string("TOKEN_SECRET", ""),
+		AdminUserIds:    s.ints("ADMIN_USER_IDS"),
+		AutoMigrate:     s.bool("AUTO_MIGRATE", false),
+		ShutdownTimeout: s.duration("SHUTDOWN_TIMEOUT", 10*time.Second),
+		Log: logger.Config{
+			Format: strings.ToLower(s.string("LOG_FORMAT", logger.FormatJSON)),
+			Level:  s.level("LOG_LEVEL", slog.LevelInfo),
+		},
+		Tracing: tracing.Config{
+			Exporter:     strings.ToLower(s.string("TRACING_EXPORTER", tracing.ExporterNone)),
+			File:         s.string("TRACING_FILE", ""),
+			OTLPEndpoint: s.string("TRACING_OTLP_ENDPOINT", ""),
+			SampleRatio:  s.float("TRACING_SAMPLE_RATIO", 1),
+		},
+		TrustedProxies: s.list("TRUSTED_PROXIES"),
+		RateLimit: RateLimitConfig{
+			Auth:    s.limit("RATE_LIMIT_AUTH", ratelimit.Limit{Burst: 10, Period: time.Minute}),
+			Query:   s.limit("RATE_LIMIT_QUERY", ratelimit.Limit{Burst: 120, Period: time.Minute}),
+			API:     s.limit("RATE_LIMIT_API", ratelimit.Limit{Burst: 300, Period: time.Minute}),
+			APIKeys: s.list("RATE_LIMIT_API_KEYS"),
+		},
+		GraphQL: graph.ServerConfig{
+			ComplexityLimit:  s.int("GRAPHQL_COMPLEXITY_LIMIT", 200),
+			DepthLimit:       s.int("GRAPHQL_DEPTH_LIMIT", 10),
+			PersistedQueries: s.int("GRAPHQL_PERSISTED_QUERIES", 1000),
+		},
+		Webhooks: webhooks.Config{
+			Timeout:       s.duration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:   s.int("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryDelay:    s.duration("WEBHOOK_RETRY_DELAY", 30*time.Second),
+			MaxRetryDelay: s.duration("WEBHOOK_MAX_RETRY_DELAY", time.Hour),
+			PollInterval:  s.duration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		},
+		Digests: notifications.Config{
+			Hour:          s.int("DIGEST_HOUR", 7),
+			CheckInterval: s.duration("DIGEST_CHECK_INTERVAL", 15*time.Minute),
+			SMTP: notifications.SMTPConfig{
+				Addr:     s.string("SMTP_ADDR", ""),
+				Username: s.string("SMTP_USERNAME", ""),
+				Password: s.string("SMTP_PASSWORD", ""),
+				From:     s.string("SMTP_FROM", ""),
+			},
+		},
+		Exports: export.Config{
+			LinkExpiry:   s.duration("EXPORT_LINK_EXPIRY", 15*time.Minute),
+			RowGroupSize: s.int("EXPORT_ROW_GROUP_SIZE", 10000),
+		},
+		Database: database.Config{
+			Driver:      s.string("DB_DRIVER", database.DriverPostgres),
+			DSN:         s.string("DB_DSN", ""),
+			Host:        s.string("DB_HOST", ""),
+			Port:        s.string("DB_PORT", ""),
+			User:        s.string("DB_USER", ""),
+			Password:    s.string("DB_PASSWORD", ""),
+			Name:        s.string("DB_NAME", ""),
+			SSLMode:     s.string("DB_SSLMODE", "disable"),
+			SSLRootCert: s.string("DB_SSLROOTCERT", ""),
+			Pool: database.PoolConfig{
+				MaxOpenConns:    s.int("DB_MAX_OPEN_CONNS", 25),
+				MaxIdleConns:    s.int("DB_MAX_IDLE_CONNS", 5),
+				ConnMaxLifetime: s.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+				ConnMaxIdleTime: s.duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			},
+			Retry: database.RetryConfig{
+				Attempts: s.int("DB_CONNECT_ATTEMPTS", 5),
+				Delay:    s.duration("DB_CONNECT_RETRY_DELAY", time.Second),
+				MaxDelay: 30 * time.Second,
+			},
+			QueryTimeout: s.duration("DB_QUERY_TIMEOUT", database.DefaultQueryTimeout),
+		},
+	}
+	config.GraphQL.Introspection = !config.Production()
+	config.SwaggerHost = s.string("SWAGGER_HOST", fmt.Sprintf("localhost:%d", config.Port))
+	config.GraphQLURL = s.string("GRAPHQL_URL", fmt.Sprintf("http://localhost:%d/query", config.Port))
+	config.Exports.BaseURL = s.string("EXPORT_BASE_URL", fmt.Sprintf("http://localhost:%d", config.Port))
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	return config, nil
+}
+
+// source looks the settings up in its values, the first ones taking
+// precedence. Empty values are ignored and the first invalid one is kept in
+// err.
+type source struct {
+	values []map[string]string
+	err    error
+}
+
+func (s *source) string(name, defaultValue string) string {
+	for _, values := range s.values {
+		if value := values[name]; value != "" {
+			return value
+		}
+	}
+	return defaultValue
+}
+
+func (s *source) int(name string, defaultValue int) int {
+	value := s.string(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		s.fail(fmt.Errorf("invalid %s %s, expected a positive number", name, value))
+		return defaultValue
+	}
+	return number
+}
+
+func (s *source) duration(name string, defaultValue time.Duration) time.Duration {
+	value := s.string(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		s.fail(fmt.Errorf("invalid %s %s, expected a duration such as 5s", name, value))
+		return defaultValue
+	}
+	return duration
+}
+
+func (s *source) float(name string, defaultValue float64) float64 {
+	value := s.string(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		s.fail(fmt.Errorf("invalid %s %s, expected a number", name, value))
+		return defaultValue
+	}
+	return number
+}
+
+func (s *source) limit(name string, defaultValue ratelimit.Limit) ratelimit.Limit {
+	value := s.string(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	limit, err := ratelimit.ParseLimit(value)
+	if err != nil {
+		s.fail(fmt.Errorf("invalid %s: %w", name, err))
+		return defaultValue
+	}
+	return limit
+}
+
+// list reads comma separated values.
+func (s *source) list(name string) []string {
+	var values []string
+	for _, value := range strings.Split(s.string(name, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ints reads comma separated positive numbers.
+func (s *source) ints(name string) []int {
+	var numbers []int
+	for _, value := range s.list(name) {
+		number, err := strconv.Atoi(value)
+		if err != nil || number <= 0 {
+			s.fail(fmt.Errorf("invalid %s %s, expected comma separated user ids", name, value))
+			return nil
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+func (s *source) bool(name string, defaultValue bool) bool {
+	value := s.string(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		s.fail(fmt.Errorf("invalid %s %s, expected true or false", name, value))
+		return defaultValue
+	}
+	return enabled
+}
+
+func (s *source) level(name string, defaultValue slog.Level) slog.Level {
+	value := s.string(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		s.fail(fmt.Errorf("invalid %s %s, expected debug, info, warn or error", name, value))
+		return defaultValue
+	}
+	return level
+}
+
+func (s *source) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func environment() map[string]string {
+	values := make(map[string]string)
+	for _, variable := range os.Environ() {
+		if name, value, ok := strings.Cut(variable, "="); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// readFile reads a YAML or TOML file, depending on its extension, into the
+// variables it sets.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config file: %w", err)
+	}
+
+	var settings map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &settings)
+	case ".toml":
+		err = toml.Unmarshal(content, &settings)
+	default:
+		return nil, fmt.Errorf("unsupported config file %s, expected a .yaml, .yml or .toml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten(values, "", settings); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(values map[string]string, prefix string, settings map[string]interface{}) error {
+	for key, value := range settings {
+		name := strings.ToUpper(prefix + key)
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if err := flatten(values, name+"_", value); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("unexpected list in %s", strings.ToLower(name))
+		case nil:
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
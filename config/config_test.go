@@ -0,0 +1,302 @@
+package config_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/config"
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/ratelimit"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+)
+
+// clearEnv unsets the variables the config may come from.
+func clearEnv(t *testing.T) {
+	for _, name := range []string{"CONFIG_FILE", "APP_ENV", "GRAPHQL_DEPTH_LIMIT", "PORT", "TOKEN_SECRET", "ADMIN_USER_IDS", "SWAGGER_HOST", "GRAPHQL_URL", "AUTO_MIGRATE", "LOG_LEVEL", "LOG_FORMAT", "TRACING_EXPORTER", "TRACING_FILE", "TRACING_OTLP_ENDPOINT", "TRACING_SAMPLE_RATIO", "TRUSTED_PROXIES", "RATE_LIMIT_AUTH", "RATE_LIMIT_API_KEYS", "DB_DRIVER", "DB_DSN", "DB_HOST", "DB_SSLMODE", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_QUERY_TIMEOUT", "WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_POLL_INTERVAL", "DIGEST_HOUR", "SMTP_ADDR", "SMTP_FROM", "EXPORT_BASE_URL", "EXPORT_ROW_GROUP_SIZE"} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	// prapare data
+	clearEnv(t)
+	t.Setenv("TOKEN_SECRET", "secret")
+
+	cfg, err := config.Load()
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if cfg.Port != 8080 || cfg.SwaggerHost != "localhost:8080" || cfg.GraphQLURL != "http://localhost:8080/query" || cfg.AutoMigrate {
+		t.Errorf("expected the defaults; got %+v", cfg)
+	}
+
+	// Test cases
+	if cfg.RateLimit.Auth != (ratelimit.Limit{Burst: 10, Period: time.Minute}) || cfg.RateLimit.APIKeys != nil || cfg.TrustedProxies != nil {
+		t.Errorf("expected the rate limit defaults; got %+v", cfg.RateLimit)
+	}
+
+	// Test cases
+	if cfg.Production() || !cfg.GraphQL.Introspection || cfg.GraphQL.DepthLimit != 10 || cfg.GraphQL.ComplexityLimit != 200 {
+		t.Errorf("expected the development GraphQL defaults; got %+v", cfg.GraphQL)
+	}
+
+	// Test cases
+	if cfg.Log.Format != logger.FormatJSON || cfg.Log.Level != slog.LevelInfo {
+		t.Errorf("expected JSON logs from the info level; got %+v", cfg.Log)
+	}
+
+	// Test cases
+	if cfg.Database.Driver != database.DriverPostgres || cfg.Database.SSLMode != "disable" || cfg.Database.Pool.MaxOpenConns != 25 || cfg.Database.QueryTimeout != database.DefaultQueryTimeout {
+		t.Errorf("expected the database defaults; got %+v", cfg.Database)
+	}
+
+	// Test cases
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected valid config; got %v", err)
+	}
+}
+
+func TestLoadYAMLFile(t *testing.T) {
+	// prapare data
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", `
+app_env: production
+port: 9090
+token_secret: from-file
+admin_user_ids: 1, 3
+auto_migrate: true
+graphql:
+  depth_limit: 5
+db:
+  driver: sqlite
+  max_open_conns: 10
+  query_timeout: 250ms
+`))
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+
+	cfg, err := config.Load()
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if cfg.Port != 9090 || cfg.TokenSecret != "from-file" || !cfg.AutoMigrate || cfg.GraphQLURL != "http://localhost:9090/query" || cfg.Exports.BaseURL != "http://localhost:9090" {
+		t.Errorf("expected the settings of the file; got %+v", cfg)
+	}
+
+	// Test cases
+	if len(cfg.AdminUserIds) != 2 || cfg.AdminUserIds[0] != 1 || cfg.AdminUserIds[1] != 3 {
+		t.Errorf("expected the admins 1 and 3; got %v", cfg.AdminUserIds)
+	}
+
+	// Test cases
+	if !cfg.Production() || cfg.GraphQL.Introspection || cfg.GraphQL.DepthLimit != 5 {
+		t.Errorf("expected production without introspection and a depth of 5; got %+v", cfg.GraphQL)
+	}
+
+	// Test cases
+	if cfg.Database.Driver != database.DriverSQLite || cfg.Database.QueryTimeout != 250*time.Millisecond {
+		t.Errorf("expected sqlite with a 250ms timeout; got %+v", cfg.Database)
+	}
+
+	// Test cases
+	if cfg.Database.Pool.MaxOpenConns != 20 {
+		t.Errorf("expected the environment to take precedence; got %v", cfg.Database.Pool.MaxOpenConns)
+	}
+}
+
+func TestLoadTOMLFile(t *testing.T) {
+	// prapare data
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.toml", `
+token_secret = "from-file"
+swagger_host = "covid19.example.com"
+
+[db]
+host = "db.example.com"
+sslmode = "verify-full"
+`))
+
+	cfg, err := config.Load()
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if cfg.SwaggerHost != "covid19.example.com" || cfg.Database.Host != "db.example.com" || cfg.Database.SSLMode != "verify-full" {
+		t.Errorf("expected the settings of the file; got %+v", cfg)
+	}
+}
+
+func TestNegativeLoad(t *testing.T) {
+	// prapare data
+	clearEnv(t)
+	t.Setenv("DB_QUERY_TIMEOUT", "soon")
+
+	_, err := config.Load()
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected invalid DB_QUERY_TIMEOUT error; got nil")
+	}
+
+	t.Setenv("DB_QUERY_TIMEOUT", "")
+	t.Setenv("LOG_LEVEL", "loud")
+	_, err = config.Load()
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected invalid LOG_LEVEL error; got nil")
+	}
+
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("TRACING_SAMPLE_RATIO", "half")
+	_, err = config.Load()
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected invalid TRACING_SAMPLE_RATIO error; got nil")
+	}
+
+	t.Setenv("TRACING_SAMPLE_RATIO", "")
+	t.Setenv("RATE_LIMIT_AUTH", "10/day")
+	_, err = config.Load()
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected invalid RATE_LIMIT_AUTH error; got nil")
+	}
+
+	t.Setenv("RATE_LIMIT_AUTH", "")
+	t.Setenv("ADMIN_USER_IDS", "1,admin")
+	_, err = config.Load()
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected invalid ADMIN_USER_IDS error; got nil")
+	}
+
+	t.Setenv("ADMIN_USER_IDS", "")
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.json", `{}`))
+	_, err = config.Load()
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected unsupported config file error; got nil")
+	}
+}
+
+func TestNegativeValidate(t *testing.T) {
+	// prapare data
+	clearEnv(t)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected TOKEN_SECRET is required error; got nil")
+	}
+
+	cfg.TokenSecret = "secret"
+	cfg.GraphQLURL = "localhost:8080/query"
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid GRAPHQL_URL error; got nil")
+	}
+
+	cfg.GraphQLURL = "http://localhost:8080/query"
+	cfg.Log.Format = "xml"
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid log format error; got nil")
+	}
+
+	cfg.Log.Format = logger.FormatText
+	cfg.Tracing.Exporter = tracing.ExporterFile
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected TRACING_FILE is required error; got nil")
+	}
+
+	cfg.Tracing.File = "spans.json"
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "proxy"}
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid TRUSTED_PROXIES error; got nil")
+	}
+
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	cfg.Environment = "staging"
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid APP_ENV error; got nil")
+	}
+
+	cfg.Environment = config.EnvironmentProduction
+	cfg.Webhooks.MaxAttempts = 0
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid WEBHOOK_MAX_ATTEMPTS error; got nil")
+	}
+
+	cfg.Webhooks.MaxAttempts = 8
+	cfg.Digests.SMTP.Addr = "smtp.example.com:587"
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected SMTP_FROM required error; got nil")
+	}
+
+	cfg.Digests.SMTP.From = "digest@example.com"
+	cfg.Digests.Hour = 24
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid DIGEST_HOUR error; got nil")
+	}
+
+	cfg.Digests.Hour = 7
+	cfg.Exports.BaseURL = "localhost:8080"
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected invalid EXPORT_BASE_URL error; got nil")
+	}
+
+	cfg.Exports.BaseURL = "http://localhost:8080"
+	cfg.Database.Pool.MaxIdleConns = 30
+
+	// Test cases
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected more idle than open connections error; got nil")
+	}
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Get alert rules
+// @Summary      Get alert rules
+// @Description  Get the threshold rules of the user and whether their condition held at the last refresh
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Success      200  {object}  []model.AlertRule
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /alert-rules [get]
+func (cc *Covid19Controller) GetAlertRules(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetAlertRules called")
+
+	query := `query {
+					alertRules{
+						id
+						country
+						metric
+						operator
+						threshold
+						triggered
+					}
+				}`
+
+	var data struct {
+		AlertRules []model.AlertRule `json:"alertRules"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetAlertRules failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"rules": data.AlertRules})
+}
+
+// Create alert rule
+// @Summary      Create alert rule
+// @Description  Create a threshold rule on a country of the user. The metric is new_cases, weekly_growth (percent) or case_fatality_rate (percent) and the operator above or below; an alert is raised each time the condition starts to hold after a refresh.
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        body body entity.CreateAlertRuleRequest true "country, metric, operator and threshold"
+// @Success      200  {object}  model.AlertRule
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      409  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /alert-rules [post]
+func (cc *Covid19Controller) CreateAlertRule(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "CreateAlertRule called")
+
+	var userInput entity.CreateAlertRuleRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "CreateAlertRule failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+
+	metric := model.AlertMetric(strings.ToUpper(userInput.Metric))
+	operator := model.AlertOperator(strings.ToUpper(userInput.Operator))
+	if !metric.IsValid() || !operator.IsValid() {
+		cc.Logger.Warn(context.Request.Context(), "invalid alert rule", "metric", userInput.Metric, "operator", userInput.Operator)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid metric or operator"))
+		return
+	}
+
+	mutation := `mutation ($country: String!, $metric: AlertMetric!, $operator: AlertOperator!, $threshold: Float!) {
+		createAlertRule(input: {
+			country: $country,
+			metric: $metric,
+			operator: $operator,
+			threshold: $threshold
+		}){
+			id
+			country
+			metric
+			operator
+			threshold
+			triggered
+		}
+	  }`
+
+	var data struct {
+		CreateAlertRule model.AlertRule `json:"createAlertRule"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"country":   userInput.Country,
+		"metric":    metric,
+		"operator":  operator,
+		"threshold": userInput.Threshold,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "CreateAlertRule failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.CreateAlertRule)
+}
+
+// Delete alert rule
+// @Summary      Delete alert rule
+// @Description  Delete a threshold rule of the user along with its alerts
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        id  path int true "rule id"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /alert-rules/{id} [delete]
+func (cc *Covid19Controller) DeleteAlertRule(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "DeleteAlertRule called")
+
+	ruleId, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "DeleteAlertRule failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid rule id"))
+		return
+	}
+
+	mutation := `mutation ($id: Int!) {
+		deleteAlertRule(input: {
+			id: $id
+		})
+	  }`
+
+	var data struct {
+		DeleteAlertRule bool `json:"deleteAlertRule"`
+	}
+	err = RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{"id": ruleId}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "DeleteAlertRule failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "alert rule deleted successfully"})
+}
+
+// Get alerts
+// @Summary      Get alerts
+// @Description  Get the alert history of the user, the latest first
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        unacknowledged  query bool false "only the alerts not acknowledged yet"
+// @Success      200  {object}  []model.Alert
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /alerts [get]
+func (cc *Covid19Controller) GetAlerts(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetAlerts called")
+
+	unacknowledged := false
+	if value := context.Query("unacknowledged"); value != "" {
+		var err error
+		unacknowledged, err = strconv.ParseBool(value)
+		if err != nil {
+			cc.Logger.Error(context.Request.Context(), "GetAlerts failed", "error", err)
+			context.JSON(http.StatusBadRequest, invalidInput("invalid unacknowledged"))
+			return
+		}
+	}
+
+	query := `query ($unacknowledged: Boolean) {
+					alerts(unacknowledged: $unacknowledged){
+						id
+						ruleId
+						country
+						metric
+						operator
+						threshold
+						value
+						triggeredAt
+						acknowledgedAt
+					}
+				}`
+
+	var data struct {
+		Alerts []model.Alert `json:"alerts"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{"unacknowledged": unacknowledged}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetAlerts failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"alerts": data.Alerts})
+}
+
+// Acknowledge alert
+// @Summary      Acknowledge alert
+// @Description  Mark an alert of the user as seen
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        id  path int true "alert id"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /alerts/{id}/acknowledge [post]
+func (cc *Covid19Controller) AcknowledgeAlert(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "AcknowledgeAlert called")
+
+	alertId, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "AcknowledgeAlert failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid alert id"))
+		return
+	}
+
+	mutation := `mutation ($id: Int!) {
+		acknowledgeAlert(input: {
+			id: $id
+		})
+	  }`
+
+	var data struct {
+		AcknowledgeAlert bool `json:"acknowledgeAlert"`
+	}
+	err = RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{"id": alertId}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "AcknowledgeAlert failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "alert acknowledged successfully"})
+}
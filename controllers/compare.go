@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Compare countries
+// @Summary      Compare countries
+// @Description  Get the aligned daily series of 2 to 5 countries for the given metrics (confirmed, deaths, recovered, active, new_cases, new_deaths) along with a summary of the deltas. The series are aligned by days since the Nth confirmed case when alignAfterCases is set.
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        countries  query string true "comma separated country names"
+// @Param        metrics  query string true "comma separated metrics"
+// @Param        from  query string false "first day (YYYY-MM-DD), defaults to 90 days before to"
+// @Param        to  query string false "last day (YYYY-MM-DD), defaults to today"
+// @Param        alignAfterCases  query int false "align the series on the day each country reached this number of confirmed cases"
+// @Success      200  {object}  model.Comparison
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /compare [get]
+func (cc *Covid19Controller) Compare(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "Compare called")
+
+	countries := splitQueryArray(context.QueryArray("countries"))
+	metrics := make([]model.Metric, 0)
+	for _, value := range splitQueryArray(context.QueryArray("metrics")) {
+		metric := model.Metric(strings.ToUpper(value))
+		if !metric.IsValid() {
+			cc.Logger.Warn(context.Request.Context(), "invalid metric", "metric", value)
+			context.JSON(http.StatusBadRequest, invalidInput("invalid metric "+value))
+			return
+		}
+		metrics = append(metrics, metric)
+	}
+
+	variables := map[string]interface{}{
+		"countries": countries,
+		"metrics":   metrics,
+		"from":      context.Query("from"),
+		"to":        context.Query("to"),
+	}
+	if value := context.Query("alignAfterCases"); value != "" {
+		alignAfterCases, err := strconv.Atoi(value)
+		if err != nil {
+			cc.Logger.Error(context.Request.Context(), "Compare failed", "error", err)
+			context.JSON(http.StatusBadRequest, invalidInput("invalid alignAfterCases"))
+			return
+		}
+		variables["alignAfterCases"] = alignAfterCases
+	}
+
+	query := `query ($countries: [String!]!, $metrics: [Metric!]!, $from: String, $to: String, $alignAfterCases: Int) {
+					compare(countries: $countries, metrics: $metrics, from: $from, to: $to, alignAfterCases: $alignAfterCases){
+						series {
+							country
+							metric
+							points {
+								date
+								day
+								value
+							}
+						}
+						summary {
+							country
+							metric
+							first
+							last
+							change
+							differenceFromFirstCountry
+						}
+					}
+				}`
+
+	var data struct {
+		Compare model.Comparison `json:"compare"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, variables, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "Compare failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.Compare)
+}
+
+// splitQueryArray accepts both repeated query parameters and comma separated
+// values.
+func splitQueryArray(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
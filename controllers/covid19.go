@@ -1,12 +1,11 @@
 package controllers
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/FaresAbuIram/COVID19-Statistics/entity"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
 	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
 	"github.com/gin-gonic/gin"
@@ -14,13 +13,16 @@ import (
 
 type Covid19Controller struct {
 	Resolver *graph.Resolver
-	Logger   logger.LoggerCollection
+	Logger   logger.Logger
+	// QueryURL is the URL of the GraphQL server queried by the handlers.
+	QueryURL string
 }
 
-func NewCovid19Controller(resolver *graph.Resolver, logger logger.LoggerCollection) *Covid19Controller {
+func NewCovid19Controller(resolver *graph.Resolver, logger logger.Logger, queryURL string) *Covid19Controller {
 	return &Covid19Controller{
 		Resolver: resolver,
 		Logger:   logger,
+		QueryURL: queryURL,
 	}
 }
 
@@ -32,67 +34,40 @@ func NewCovid19Controller(resolver *graph.Resolver, logger logger.LoggerCollecti
 // @Param		 Authorization	header		string	true	"Authentication header"
 // @Param        body body entity.AddCountryRequest true "country name"
 // @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      409  {object}	entity.UserResponseFailure
 // @Failure      500  {object}	entity.UserResponseFailure
 // @Router       /country [post]
 func (cc *Covid19Controller) AddNewCountry(context *gin.Context) {
-	cc.Logger.AddInfoLogger("controllers," + "covid19.go," + "AddNewCountry() Func")
+	cc.Logger.Debug(context.Request.Context(), "AddNewCountry called")
 
 	var userInput entity.AddCountryRequest
 	if err := context.BindJSON(&userInput); err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		cc.Logger.Error(context.Request.Context(), "AddNewCountry failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
 		return
 	}
 
 	userId := middleware.GetUserID(context)
 
-	mutation := `mutation {
+	mutation := `mutation ($userId: Int!, $name: String!) {
 		addCountry(input: {
-			name: "%s",
-			userId: %d
-		}) 
+			name: $name,
+			userId: $userId
+		})
 	  }`
-	query := fmt.Sprintf(mutation, userInput.Name, userId)
-
-	// Marshal the mutation variables to JSON
-	queryBody, _ := json.Marshal(map[string]string{
-		"query": query,
-	})
-
-	// Create a new HTTP request to the GraphQL server
-	resp, err := NewQueryRequest(queryBody)
-	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	defer resp.Body.Close()
 
-	var res struct {
-		Data struct {
-			AddCountry bool `json:"addCountry"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+	var data struct {
+		AddCountry bool `json:"addCountry"`
 	}
-
-	err = json.NewDecoder(resp.Body).Decode(&res)
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"userId": userId,
+		"name":   userInput.Name,
+	}, &data)
 	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if res.Data.AddCountry == false {
-		cc.Logger.AddErrorLogger("either country already exists or something wrong")
-		context.JSON(http.StatusInternalServerError, gin.H{"error": "either country already exists or something wrong"})
-		return
-	}
-
-	if len(res.Errors) != 0 {
-		cc.Logger.AddErrorLogger(res.Errors[0].Message)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": res.Errors[0].Message})
+		cc.Logger.Error(context.Request.Context(), "AddNewCountry failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
 
@@ -110,57 +85,29 @@ func (cc *Covid19Controller) AddNewCountry(context *gin.Context) {
 // @Failure      500  {object}	entity.UserResponseFailure
 // @Router       /all-countries [get]
 func (cc *Covid19Controller) GetCountries(context *gin.Context) {
-	cc.Logger.AddInfoLogger("controllers," + "covid19.go," + "GetCountries() Func")
+	cc.Logger.Debug(context.Request.Context(), "GetCountries called")
 
 	userId := middleware.GetUserID(context)
 
-	newQuery := `query {
+	newQuery := `query ($userId: Int!) {
 					list(
-						userId: %d
+						userId: $userId
 					){
 						name
 					}
 				}`
-	query := fmt.Sprintf(newQuery, userId)
-
-	// Marshal the mutation variables to JSON
-	queryBody, _ := json.Marshal(map[string]string{
-		"query": query,
-	})
 
-	// Create a new HTTP request to the GraphQL server
-	resp, err := NewQueryRequest(queryBody)
-	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	var data struct {
+		List []entity.CountryName `json:"list"`
 	}
-
-	defer resp.Body.Close()
-
-	var res struct {
-		Data struct {
-			List []entity.CountryName `json:"list"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&res)
+	err := RunQuery(context.Request.Context(), cc.QueryURL, newQuery, map[string]interface{}{"userId": userId}, &data)
 	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		cc.Logger.Error(context.Request.Context(), "GetCountries failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
 
-	if len(res.Errors) != 0 {
-		cc.Logger.AddErrorLogger(res.Errors[0].Message)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": res.Errors[0].Message})
-		return
-	}
-
-	context.JSON(http.StatusOK, gin.H{"countries": res.Data.List})
+	context.JSON(http.StatusOK, gin.H{"countries": data.List})
 }
 
 // Get the percentage of death cases to confirmed cases for a given country.
@@ -172,64 +119,42 @@ func (cc *Covid19Controller) GetCountries(context *gin.Context) {
 // @Param        name  path string true "country name"
 // @Success      200  {object}  entity.Percentage
 // @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
 // @Failure      500  {object}	entity.UserResponseFailure
 // @Router       /percentage-of-death-to-confirmed/{name} [get]
 func (cc *Covid19Controller) PercentageOfDeathToConfirmed(context *gin.Context) {
-	cc.Logger.AddInfoLogger("controllers," + "covid19.go," + "PercentageOfDeathToConfirmed() Func")
+	cc.Logger.Debug(context.Request.Context(), "PercentageOfDeathToConfirmed called")
 	name := context.Param("name")
 	if name == "" {
-		cc.Logger.AddErrorLogger("missing name")
-		context.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		cc.Logger.Warn(context.Request.Context(), "missing name")
+		context.JSON(http.StatusBadRequest, invalidInput("missing name"))
 		return
 	}
 	userId := middleware.GetUserID(context)
 
-	newQuery := `query {
+	newQuery := `query ($userId: Int!, $name: String!) {
 					percentageeOfDeathToConfirmed(input : {
-							userId:  %d
-							name: "%s"
+							userId: $userId
+							name: $name
 						}
 					)
 				}
 	  `
-	query := fmt.Sprintf(newQuery, userId, name)
-
-	// Marshal the mutation variables to JSON
-	queryBody, _ := json.Marshal(map[string]string{
-		"query": query,
-	})
-
-	// Create a new HTTP request to the GraphQL server
-	resp, err := NewQueryRequest(queryBody)
-	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	defer resp.Body.Close()
 
-	var res struct {
-		Data struct {
-			PercentageeOfDeathToConfirmed float64 `json:"percentageeOfDeathToConfirmed"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+	var data struct {
+		PercentageeOfDeathToConfirmed float64 `json:"percentageeOfDeathToConfirmed"`
 	}
-
-	err = json.NewDecoder(resp.Body).Decode(&res)
+	err := RunQuery(context.Request.Context(), cc.QueryURL, newQuery, map[string]interface{}{
+		"userId": userId,
+		"name":   name,
+	}, &data)
 	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		cc.Logger.Error(context.Request.Context(), "PercentageOfDeathToConfirmed failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
-	if len(res.Errors) != 0 {
-		cc.Logger.AddErrorLogger(res.Errors[0].Message)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": res.Errors[0].Message})
-		return
-	}
-	context.JSON(http.StatusOK, gin.H{"countries": res.Data.PercentageeOfDeathToConfirmed})
+	context.JSON(http.StatusOK, entity.Percentage{Value: data.PercentageeOfDeathToConfirmed})
 }
 
 // Get Top Three Countries based on the case type passed by the user (confirmed, death)
@@ -244,61 +169,96 @@ func (cc *Covid19Controller) PercentageOfDeathToConfirmed(context *gin.Context)
 // @Failure      500  {object}	entity.UserResponseFailure
 // @Router       /top-three-countries/{type} [get]
 func (cc *Covid19Controller) GetTopThreeCountries(context *gin.Context) {
-	cc.Logger.AddInfoLogger("controllers," + "covid19.go," + "GetTopThreeCountries() Func")
+	cc.Logger.Debug(context.Request.Context(), "GetTopThreeCountries called")
 	status := context.Param("type")
 	if status == "" {
-		cc.Logger.AddErrorLogger("missing type")
-		context.JSON(http.StatusBadRequest, gin.H{"error": "missing type"})
+		cc.Logger.Warn(context.Request.Context(), "missing type")
+		context.JSON(http.StatusBadRequest, invalidInput("missing type"))
 		return
 	}
 	userId := middleware.GetUserID(context)
 
-	newQuery := `query {
+	newQuery := `query ($userId: Int!, $type: String!) {
 					getTopThreeCountries(input :{
-							userId: %d
-							type: "%s"
+							userId: $userId
+							type: $type
 						}
 						){
 							name
 					}
 				}
 	  `
-	query := fmt.Sprintf(newQuery, userId, status)
-
-	// Marshal the mutation variables to JSON
-	queryBody, _ := json.Marshal(map[string]string{
-		"query": query,
-	})
 
-	// Create a new HTTP request to the GraphQL server
-	resp, err := NewQueryRequest(queryBody)
+	var data struct {
+		GetTopThreeCountries []entity.CountryName `json:"getTopThreeCountries"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, newQuery, map[string]interface{}{
+		"userId": userId,
+		"type":   status,
+	}, &data)
 	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		cc.Logger.Error(context.Request.Context(), "GetTopThreeCountries failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
+	context.JSON(http.StatusOK, gin.H{"countries": data.GetTopThreeCountries})
+}
 
-	defer resp.Body.Close()
-
-	var res struct {
-		Data struct {
-			GetTopThreeCountries []entity.CountryName `json:"getTopThreeCountries"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+// Get the derived epidemiological indicators for a given country.
+// @Summary      Get the derived epidemiological indicators for a given country.
+// @Description  get the 7-day rolling averages of new cases and deaths, the week-over-week growth, the doubling time and the effective reproduction number estimate of a country for every day between from and to.
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        name  path string true "country name"
+// @Param        from  query string false "first day (YYYY-MM-DD), defaults to 90 days before to"
+// @Param        to  query string false "last day (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  []model.DailyIndicator
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /indicators/{name} [get]
+func (cc *Covid19Controller) GetIndicators(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetIndicators called")
+	name := context.Param("name")
+	if name == "" {
+		cc.Logger.Warn(context.Request.Context(), "missing name")
+		context.JSON(http.StatusBadRequest, invalidInput("missing name"))
+		return
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&res)
-	if err != nil {
-		cc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	newQuery := `query ($name: String!, $from: String, $to: String) {
+					indicators(input: {
+							name: $name
+							from: $from
+							to: $to
+						}
+					){
+						date
+						confirmed
+						deaths
+						newCases
+						newDeaths
+						newCasesAverage
+						newDeathsAverage
+						weekOverWeekGrowth
+						doublingTime
+						reproductionNumber
+					}
+				}
+	  `
+
+	var data struct {
+		Indicators []model.DailyIndicator `json:"indicators"`
 	}
-	if len(res.Errors) != 0 {
-		cc.Logger.AddErrorLogger(res.Errors[0].Message)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": res.Errors[0].Message})
+	err := RunQuery(context.Request.Context(), cc.QueryURL, newQuery, map[string]interface{}{
+		"name": name,
+		"from": context.Query("from"),
+		"to":   context.Query("to"),
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetIndicators failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
-	context.JSON(http.StatusOK, gin.H{"countries": res.Data.GetTopThreeCountries})
+	context.JSON(http.StatusOK, gin.H{"indicators": data.Indicators})
 }
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Get digest preferences
+// @Summary      Get digest preferences
+// @Description  Get the channels the user receives digests through, with their destination and frequency
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Success      200  {object}  []model.DigestPreference
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /digest-preferences [get]
+func (cc *Covid19Controller) GetDigestPreferences(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetDigestPreferences called")
+
+	query := `query {
+					digestPreferences{
+						channel
+						destination
+						frequency
+						lastSentAt
+					}
+				}`
+
+	var data struct {
+		DigestPreferences []model.DigestPreference `json:"digestPreferences"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetDigestPreferences failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"preferences": data.DigestPreferences})
+}
+
+// Set digest preference
+// @Summary      Set digest preference
+// @Description  Receive a daily or weekly digest of the followed countries through email or chat, off opting out. The email destination defaults to the email of the user, the chat one is a Slack or Microsoft Teams incoming webhook URL.
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        channel  path string true "email or chat"
+// @Param        body body entity.DigestPreferenceRequest true "destination and frequency: daily, weekly or off"
+// @Success      200  {object}  model.DigestPreference
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /digest-preferences/{channel} [put]
+func (cc *Covid19Controller) SetDigestPreference(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "SetDigestPreference called")
+
+	var userInput entity.DigestPreferenceRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "SetDigestPreference failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+
+	channel := model.DigestChannel(strings.ToUpper(context.Param("channel")))
+	if !channel.IsValid() {
+		cc.Logger.Warn(context.Request.Context(), "invalid digest channel", "channel", context.Param("channel"))
+		context.JSON(http.StatusBadRequest, invalidInput("invalid channel "+context.Param("channel")))
+		return
+	}
+	frequency := model.DigestFrequency(strings.ToUpper(userInput.Frequency))
+	if !frequency.IsValid() {
+		cc.Logger.Warn(context.Request.Context(), "invalid digest frequency", "frequency", userInput.Frequency)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid frequency "+userInput.Frequency))
+		return
+	}
+	var destination *string
+	if userInput.Destination != "" {
+		destination = &userInput.Destination
+	}
+
+	mutation := `mutation ($channel: DigestChannel!, $destination: String, $frequency: DigestFrequency!) {
+		setDigestPreference(input: {
+			channel: $channel,
+			destination: $destination,
+			frequency: $frequency
+		}){
+			channel
+			destination
+			frequency
+			lastSentAt
+		}
+	  }`
+
+	var data struct {
+		SetDigestPreference model.DigestPreference `json:"setDigestPreference"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"channel":     channel,
+		"destination": destination,
+		"frequency":   frequency,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "SetDigestPreference failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.SetDigestPreference)
+}
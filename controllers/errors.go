@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+)
+
+// QueryError is a GraphQL error along with its extensions.code, if any.
+type QueryError struct {
+	Message string
+	Code    string
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+// errorStatus returns the HTTP status matching the code of a GraphQL error,
+// 500 for the errors without code.
+func errorStatus(err error) int {
+	var queryError *QueryError
+	if !errors.As(err, &queryError) {
+		return http.StatusInternalServerError
+	}
+
+	switch queryError.Code {
+	case graph.ErrorCodeValidation, errcode.ValidationFailed, errcode.ParseFailed:
+		return http.StatusBadRequest
+	case graph.ErrorCodeNotFound:
+		return http.StatusNotFound
+	case graph.ErrorCodeConflict:
+		return http.StatusConflict
+	case graph.ErrorCodeUnauthenticated:
+		return http.StatusUnauthorized
+	case graph.ErrorCodeForbidden:
+		return http.StatusForbidden
+	case graph.ErrorCodeUpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// errorBody returns the response of a failed request. Only the message of
+// the GraphQL errors is shown to the clients, the other errors, such as the
+// GraphQL server being unreachable, are internal.
+func errorBody(err error) entity.UserResponseFailure {
+	var queryError *QueryError
+	if !errors.As(err, &queryError) {
+		return entity.UserResponseFailure{Error: graph.InternalErrorMessage, Code: graph.ErrorCodeInternal}
+	}
+	return entity.UserResponseFailure{Error: queryError.Message, Code: queryError.Code}
+}
+
+// serviceError turns an error of a service called without the GraphQL
+// server, such as by the streaming handlers, into the QueryError the server
+// would have answered.
+func serviceError(ctx context.Context, err error) error {
+	presented := graph.ErrorPresenter(ctx, err)
+	code, _ := presented.Extensions["code"].(string)
+	return &QueryError{Message: presented.Message, Code: code}
+}
+
+// invalidInput returns the response of a request whose input can't be read.
+func invalidInput(message string) entity.UserResponseFailure {
+	return entity.UserResponseFailure{Error: message, Code: graph.ErrorCodeValidation}
+}
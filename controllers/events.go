@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LastEventIDHeader is sent by the EventSource clients reconnecting, it
+// holds the ID of the last event they got.
+const LastEventIDHeader = "Last-Event-ID"
+
+type EventsController struct {
+	Covid19Service *services.Covid19Service
+	Logger         logger.Logger
+	// Heartbeat is how often a comment is sent on idle streams, so that the
+	// proxies keep them open.
+	Heartbeat time.Duration
+	// WriteTimeout bounds each write, the clients reading slower are
+	// disconnected and may resume with Last-Event-ID.
+	WriteTimeout time.Duration
+	// Done ends the streams, such as when the server shuts down.
+	Done <-chan struct{}
+}
+
+func NewEventsController(covid19Service *services.Covid19Service, logger logger.Logger) *EventsController {
+	return &EventsController{
+		Covid19Service: covid19Service,
+		Logger:         logger,
+		Heartbeat:      15 * time.Second,
+		WriteTimeout:   10 * time.Second,
+	}
+}
+
+// Stream events
+// @Summary      Stream events
+// @Description  Streams the Server-Sent Events of the countries of the user: statistics-changed once the refresher stored new totals and threshold-alert when one of their alert rules starts to hold. Reconnecting with Last-Event-ID replays the recent events missed.
+// @Produce      text/event-stream
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param		 Last-Event-ID	header		string	false	"ID of the last event received"
+// @Success      200  {string}  string
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /events [get]
+func (ec *EventsController) Stream(context *gin.Context) {
+	ctx := context.Request.Context()
+	ec.Logger.Debug(ctx, "Stream called")
+
+	var lastEventID *uint64
+	if header := context.GetHeader(LastEventIDHeader); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			ec.Logger.Warn(ctx, "invalid Last-Event-ID", "last_event_id", header)
+			context.JSON(http.StatusBadRequest, invalidInput("Invalid Last-Event-ID header"))
+			return
+		}
+		lastEventID = &id
+	}
+
+	published, err := ec.Covid19Service.SubscribeEvents(ctx, middleware.GetUserID(context), lastEventID)
+	if err != nil {
+		err = serviceError(ctx, err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.Header("Content-Type", "text/event-stream")
+	context.Header("Cache-Control", "no-cache")
+	context.Header("Connection", "keep-alive")
+	context.Header("X-Accel-Buffering", "no")
+	context.Status(http.StatusOK)
+
+	heartbeat := time.NewTicker(ec.Heartbeat)
+	defer heartbeat.Stop()
+	controller := http.NewResponseController(context.Writer)
+	write := func(message string) bool {
+		controller.SetWriteDeadline(time.Now().Add(ec.WriteTimeout))
+		if _, err := context.Writer.WriteString(message); err != nil {
+			ec.Logger.Warn(ctx, "Stream write failed", "error", err)
+			return false
+		}
+		context.Writer.Flush()
+		return true
+	}
+
+	if !write(": connected\n\n") {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ec.Done:
+			return
+		case <-heartbeat.C:
+			if !write(": heartbeat\n\n") {
+				return
+			}
+		case event, ok := <-published:
+			if !ok {
+				if ctx.Err() == nil {
+					ec.Logger.Warn(ctx, "Stream dropped, the client is too slow")
+				}
+				return
+			}
+			if !write(formatEvent(event)) {
+				return
+			}
+		}
+	}
+}
+
+// formatEvent returns the event in the text/event-stream format.
+func formatEvent(event events.Event) string {
+	data, _ := json.Marshal(event.Data)
+	return fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,192 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/controllers"
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/gin-gonic/gin"
+)
+
+// streamRecorder records a stream while the test reads it. With blocked set,
+// the writes wait for it to be closed, like a client not reading.
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+	mu      sync.Mutex
+	blocked chan struct{}
+}
+
+func (r *streamRecorder) Write(data []byte) (int, error) {
+	if r.blocked != nil {
+		<-r.blocked
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Write(data)
+}
+
+func (r *streamRecorder) WriteString(data string) (int, error) {
+	return r.Write([]byte(data))
+}
+
+func (r *streamRecorder) body() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Body.String()
+}
+
+// waitFor waits for the stream to hold the text.
+func (r *streamRecorder) waitFor(t *testing.T, text string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(r.body(), text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the stream to hold %q; got %q", text, r.body())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// newEventsController returns a controller streaming the events of a user
+// following Palestine, along with the bus and the id of the user.
+func newEventsController(t *testing.T, log logger.Logger) (*controllers.EventsController, *events.Bus, int) {
+	t.Helper()
+	ctx := context.Background()
+	memoryRepository := database.NewMemoryRepository()
+	covid19Service := services.NewCovid19Service(memoryRepository, logger.Discard())
+	covid19Service.Events = events.NewBus(events.DefaultReplay)
+
+	if err := memoryRepository.InsertNewUser(ctx, "test@test.com", []byte("hashed")); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	userId, _, _ := memoryRepository.FindUserByEmail(ctx, "test@test.com")
+	if _, err := covid19Service.AddCountry(ctx, "Palestine", userId); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	eventsController := controllers.NewEventsController(covid19Service, log)
+	eventsController.Heartbeat = time.Hour
+	eventsController.WriteTimeout = time.Second
+	return eventsController, covid19Service.Events, userId
+}
+
+// stream serves the events of the user in the background, the returned
+// channel being closed once the handler returned.
+func stream(ctx context.Context, eventsController *controllers.EventsController, userId int, recorder *streamRecorder, headers map[string]string) <-chan struct{} {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events", func(context *gin.Context) {
+		context.Set("user_id", userId)
+	}, eventsController.Stream)
+
+	request := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(recorder, request)
+	}()
+	return done
+}
+
+func publish(bus *events.Bus, country string) int {
+	return bus.Publish(events.Event{
+		Type:    events.TypeStatisticsChanged,
+		Country: country,
+		Data:    events.StatisticsUpdated{Country: country, Confirmed: 10},
+	})
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	// prapare data
+	eventsController, _, userId := newEventsController(t, logger.Discard())
+	eventsController.Heartbeat = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := &streamRecorder{ResponseRecorder: httptest.NewRecorder()}
+	done := stream(ctx, eventsController, userId, recorder, nil)
+
+	// Test cases
+	recorder.waitFor(t, ": connected\n\n: heartbeat\n\n")
+	cancel()
+	<-done
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("expected a 200 text/event-stream; got %d and %q", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestStreamReplaysAfterLastEventID(t *testing.T) {
+	// prapare data
+	eventsController, bus, userId := newEventsController(t, logger.Discard())
+	for _, country := range []string{"Palestine", "Jordan", "Palestine"} {
+		publish(bus, country)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := &streamRecorder{ResponseRecorder: httptest.NewRecorder()}
+	done := stream(ctx, eventsController, userId, recorder, map[string]string{controllers.LastEventIDHeader: "1"})
+
+	// Test cases
+	recorder.waitFor(t, "id: 3\nevent: statistics-changed\n")
+	publish(bus, "Palestine")
+	recorder.waitFor(t, "id: 4\n")
+	cancel()
+	<-done
+	if body := recorder.body(); strings.Contains(body, "id: 1\n") || strings.Contains(body, "id: 2\n") {
+		t.Errorf("expected only the events after 1 of the followed countries; got %q", body)
+	}
+}
+
+func TestStreamInvalidLastEventID(t *testing.T) {
+	// prapare data
+	eventsController, _, userId := newEventsController(t, logger.Discard())
+	recorder := &streamRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	<-stream(context.Background(), eventsController, userId, recorder, map[string]string{controllers.LastEventIDHeader: "invalid"})
+
+	// Test cases
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected %d; got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestStreamDropsSlowConsumer(t *testing.T) {
+	// prapare data
+	output := &bytes.Buffer{}
+	eventsController, bus, userId := newEventsController(t, logger.New(output, logger.Config{Format: logger.FormatJSON, Level: slog.LevelInfo}))
+	recorder := &streamRecorder{ResponseRecorder: httptest.NewRecorder(), blocked: make(chan struct{})}
+	done := stream(context.Background(), eventsController, userId, recorder, nil)
+
+	// the handler subscribed then got stuck writing, so the events pile up
+	// until the bus drops it
+	time.Sleep(50 * time.Millisecond)
+	dropped := 0
+	for i := 0; i < 2*events.DefaultBuffer && dropped == 0; i++ {
+		dropped = publish(bus, "Palestine")
+	}
+	close(recorder.blocked)
+
+	// Test cases
+	if dropped != 1 {
+		t.Fatalf("expected the slow consumer to be dropped; got %d dropped", dropped)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the stream to end once dropped")
+	}
+	if !strings.Contains(recorder.body(), "id: 1\n") || !strings.Contains(output.String(), "the client is too slow") {
+		t.Errorf("expected the buffered events then the stream dropped; got %q and %q", recorder.body(), output.String())
+	}
+}
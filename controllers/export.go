@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Export statistics
+// @Summary      Export statistics
+// @Description  Streams the statistics of the countries of the user as CSV, NDJSON or Parquet, with the columns country, date, confirmed, deaths, recovered, new_confirmed and new_deaths. The daily dataset holds the snapshots between from and to, the last 90 days by default, and the totals one the latest totals. The links of the exportLink GraphQL query hold a token replacing the authorization header.
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.apache.parquet
+// @Param		 Authorization	header		string	false	"Authentication header, unless token is given"
+// @Param        dataset  query string false "daily or totals, daily by default"
+// @Param        format  query string false "csv, ndjson or parquet, csv by default"
+// @Param        countries  query string false "comma separated countries of the user, all of them by default"
+// @Param        from  query string false "YYYY-MM-DD"
+// @Param        to  query string false "YYYY-MM-DD"
+// @Param        token  query string false "token of an export link"
+// @Success      200  {string}  string
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      401  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /export [get]
+func (cc *Covid19Controller) Export(context *gin.Context) {
+	ctx := context.Request.Context()
+	cc.Logger.Debug(ctx, "Export called")
+
+	input := model.ExportInput{
+		Dataset: model.ExportDataset(strings.ToUpper(context.DefaultQuery("dataset", "daily"))),
+		Format:  model.ExportFormat(strings.ToUpper(context.DefaultQuery("format", "csv"))),
+	}
+	for _, countries := range context.QueryArray("countries") {
+		for _, country := range strings.Split(countries, ",") {
+			if country = strings.TrimSpace(country); country != "" {
+				input.Countries = append(input.Countries, country)
+			}
+		}
+	}
+	if from, ok := context.GetQuery("from"); ok {
+		input.From = &from
+	}
+	if to, ok := context.GetQuery("to"); ok {
+		input.To = &to
+	}
+
+	// the export is streamed by the service itself, the GraphQL server
+	// answering JSON only
+	exported, err := cc.Resolver.Covid19Service.NewExport(ctx, middleware.GetUserID(context), input)
+	if err != nil {
+		err = serviceError(ctx, err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.Header("Content-Type", exported.ContentType())
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exported.Filename()))
+	context.Status(http.StatusOK)
+	if err := exported.Write(ctx, context.Writer); err != nil {
+		// the status is already sent, the client gets a truncated export
+		cc.Logger.Error(ctx, "Export failed", "error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	HealthService *services.HealthService
+	Logger        logger.Logger
+}
+
+func NewHealthController(healthService *services.HealthService, logger logger.Logger) *HealthController {
+	return &HealthController{
+		HealthService: healthService,
+		Logger:        logger,
+	}
+}
+
+// Liveness
+// @Summary      Liveness probe
+// @Description  Answers as long as the server is running
+// @Produce      json
+// @Success      200  {object}  entity.HealthResponse
+// @Router       /healthz [get]
+func (hc *HealthController) Liveness(context *gin.Context) {
+	context.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness
+// @Summary      Readiness probe
+// @Description  Checks that the database and the upstream COVID-19 API are reachable
+// @Produce      json
+// @Success      200  {object}  entity.ReadinessResponse
+// @Failure      503  {object}  entity.ReadinessResponse
+// @Router       /readyz [get]
+func (hc *HealthController) Readiness(context *gin.Context) {
+	ready, checks := hc.HealthService.Readiness(context.Request.Context())
+	if !ready {
+		hc.Logger.Warn(context.Request.Context(), "not ready", "checks", checks)
+		context.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	context.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
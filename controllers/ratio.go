@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Get the ratio between two statistics of a given country.
+// @Summary      get the ratio between two statistics of a given country.
+// @Description  get the ratio between two statistics (confirmed, deaths, recovered, active) of a country subscribed by the user, for the latest statistics or for a given date. The value is null when the denominator is 0.
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        name  path string true "country name"
+// @Param        numerator  query string false "numerator, defaults to deaths"
+// @Param        denominator  query string false "denominator, defaults to confirmed"
+// @Param        date  query string false "day of the statistics (YYYY-MM-DD), defaults to the latest"
+// @Success      200  {object}  model.Ratio
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /ratio/{name} [get]
+func (cc *Covid19Controller) GetRatio(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetRatio called")
+
+	numerator := model.RatioMetric(strings.ToUpper(context.DefaultQuery("numerator", "deaths")))
+	denominator := model.RatioMetric(strings.ToUpper(context.DefaultQuery("denominator", "confirmed")))
+	if !numerator.IsValid() || !denominator.IsValid() {
+		cc.Logger.Warn(context.Request.Context(), "invalid numerator or denominator")
+		context.JSON(http.StatusBadRequest, invalidInput("invalid numerator or denominator"))
+		return
+	}
+
+	query := `query ($name: String!, $numerator: RatioMetric!, $denominator: RatioMetric!, $date: String) {
+					ratio(input: {
+							name: $name
+							numerator: $numerator
+							denominator: $denominator
+							date: $date
+						}
+					){
+						country
+						date
+						numerator
+						denominator
+						numeratorValue
+						denominatorValue
+						value
+						percentage
+					}
+				}`
+
+	var data struct {
+		Ratio model.Ratio `json:"ratio"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{
+		"name":        context.Param("name"),
+		"numerator":   numerator,
+		"denominator": denominator,
+		"date":        context.Query("date"),
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetRatio failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.Ratio)
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Get all regions
+// @Summary      Get all regions
+// @Description  Get the continents, the WHO regions and the custom regions of the user
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Success      200  {object}  []model.Region
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /regions [get]
+func (cc *Covid19Controller) GetRegions(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetRegions called")
+
+	query := `query {
+					regions{
+						name
+						type
+					}
+				}`
+
+	var data struct {
+		Regions []model.Region `json:"regions"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetRegions failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"regions": data.Regions})
+}
+
+// Create new region
+// @Summary      Create new region
+// @Description  Create a custom group of countries for the user
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        body body entity.CreateRegionRequest true "region name and countries"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      409  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /regions [post]
+func (cc *Covid19Controller) CreateRegion(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "CreateRegion called")
+
+	var userInput entity.CreateRegionRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "CreateRegion failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+	if userInput.Countries == nil {
+		userInput.Countries = []string{}
+	}
+
+	mutation := `mutation ($name: String!, $countries: [String!]!) {
+		createRegion(input: {
+			name: $name,
+			countries: $countries
+		})
+	  }`
+
+	var data struct {
+		CreateRegion bool `json:"createRegion"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"name":      userInput.Name,
+		"countries": userInput.Countries,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "CreateRegion failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "region created successfully"})
+}
+
+// Add country to region
+// @Summary      Add country to region
+// @Description  Add a country to a custom region of the user, the continents and WHO regions being read-only
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        region  path string true "region name"
+// @Param        body body entity.AddCountryRequest true "country name"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /regions/{region}/countries [post]
+func (cc *Covid19Controller) AddCountryToRegion(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "AddCountryToRegion called")
+
+	var userInput entity.AddCountryRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "AddCountryToRegion failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+
+	mutation := `mutation ($region: String!, $name: String!) {
+		addCountryToRegion(input: {
+			region: $region,
+			name: $name
+		})
+	  }`
+
+	var data struct {
+		AddCountryToRegion bool `json:"addCountryToRegion"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"region": context.Param("region"),
+		"name":   userInput.Name,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "AddCountryToRegion failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "country added successfully"})
+}
+
+// Get the aggregated statistics of a region
+// @Summary      Get the aggregated statistics of a region
+// @Description  Get the totals of a region (use World for every country) along with the statistics of each of its countries, the countries nobody follows having no statistics yet and being listed apart
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        region  path string true "region name"
+// @Success      200  {object}  model.RegionAggregate
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /regions/{region}/aggregate [get]
+func (cc *Covid19Controller) GetRegionAggregate(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetRegionAggregate called")
+
+	query := `query ($region: String!) {
+					aggregate(input: {
+							region: $region
+						}
+					){
+						region
+						confirmed
+						deaths
+						recovered
+						active
+						countries {
+							name
+							confirmed
+							deaths
+							recovered
+							active
+						}
+						missingCountries
+					}
+				}`
+
+	var data struct {
+		Aggregate model.RegionAggregate `json:"aggregate"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{
+		"region": context.Param("region"),
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetRegionAggregate failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.Aggregate)
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Add new subdivision
+// @Summary      Add new subdivision
+// @Description  Subscribe the user to a province or state of a country
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        body body entity.AddSubdivisionRequest true "country and subdivision names"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      409  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /subdivision [post]
+func (cc *Covid19Controller) AddSubdivision(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "AddSubdivision called")
+
+	var userInput entity.AddSubdivisionRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "AddSubdivision failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+
+	mutation := `mutation ($country: String!, $name: String!) {
+		addSubdivision(input: {
+			country: $country,
+			name: $name
+		})
+	  }`
+
+	var data struct {
+		AddSubdivision bool `json:"addSubdivision"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"country": userInput.Country,
+		"name":    userInput.Name,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "AddSubdivision failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "subdivision added successfully"})
+}
+
+// Get all subdivisions
+// @Summary      Get all subdivisions
+// @Description  Get the statistics of the subdivisions subscribed by the user
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Success      200  {object}  []model.SubdivisionStatistics
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /all-subdivisions [get]
+func (cc *Covid19Controller) GetSubdivisions(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetSubdivisions called")
+
+	query := `query {
+					subdivisions{
+						country
+						name
+						confirmed
+						deaths
+						recovered
+						active
+					}
+				}`
+
+	var data struct {
+		Subdivisions []model.SubdivisionStatistics `json:"subdivisions"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetSubdivisions failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"subdivisions": data.Subdivisions})
+}
+
+// Get the subdivisions of a country
+// @Summary      Get the subdivisions of a country
+// @Description  Get the statistics of every known province or state of a country
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        name  path string true "country name"
+// @Success      200  {object}  []model.SubdivisionStatistics
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /subdivisions/{name} [get]
+func (cc *Covid19Controller) GetCountrySubdivisions(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetCountrySubdivisions called")
+
+	query := `query ($name: String!) {
+					countrySubdivisions(name: $name){
+						country
+						name
+						confirmed
+						deaths
+						recovered
+						active
+					}
+				}`
+
+	var data struct {
+		CountrySubdivisions []model.SubdivisionStatistics `json:"countrySubdivisions"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{"name": context.Param("name")}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetCountrySubdivisions failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"subdivisions": data.CountrySubdivisions})
+}
+
+// Roll up country totals from subdivisions
+// @Summary      Roll up country totals from subdivisions
+// @Description  Choose whether the totals of a country are taken from the API or computed from the sum of its subdivisions, for every user, which only the admins can do
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        name  path string true "country name"
+// @Param        body body entity.SubdivisionRollupRequest true "roll up or not"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /subdivisions/{name}/rollup [put]
+func (cc *Covid19Controller) SetSubdivisionRollup(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "SetSubdivisionRollup called")
+
+	var userInput entity.SubdivisionRollupRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "SetSubdivisionRollup failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+
+	mutation := `mutation ($country: String!, $enabled: Boolean!) {
+		setSubdivisionRollup(input: {
+			country: $country,
+			enabled: $enabled
+		})
+	  }`
+
+	var data struct {
+		SetSubdivisionRollup bool `json:"setSubdivisionRollup"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"country": context.Param("name"),
+		"enabled": userInput.Enabled,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "SetSubdivisionRollup failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "country updated successfully"})
+}
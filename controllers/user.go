@@ -2,45 +2,66 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"strconv"
 
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type UserController struct {
 	Resolver *graph.Resolver
-	Logger   logger.LoggerCollection
+	// Server is the GraphQL server answering Query, built once.
+	Server   http.Handler
+	Logger   logger.Logger
+	// QueryURL is the URL of the GraphQL server queried by the handlers.
+	QueryURL string
 }
 
 type QueryRequest interface {
-	NewQueryRequest(queryBody []byte) (*http.Response, error)
+	NewQueryRequest(ctx context.Context, queryURL string, queryBody []byte) (*http.Response, error)
 }
 
-func NewUserController(resolver *graph.Resolver, logger logger.LoggerCollection) *UserController {
+func NewUserController(resolver *graph.Resolver, server http.Handler, logger logger.Logger, queryURL string) *UserController {
 	return &UserController{
 		Resolver: resolver,
+		Server:   server,
 		Logger:   logger,
+		QueryURL: queryURL,
 	}
 }
 
 func (uc *UserController) Query(context *gin.Context) {
-	h := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: uc.Resolver}))
-
-	h.ServeHTTP(context.Writer, context.Request)
+	uc.Server.ServeHTTP(context.Writer, context.Request)
 }
 
-func NewQueryRequest(queryBody []byte) (*http.Response, error) {
-	request, err := http.NewRequest("POST", "http://localhost:8080/query", bytes.NewBuffer(queryBody))
+// NewQueryRequest posts the query to the GraphQL server at queryURL, the
+// request is cancelled along with ctx.
+func NewQueryRequest(ctx context.Context, queryURL string, queryBody []byte) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", queryURL, bytes.NewBuffer(queryBody))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Set("Content-Type", "application/json")
+	// keep the ID of the request served and its trace, so that the logs and
+	// the spans of both match
+	if requestID, ok := logger.ContextValue(ctx, "request_id").(string); ok {
+		request.Header.Set(middleware.RequestIDHeader, requestID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+	// the request was already limited on its own route
+	request.Header.Set(middleware.InternalRequestHeader, middleware.InternalToken())
+	// and its user was authenticated by the handler
+	if userID := graph.UserIDFromContext(ctx); userID != 0 {
+		request.Header.Set(middleware.InternalUserHeader, strconv.Itoa(userID))
+	}
 	// Send the HTTP request and read the response
 	client := &http.Client{}
 	resp, err := client.Do(request)
@@ -51,6 +72,48 @@ func NewQueryRequest(queryBody []byte) (*http.Response, error) {
 	return resp, nil
 }
 
+// RunQuery sends the query and its variables to the GraphQL server at
+// queryURL and decodes the data of the response into data. The first GraphQL
+// error, if any, is returned as a *QueryError.
+func RunQuery(ctx context.Context, queryURL, query string, variables map[string]interface{}, data interface{}) error {
+	// Marshal the query and its variables to JSON
+	queryBody, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Create a new HTTP request to the GraphQL server
+	resp, err := NewQueryRequest(ctx, queryURL, queryBody)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	var res struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return err
+	}
+	if len(res.Errors) != 0 {
+		return &QueryError{Message: res.Errors[0].Message, Code: res.Errors[0].Extensions.Code}
+	}
+
+	return json.Unmarshal(res.Data, data)
+}
+
 // Create New User
 // @Summary      Create New User
 // @Description  Create New User with email and password
@@ -58,61 +121,40 @@ func NewQueryRequest(queryBody []byte) (*http.Response, error) {
 // @Produce      json
 // @Param        body body model.RegisterInput true "email and password"
 // @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      409  {object}	entity.UserResponseFailure
 // @Failure      500  {object}	entity.UserResponseFailure
 // @Router       /register [post]
 func (uc *UserController) Register(context *gin.Context) {
-	uc.Logger.AddInfoLogger("controllers," + "user.go," + "Register() Func")
+	uc.Logger.Debug(context.Request.Context(), "Register called")
 
 	var userInput model.RegisterInput
 	if err := context.BindJSON(&userInput); err != nil {
-		uc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		uc.Logger.Error(context.Request.Context(), "Register failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
 		return
 	}
 
-	mutation := `mutation {
+	mutation := `mutation ($email: String!, $password: String!) {
 				register(input: {
-					email: "%s",
-					password: "%s"
-				}) 
+					email: $email,
+					password: $password
+				})
 	  		}`
-	query := fmt.Sprintf(mutation, userInput.Email, userInput.Password)
-
-	// Marshal the mutation variables to JSON
-	queryBody, _ := json.Marshal(map[string]string{
-		"query": query,
-	})
 
-	// Create a new HTTP request to the GraphQL server
-	resp, err := NewQueryRequest(queryBody)
-	if err != nil {
-		uc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	var data struct {
+		Register bool `json:"register"`
 	}
-
-	defer resp.Body.Close()
-
-	var res struct {
-		Data struct {
-			Register bool `json:"register"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&res)
+	err := RunQuery(context.Request.Context(), uc.QueryURL, mutation, map[string]interface{}{
+		"email":    userInput.Email,
+		"password": userInput.Password,
+	}, &data)
 	if err != nil {
-		uc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if len(res.Errors) != 0 {
-		uc.Logger.AddErrorLogger(res.Errors[0].Message)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": res.Errors[0].Message})
+		uc.Logger.Error(context.Request.Context(), "Register failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
+
 	context.JSON(http.StatusOK, gin.H{"message": "User registered successfully"})
 }
 
@@ -123,61 +165,39 @@ func (uc *UserController) Register(context *gin.Context) {
 // @Produce      json
 // @Param        body body model.LoginInput true "email and password"
 // @Success      200  {object}  entity.LoginResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      401  {object}	entity.UserResponseFailure
 // @Failure      500  {object}	entity.UserResponseFailure
 // @Router       /login [post]
 func (uc *UserController) Login(context *gin.Context) {
-	uc.Logger.AddInfoLogger("controllers," + "user.go," + "Login() Func")
+	uc.Logger.Debug(context.Request.Context(), "Login called")
 
 	var userInput model.LoginInput
 	if err := context.BindJSON(&userInput); err != nil {
-		uc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		uc.Logger.Error(context.Request.Context(), "Login failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
 		return
 	}
 
-	mutation := `mutation {
+	mutation := `mutation ($email: String!, $password: String!) {
 				login(input: {
-					email: "%s",
-					password: "%s"
-				}) 
+					email: $email,
+					password: $password
+				})
 	  		}`
-	query := fmt.Sprintf(mutation, userInput.Email, userInput.Password)
-
-	// Marshal the mutation variables to JSON
-	queryBody, _ := json.Marshal(map[string]string{
-		"query": query,
-	})
 
-	// Create a new HTTP request to the GraphQL server
-	resp, err := NewQueryRequest(queryBody)
-	if err != nil {
-		uc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	var data struct {
+		Login string `json:"login"`
 	}
-
-	defer resp.Body.Close()
-
-	var res struct {
-		Data struct {
-			Login string `json:"login"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&res)
+	err := RunQuery(context.Request.Context(), uc.QueryURL, mutation, map[string]interface{}{
+		"email":    userInput.Email,
+		"password": userInput.Password,
+	}, &data)
 	if err != nil {
-		uc.Logger.AddErrorLogger(err.Error())
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if len(res.Errors) != 0 {
-		uc.Logger.AddErrorLogger(res.Errors[0].Message)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": res.Errors[0].Message})
+		uc.Logger.Error(context.Request.Context(), "Login failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
 		return
 	}
 
-	context.JSON(http.StatusOK, gin.H{"token": res.Data.Login})
+	context.JSON(http.StatusOK, gin.H{"token": data.Login})
 }
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Get webhooks
+// @Summary      Get webhooks
+// @Description  Get the webhooks of the user, without their secret
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Success      200  {object}  []model.Webhook
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /webhooks [get]
+func (cc *Covid19Controller) GetWebhooks(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetWebhooks called")
+
+	query := `query {
+					webhooks{
+						id
+						url
+						events
+						countries
+						createdAt
+					}
+				}`
+
+	var data struct {
+		Webhooks []model.Webhook `json:"webhooks"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetWebhooks failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"webhooks": data.Webhooks})
+}
+
+// Create webhook
+// @Summary      Create webhook
+// @Description  Register a URL the events of the user are posted to, signed with the returned secret. The events are statistics-changed and threshold-alert and the countries must be followed by the user, none meaning all of them.
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        body body entity.CreateWebhookRequest true "url, events and countries"
+// @Success      200  {object}  model.Webhook
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      403  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /webhooks [post]
+func (cc *Covid19Controller) CreateWebhook(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "CreateWebhook called")
+
+	var userInput entity.CreateWebhookRequest
+	if err := context.BindJSON(&userInput); err != nil {
+		cc.Logger.Error(context.Request.Context(), "CreateWebhook failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("Invalid input"))
+		return
+	}
+
+	webhookEvents := make([]model.WebhookEvent, 0, len(userInput.Events))
+	for _, event := range userInput.Events {
+		webhookEvent := model.WebhookEvent(strings.ToUpper(strings.ReplaceAll(event, "-", "_")))
+		if !webhookEvent.IsValid() {
+			cc.Logger.Warn(context.Request.Context(), "invalid webhook event", "event", event)
+			context.JSON(http.StatusBadRequest, invalidInput("invalid event "+event))
+			return
+		}
+		webhookEvents = append(webhookEvents, webhookEvent)
+	}
+	countries := userInput.Countries
+	if countries == nil {
+		countries = []string{}
+	}
+
+	mutation := `mutation ($url: String!, $events: [WebhookEvent!], $countries: [String!]) {
+		createWebhook(input: {
+			url: $url,
+			events: $events,
+			countries: $countries
+		}){
+			id
+			url
+			events
+			countries
+			secret
+			createdAt
+		}
+	  }`
+
+	var data struct {
+		CreateWebhook model.Webhook `json:"createWebhook"`
+	}
+	err := RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{
+		"url":       userInput.URL,
+		"events":    webhookEvents,
+		"countries": countries,
+	}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "CreateWebhook failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.CreateWebhook)
+}
+
+// Delete webhook
+// @Summary      Delete webhook
+// @Description  Delete a webhook of the user along with its deliveries
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        id  path int true "webhook id"
+// @Success      200  {object}  entity.RegisterResponseSuccess
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /webhooks/{id} [delete]
+func (cc *Covid19Controller) DeleteWebhook(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "DeleteWebhook called")
+
+	webhookId, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "DeleteWebhook failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid webhook id"))
+		return
+	}
+
+	mutation := `mutation ($id: Int!) {
+		deleteWebhook(input: {
+			id: $id
+		})
+	  }`
+
+	var data struct {
+		DeleteWebhook bool `json:"deleteWebhook"`
+	}
+	err = RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{"id": webhookId}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "DeleteWebhook failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "webhook deleted successfully"})
+}
+
+// Get webhook deliveries
+// @Summary      Get webhook deliveries
+// @Description  Get the last 100 deliveries of a webhook of the user with their status, the latest first
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        id  path int true "webhook id"
+// @Success      200  {object}  []model.WebhookDelivery
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /webhooks/{id}/deliveries [get]
+func (cc *Covid19Controller) GetWebhookDeliveries(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "GetWebhookDeliveries called")
+
+	webhookId, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetWebhookDeliveries failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid webhook id"))
+		return
+	}
+
+	query := `query ($webhookId: Int!) {
+					webhookDeliveries(webhookId: $webhookId){
+						id
+						webhookId
+						event
+						payload
+						status
+						attempts
+						responseStatus
+						error
+						nextAttemptAt
+						createdAt
+						deliveredAt
+					}
+				}`
+
+	var data struct {
+		WebhookDeliveries []model.WebhookDelivery `json:"webhookDeliveries"`
+	}
+	err = RunQuery(context.Request.Context(), cc.QueryURL, query, map[string]interface{}{"webhookId": webhookId}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "GetWebhookDeliveries failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"deliveries": data.WebhookDeliveries})
+}
+
+// Redeliver webhook
+// @Summary      Redeliver webhook
+// @Description  Queue the payload of a delivery of the user again, as a new delivery
+// @Accept       json
+// @Produce      json
+// @Param		 Authorization	header		string	true	"Authentication header"
+// @Param        id  path int true "delivery id"
+// @Success      200  {object}  model.WebhookDelivery
+// @Failure      400  {object}	entity.UserResponseFailure
+// @Failure      404  {object}	entity.UserResponseFailure
+// @Failure      500  {object}	entity.UserResponseFailure
+// @Router       /webhook-deliveries/{id}/redeliver [post]
+func (cc *Covid19Controller) RedeliverWebhook(context *gin.Context) {
+	cc.Logger.Debug(context.Request.Context(), "RedeliverWebhook called")
+
+	deliveryId, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "RedeliverWebhook failed", "error", err)
+		context.JSON(http.StatusBadRequest, invalidInput("invalid delivery id"))
+		return
+	}
+
+	mutation := `mutation ($id: Int!) {
+		redeliverWebhook(input: {
+			id: $id
+		}){
+			id
+			webhookId
+			event
+			payload
+			status
+			attempts
+			nextAttemptAt
+			createdAt
+		}
+	  }`
+
+	var data struct {
+		RedeliverWebhook model.WebhookDelivery `json:"redeliverWebhook"`
+	}
+	err = RunQuery(context.Request.Context(), cc.QueryURL, mutation, map[string]interface{}{"id": deliveryId}, &data)
+	if err != nil {
+		cc.Logger.Error(context.Request.Context(), "RedeliverWebhook failed", "error", err)
+		context.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	context.JSON(http.StatusOK, data.RedeliverWebhook)
+}
@@ -1,43 +1,201 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 const (
-	host     = "localhost"
-	port     = 5432
-	user     = "myuser"
-	password = "mypass"
-	dbname   = "mydb"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	defaultSQLiteDSN = "file:covid19.db"
+
+	// DefaultQueryTimeout bounds each query unless configured otherwise.
+	DefaultQueryTimeout = 5 * time.Second
 )
 
-func Connect() (*sql.DB, error) {
+// sslModes are the accepted TLS modes, as defined by libpq.
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// PoolConfig holds the connection pool limits, zero values keep the
+// database/sql defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// RetryConfig tells how many times the first ping is attempted, the delay
+// doubles after each failure up to MaxDelay.
+type RetryConfig struct {
+	Attempts int
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// Config tells which database to open and how to use it.
+type Config struct {
+	// Driver is DriverPostgres or DriverSQLite.
+	Driver string
+	// DSN, when set, is passed to the driver as is and replaces the other
+	// connection settings.
+	DSN          string
+	Host         string
+	Port         string
+	User         string
+	Password     string
+	Name         string
+	SSLMode      string
+	SSLRootCert  string
+	Pool         PoolConfig
+	Retry        RetryConfig
+	QueryTimeout time.Duration
+}
 
-	err := godotenv.Load()
+// Validate checks the driver, the TLS mode and the pool and retry settings.
+func (c Config) Validate() error {
+	if c.Driver != DriverPostgres && c.Driver != DriverSQLite {
+		return fmt.Errorf("unsupported database driver %s, expected %s or %s", c.Driver, DriverPostgres, DriverSQLite)
+	}
+	if c.Driver == DriverPostgres && c.DSN == "" {
+		valid := false
+		for _, mode := range sslModes {
+			valid = valid || mode == c.SSLMode
+		}
+		if !valid {
+			return fmt.Errorf("invalid database sslmode %s, expected one of %s", c.SSLMode, strings.Join(sslModes, ", "))
+		}
+	}
+	if c.Pool.MaxOpenConns > 0 && c.Pool.MaxIdleConns > c.Pool.MaxOpenConns {
+		return fmt.Errorf("%d idle connections can't exceed the limit of %d open connections", c.Pool.MaxIdleConns, c.Pool.MaxOpenConns)
+	}
+	if c.Retry.Attempts < 1 {
+		return fmt.Errorf("invalid number of connection attempts %d, expected at least 1", c.Retry.Attempts)
+	}
+	return nil
+}
+
+// Connect opens the database, configures its pool and waits until it answers
+// a ping, the failed attempts being logged to log. Without DSN, Postgres is
+// configured from the connection settings and SQLite uses the covid19.db file.
+func Connect(ctx context.Context, config Config, log logger.Logger) (*sql.DB, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, err := open(config)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		return nil, err
 	}
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+	if err := PingWithRetry(ctx, db, config.Retry, log); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func open(config Config) (*sql.DB, error) {
+	dsn := config.DSN
+
+	switch config.Driver {
+	case DriverPostgres:
+		if dsn == "" {
+			dsn = postgresDSN(config)
+		}
+
+		// Open a database connection
+		db, err := sql.Open(DriverPostgres, dsn)
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(config.Pool.MaxOpenConns)
+		db.SetMaxIdleConns(config.Pool.MaxIdleConns)
+		db.SetConnMaxLifetime(config.Pool.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(config.Pool.ConnMaxIdleTime)
+		return db, nil
+	default:
+		if dsn == "" {
+			dsn = defaultSQLiteDSN
+		}
+		return OpenSQLite(dsn)
+	}
+}
+
+// postgresDSN builds the connection string from the connection settings.
+func postgresDSN(config Config) string {
+	params := [][2]string{
+		{"host", config.Host},
+		{"port", config.Port},
+		{"user", config.User},
+		{"password", config.Password},
+		{"dbname", config.Name},
+		{"sslmode", config.SSLMode},
+		{"sslrootcert", config.SSLRootCert},
+	}
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		if param[1] == "" {
+			continue
+		}
+		// quote the values so that passwords may hold spaces or quotes
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(param[1])
+		parts = append(parts, fmt.Sprintf("%s='%s'", param[0], value))
+	}
+	return strings.Join(parts, " ")
+}
 
-	postgresqlDbInfo := fmt.Sprintf("host=%s port=%s user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
+// PingWithRetry pings the database until it answers, giving up after the
+// configured attempts or when ctx is done. Each failed attempt but the last is
+// logged as a warning along with the delay before the next one.
+func PingWithRetry(ctx context.Context, db *sql.DB, retry RetryConfig, log logger.Logger) error {
+	delay := retry.Delay
+	var err error
+	for attempt := 1; attempt <= retry.Attempts; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+		if attempt == retry.Attempts {
+			break
+		}
+		log.Warn(ctx, "database not ready, retrying", "attempt", attempt, "attempts", retry.Attempts, "delay", delay, "error", err)
 
-	// Open a database connection
-	db, err := sql.Open("postgres", postgresqlDbInfo)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+	return fmt.Errorf("database not reachable after %d attempt(s): %w", retry.Attempts, err)
+}
+
+// OpenSQLite opens a SQLite database with foreign keys enforced. SQLite
+// allows a single writer, and every connection to :memory: gets its own
+// database, so the pool is limited to one connection.
+func OpenSQLite(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(DriverSQLite, dsn)
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return db, nil
 }
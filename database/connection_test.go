@@ -0,0 +1,116 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+)
+
+func TestConfigValidate(t *testing.T) {
+	// prapare data
+	config := Config{
+		Driver:  DriverPostgres,
+		SSLMode: "verify-full",
+		Pool:    PoolConfig{MaxOpenConns: 10, MaxIdleConns: 5},
+		Retry:   RetryConfig{Attempts: 1},
+	}
+
+	err := config.Validate()
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+}
+
+func TestNegativeConfigValidate(t *testing.T) {
+	// prapare data
+	valid := Config{
+		Driver:  DriverPostgres,
+		SSLMode: "disable",
+		Pool:    PoolConfig{MaxOpenConns: 2, MaxIdleConns: 2},
+		Retry:   RetryConfig{Attempts: 1},
+	}
+	invalid := map[string]func(config *Config){
+		"driver":   func(config *Config) { config.Driver = "mysql" },
+		"sslmode":  func(config *Config) { config.SSLMode = "sometimes" },
+		"idle":     func(config *Config) { config.Pool.MaxIdleConns = 3 },
+		"attempts": func(config *Config) { config.Retry.Attempts = 0 },
+	}
+
+	for name, change := range invalid {
+		config := valid
+		change(&config)
+
+		// Test cases
+		if err := config.Validate(); err == nil {
+			t.Errorf("expected invalid %s error; got nil", name)
+		}
+	}
+}
+
+func TestPostgresDSN(t *testing.T) {
+	// prapare data
+	config := Config{
+		Host:     "db.example.com",
+		Port:     "5432",
+		User:     "covid",
+		Password: "it's secret",
+		Name:     "covid19",
+		SSLMode:  "verify-full",
+	}
+
+	dsn := postgresDSN(config)
+
+	// Test cases
+	expected := `host='db.example.com' port='5432' user='covid' password='it\'s secret' dbname='covid19' sslmode='verify-full'`
+	if dsn != expected {
+		t.Errorf("expected %s; got %s", expected, dsn)
+	}
+}
+
+func TestPingWithRetry(t *testing.T) {
+	// prapare data
+	db := newTestDB(t)
+
+	err := PingWithRetry(context.Background(), db, RetryConfig{Attempts: 3, Delay: time.Millisecond}, logger.Discard())
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+}
+
+func TestNegativePingWithRetry(t *testing.T) {
+	// prapare data
+	db := newTestDB(t)
+	db.Close()
+
+	output := &bytes.Buffer{}
+	log := logger.New(output, logger.Config{Format: logger.FormatText, Level: slog.LevelInfo})
+
+	start := time.Now()
+	err := PingWithRetry(context.Background(), db, RetryConfig{Attempts: 3, Delay: 10 * time.Millisecond}, log)
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected database not reachable error; got nil")
+	}
+
+	// Test cases
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected to wait 10ms then 20ms between the attempts; waited %v", elapsed)
+	}
+
+	// Test cases
+	for _, expected := range []string{"level=WARN", "attempt=1 attempts=3 delay=10ms", "attempt=2 attempts=3 delay=20ms"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected the retries to be logged with %s; got %s", expected, output.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	// prapare data
+	cases := []struct {
+		err      error
+		expected error
+	}{
+		{sql.ErrNoRows, ErrNotFound},
+		{fmt.Errorf("scan: %w", sql.ErrNoRows), ErrNotFound},
+		{&pq.Error{Code: pqUniqueViolation}, ErrConflict},
+		{&pq.Error{Code: pqForeignKeyViolation}, ErrNotFound},
+	}
+
+	for _, c := range cases {
+		err := translateError(c.err)
+
+		// Test cases
+		if !errors.Is(err, c.expected) {
+			t.Errorf("expected %v from %v; got %v", c.expected, c.err, err)
+		}
+	}
+
+	// Test cases
+	if err := translateError(sql.ErrConnDone); err != sql.ErrConnDone {
+		t.Errorf("expected other errors to be kept as is; got %v", err)
+	}
+
+	// Test cases
+	if err := translateError(nil); err != nil {
+		t.Errorf("expected nil; got %v", err)
+	}
+}
@@ -0,0 +1,1041 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+// sharedRegions are the regions created by the regions migration, without
+// the countries it puts in them.
+var sharedRegions = []entity.Region{
+	{Name: "Africa", Type: "continent"},
+	{Name: "Asia", Type: "continent"},
+	{Name: "Europe", Type: "continent"},
+	{Name: "North America", Type: "continent"},
+	{Name: "South America", Type: "continent"},
+	{Name: "Oceania", Type: "continent"},
+	{Name: "African Region", Type: "who_region"},
+	{Name: "Region of the Americas", Type: "who_region"},
+	{Name: "South-East Asia Region", Type: "who_region"},
+	{Name: "European Region", Type: "who_region"},
+	{Name: "Eastern Mediterranean Region", Type: "who_region"},
+	{Name: "Western Pacific Region", Type: "who_region"},
+}
+
+type memoryUser struct {
+	id       int
+	email    string
+	password []byte
+}
+
+type memoryCountry struct {
+	id                 int
+	name               string
+	rollupSubdivisions bool
+}
+
+type memorySubdivision struct {
+	id        int
+	countryId int
+	name      string
+}
+
+type dailyStatisticKey struct {
+	countryId int
+	date      string
+}
+
+// MemoryRepository is a thread-safe in-memory implementation of
+// SQLRepositoryInterface with the same semantics as the SQL schema. It is
+// meant for tests and local experiments, nothing is persisted. Operations
+// never block, so the contexts are accepted but not used.
+type MemoryRepository struct {
+	mutex sync.RWMutex
+
+	users                 []memoryUser
+	countries             []memoryCountry
+	statistics            map[int]entity.Statistics
+	usersCountries        map[int]map[int]bool
+	dailyStatistics       map[dailyStatisticKey]entity.DailyStatistic
+	regions               []entity.Region
+	regionsCountries      map[int]map[int]bool
+	subdivisions          []memorySubdivision
+	subdivisionStatistics map[int]entity.SubdivisionStatistics
+	usersSubdivisions     map[int]map[int]bool
+	alertRules            []entity.AlertRule
+	alerts                []entity.Alert
+	lastAlertRuleId       int
+	lastAlertId           int
+	webhooks              []entity.Webhook
+	webhookDeliveries     []entity.WebhookDelivery
+	lastWebhookId         int
+	lastWebhookDeliveryId int
+	digestPreferences     []entity.DigestPreference
+}
+
+var _ SQLRepositoryInterface = (*MemoryRepository)(nil)
+
+func NewMemoryRepository() *MemoryRepository {
+	repository := &MemoryRepository{
+		statistics:            make(map[int]entity.Statistics),
+		usersCountries:        make(map[int]map[int]bool),
+		dailyStatistics:       make(map[dailyStatisticKey]entity.DailyStatistic),
+		regionsCountries:      make(map[int]map[int]bool),
+		subdivisionStatistics: make(map[int]entity.SubdivisionStatistics),
+		usersSubdivisions:     make(map[int]map[int]bool),
+	}
+	for i, region := range sharedRegions {
+		region.ID = i + 1
+		repository.regions = append(repository.regions, region)
+	}
+	return repository
+}
+
+func (m *MemoryRepository) UsersCountById(ctx context.Context, userId int) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.findUserById(userId) == nil {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (m *MemoryRepository) CountriesCountByname(ctx context.Context, name string) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	count := 0
+	for _, country := range m.countries {
+		if country.name == name {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MemoryRepository) InsertCountry(ctx context.Context, name string) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	id := len(m.countries) + 1
+	m.countries = append(m.countries, memoryCountry{id: id, name: name})
+	return id, nil
+}
+
+func (m *MemoryRepository) InsertStatistic(ctx context.Context, countryId int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findCountryById(countryId) == nil {
+		return foreignKeyError("statistics", "countries")
+	}
+	if _, ok := m.statistics[countryId]; ok {
+		return nil
+	}
+	now := time.Now().UTC()
+	m.statistics[countryId] = entity.Statistics{CountryId: countryId, LastUpdated: &now}
+	return nil
+}
+
+func (m *MemoryRepository) GetCountryIdByName(ctx context.Context, name string) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	country := m.findCountryByName(name)
+	if country == nil {
+		return 0, ErrNotFound
+	}
+	return country.id, nil
+}
+
+func (m *MemoryRepository) InsertIntoUsersCountries(ctx context.Context, userId, countryId int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findUserById(userId) == nil || m.findCountryById(countryId) == nil {
+		return foreignKeyError("users_countries", "users or countries")
+	}
+	return insertPair(m.usersCountries, userId, countryId, "users_countries")
+}
+
+func (m *MemoryRepository) GetAllCountriesByUserId(ctx context.Context, userId int) ([]*model.Country, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	countries := make([]*model.Country, 0)
+	for _, country := range m.countries {
+		if m.usersCountries[userId][country.id] {
+			countries = append(countries, &model.Country{Name: country.name})
+		}
+	}
+	return countries, nil
+}
+
+func (m *MemoryRepository) GetTopThreeCountriesByUserIdAndType(ctx context.Context, userId int, status string) ([]*model.Country, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statistics := make([]entity.Statistics, 0)
+	for _, country := range m.countries {
+		statistic, ok := m.statistics[country.id]
+		if ok && m.usersCountries[userId][country.id] {
+			statistics = append(statistics, statistic)
+		}
+	}
+
+	sort.SliceStable(statistics, func(i, j int) bool {
+		switch status {
+		case "confirmed":
+			return statistics[i].Confirmed > statistics[j].Confirmed
+		case "death":
+			return statistics[i].Deaths > statistics[j].Deaths
+		}
+		return false
+	})
+	if len(statistics) > 3 {
+		statistics = statistics[:3]
+	}
+
+	countries := make([]*model.Country, 0, len(statistics))
+	for _, statistic := range statistics {
+		countries = append(countries, &model.Country{Name: m.findCountryById(statistic.CountryId).name})
+	}
+	return countries, nil
+}
+
+func (m *MemoryRepository) GetAllCountries(ctx context.Context) (map[int]string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	countries := make(map[int]string)
+	for _, country := range m.countries {
+		countries[country.id] = country.name
+	}
+	return countries, nil
+}
+
+func (m *MemoryRepository) GetAllStatistics(ctx context.Context) ([]entity.Statistics, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statistics := make([]entity.Statistics, 0, len(m.statistics))
+	for _, country := range m.countries {
+		if statistic, ok := m.statistics[country.id]; ok {
+			statistics = append(statistics, statistic)
+		}
+	}
+	return statistics, nil
+}
+
+func (m *MemoryRepository) UpdateArrayOfStatistics(ctx context.Context, statistics []entity.Statistics) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now().UTC()
+	for _, statistic := range statistics {
+		if _, ok := m.statistics[statistic.CountryId]; !ok {
+			continue
+		}
+		statistic.LastUpdated = &now
+		m.statistics[statistic.CountryId] = statistic
+	}
+}
+
+func (m *MemoryRepository) UsersCountByEmail(ctx context.Context, email string) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.findUserByEmail(email) == nil {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (m *MemoryRepository) InsertNewUser(ctx context.Context, email string, password []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findUserByEmail(email) != nil {
+		return uniqueError("users", "email")
+	}
+	m.users = append(m.users, memoryUser{id: len(m.users) + 1, email: email, password: password})
+	return nil
+}
+
+func (m *MemoryRepository) FindUserByEmail(ctx context.Context, email string) (int, []byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	user := m.findUserByEmail(email)
+	if user == nil {
+		return 0, nil, ErrNotFound
+	}
+	return user.id, user.password, nil
+}
+
+func (m *MemoryRepository) UpsertDailyStatistic(ctx context.Context, statistic entity.DailyStatistic) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findCountryById(statistic.CountryId) == nil {
+		return foreignKeyError("daily_statistics", "countries")
+	}
+	statistic.Date = truncateToDay(statistic.Date)
+	m.dailyStatistics[dailyStatisticKey{statistic.CountryId, statistic.Date.Format("2006-01-02")}] = statistic
+	return nil
+}
+
+func (m *MemoryRepository) GetDailyStatisticsByCountryName(ctx context.Context, countryName string, from, to time.Time) ([]entity.DailyStatistic, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statistics := make([]entity.DailyStatistic, 0)
+	country := m.findCountryByName(countryName)
+	if country == nil {
+		return statistics, nil
+	}
+	for _, statistic := range m.dailyStatistics {
+		if statistic.CountryId == country.id && !statistic.Date.Before(from) && !statistic.Date.After(to) {
+			statistics = append(statistics, statistic)
+		}
+	}
+	sort.Slice(statistics, func(i, j int) bool { return statistics[i].Date.Before(statistics[j].Date) })
+	return statistics, nil
+}
+
+func (m *MemoryRepository) InsertRegion(ctx context.Context, name, regionType string, userId int, countryIds []int) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if userId != 0 && m.findUserById(userId) == nil {
+		return 0, foreignKeyError("regions", "users")
+	}
+	for _, region := range m.regions {
+		if region.Name == name && region.UserId == userId {
+			return 0, uniqueError("regions", "name")
+		}
+	}
+	// checked first, so that nothing is inserted when a country is missing
+	for _, countryId := range countryIds {
+		if m.findCountryById(countryId) == nil {
+			return 0, foreignKeyError("regions_countries", "countries")
+		}
+	}
+	id := len(m.regions) + 1
+	m.regions = append(m.regions, entity.Region{ID: id, Name: name, Type: regionType, UserId: userId})
+	for _, countryId := range countryIds {
+		if !m.regionsCountries[id][countryId] {
+			insertPair(m.regionsCountries, id, countryId, "regions_countries")
+		}
+	}
+	return id, nil
+}
+
+func (m *MemoryRepository) GetRegionByName(ctx context.Context, userId int, name string) (entity.Region, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var found *entity.Region
+	for i, region := range m.regions {
+		if region.Name != name {
+			continue
+		}
+		if region.UserId == userId && userId != 0 {
+			return region, nil
+		}
+		if region.UserId == 0 {
+			found = &m.regions[i]
+		}
+	}
+	if found == nil {
+		return entity.Region{}, ErrNotFound
+	}
+	return *found, nil
+}
+
+func (m *MemoryRepository) GetRegionsByUserId(ctx context.Context, userId int) ([]entity.Region, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	regions := make([]entity.Region, 0)
+	for _, region := range m.regions {
+		if region.UserId == 0 || region.UserId == userId {
+			regions = append(regions, region)
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].Type != regions[j].Type {
+			return regions[i].Type < regions[j].Type
+		}
+		return regions[i].Name < regions[j].Name
+	})
+	return regions, nil
+}
+
+func (m *MemoryRepository) InsertIntoRegionsCountries(ctx context.Context, regionId, countryId int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if regionId < 1 || regionId > len(m.regions) || m.findCountryById(countryId) == nil {
+		return foreignKeyError("regions_countries", "regions or countries")
+	}
+	if m.regionsCountries[regionId][countryId] {
+		return nil
+	}
+	return insertPair(m.regionsCountries, regionId, countryId, "regions_countries")
+}
+
+func (m *MemoryRepository) GetCountriesStatisticsByRegionId(ctx context.Context, regionId int) ([]entity.CountryStatistics, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.countriesStatistics(func(countryId int) bool { return m.regionsCountries[regionId][countryId] }), nil
+}
+
+func (m *MemoryRepository) GetAllCountriesStatistics(ctx context.Context) ([]entity.CountryStatistics, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.countriesStatistics(func(int) bool { return true }), nil
+}
+
+func (m *MemoryRepository) UpsertSubdivision(ctx context.Context, countryId int, name string) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findCountryById(countryId) == nil {
+		return 0, foreignKeyError("subdivisions", "countries")
+	}
+	for _, subdivision := range m.subdivisions {
+		if subdivision.countryId == countryId && subdivision.name == name {
+			return subdivision.id, nil
+		}
+	}
+	id := len(m.subdivisions) + 1
+	m.subdivisions = append(m.subdivisions, memorySubdivision{id: id, countryId: countryId, name: name})
+	return id, nil
+}
+
+func (m *MemoryRepository) UpsertSubdivisionStatistic(ctx context.Context, statistic entity.SubdivisionStatistics) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if statistic.SubdivisionId < 1 || statistic.SubdivisionId > len(m.subdivisions) {
+		return foreignKeyError("subdivision_statistics", "subdivisions")
+	}
+	m.subdivisionStatistics[statistic.SubdivisionId] = entity.SubdivisionStatistics{
+		SubdivisionId: statistic.SubdivisionId,
+		Confirmed:     statistic.Confirmed,
+		Deaths:        statistic.Deaths,
+		Recovered:     statistic.Recovered,
+	}
+	return nil
+}
+
+func (m *MemoryRepository) GetSubdivisionIdByName(ctx context.Context, countryName, name string) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	country := m.findCountryByName(countryName)
+	for _, subdivision := range m.subdivisions {
+		if country != nil && subdivision.countryId == country.id && subdivision.name == name {
+			return subdivision.id, nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+func (m *MemoryRepository) InsertIntoUsersSubdivisions(ctx context.Context, userId, subdivisionId int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findUserById(userId) == nil || subdivisionId < 1 || subdivisionId > len(m.subdivisions) {
+		return foreignKeyError("users_subdivisions", "users or subdivisions")
+	}
+	return insertPair(m.usersSubdivisions, userId, subdivisionId, "users_subdivisions")
+}
+
+func (m *MemoryRepository) GetSubdivisionsStatisticsByUserId(ctx context.Context, userId int) ([]entity.SubdivisionStatistics, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.subdivisionsStatistics(func(subdivision memorySubdivision) bool {
+		return m.usersSubdivisions[userId][subdivision.id]
+	}), nil
+}
+
+func (m *MemoryRepository) GetSubdivisionsStatisticsByCountryName(ctx context.Context, countryName string) ([]entity.SubdivisionStatistics, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.subdivisionsStatistics(func(subdivision memorySubdivision) bool {
+		return m.findCountryById(subdivision.countryId).name == countryName
+	}), nil
+}
+
+func (m *MemoryRepository) GetCountryIdsWithSubdivisionRollup(ctx context.Context) ([]int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	countryIds := make([]int, 0)
+	for _, country := range m.countries {
+		if country.rollupSubdivisions {
+			countryIds = append(countryIds, country.id)
+		}
+	}
+	return countryIds, nil
+}
+
+func (m *MemoryRepository) UpdateSubdivisionRollup(ctx context.Context, countryName string, enabled bool) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	count := 0
+	for i := range m.countries {
+		if m.countries[i].name == countryName {
+			m.countries[i].rollupSubdivisions = enabled
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MemoryRepository) UsersCountriesCount(ctx context.Context, userId, countryId int) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.usersCountries[userId][countryId] {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m *MemoryRepository) GetStatisticByCountryId(ctx context.Context, countryId int) (entity.Statistics, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statistic, ok := m.statistics[countryId]
+	if !ok {
+		return entity.Statistics{}, ErrNotFound
+	}
+	return statistic, nil
+}
+
+func (m *MemoryRepository) GetDailyStatisticByCountryIdAndDate(ctx context.Context, countryId int, date time.Time) (entity.DailyStatistic, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	statistic, ok := m.dailyStatistics[dailyStatisticKey{countryId, date.Format("2006-01-02")}]
+	if !ok {
+		return entity.DailyStatistic{}, ErrNotFound
+	}
+	return statistic, nil
+}
+
+func (m *MemoryRepository) InsertAlertRule(ctx context.Context, rule entity.AlertRule) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findUserById(rule.UserId) == nil {
+		return 0, foreignKeyError("alert_rules", "users")
+	}
+	country := m.findCountryById(rule.CountryId)
+	if country == nil {
+		return 0, foreignKeyError("alert_rules", "countries")
+	}
+	for _, existing := range m.alertRules {
+		if existing.UserId == rule.UserId && existing.CountryId == rule.CountryId && existing.Metric == rule.Metric &&
+			existing.Operator == rule.Operator && existing.Threshold == rule.Threshold {
+			return 0, uniqueError("alert_rules", "rule")
+		}
+	}
+	m.lastAlertRuleId++
+	rule.ID = m.lastAlertRuleId
+	rule.Country = country.name
+	rule.Triggered = false
+	m.alertRules = append(m.alertRules, rule)
+	return rule.ID, nil
+}
+
+func (m *MemoryRepository) GetAlertRulesByUserId(ctx context.Context, userId int) ([]entity.AlertRule, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	rules := make([]entity.AlertRule, 0)
+	for _, rule := range m.alertRules {
+		if rule.UserId == userId {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *MemoryRepository) GetAllAlertRules(ctx context.Context) ([]entity.AlertRule, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append(make([]entity.AlertRule, 0, len(m.alertRules)), m.alertRules...), nil
+}
+
+func (m *MemoryRepository) DeleteAlertRule(ctx context.Context, userId, ruleId int) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	rule := m.findAlertRuleById(ruleId)
+	if rule == nil || rule.UserId != userId {
+		return 0, nil
+	}
+	rules := m.alertRules[:0]
+	for _, rule := range m.alertRules {
+		if rule.ID != ruleId {
+			rules = append(rules, rule)
+		}
+	}
+	m.alertRules = rules
+	alerts := m.alerts[:0]
+	for _, alert := range m.alerts {
+		if alert.RuleId != ruleId {
+			alerts = append(alerts, alert)
+		}
+	}
+	m.alerts = alerts
+	return 1, nil
+}
+
+func (m *MemoryRepository) UpdateAlertRuleTriggered(ctx context.Context, ruleId int, triggered bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if rule := m.findAlertRuleById(ruleId); rule != nil {
+		rule.Triggered = triggered
+	}
+	return nil
+}
+
+func (m *MemoryRepository) InsertAlert(ctx context.Context, alert entity.Alert) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findAlertRuleById(alert.RuleId) == nil {
+		return 0, foreignKeyError("alerts", "alert_rules")
+	}
+	m.lastAlertId++
+	alert.ID = m.lastAlertId
+	alert.TriggeredAt = alert.TriggeredAt.UTC()
+	alert.AcknowledgedAt = nil
+	m.alerts = append(m.alerts, alert)
+	return alert.ID, nil
+}
+
+func (m *MemoryRepository) GetAlertsByUserId(ctx context.Context, userId int, unacknowledgedOnly bool) ([]entity.Alert, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	alerts := make([]entity.Alert, 0)
+	for _, alert := range m.alerts {
+		rule := m.findAlertRuleById(alert.RuleId)
+		if rule.UserId != userId || (unacknowledgedOnly && alert.AcknowledgedAt != nil) {
+			continue
+		}
+		alert.UserId = rule.UserId
+		alert.Country = rule.Country
+		alert.Metric = rule.Metric
+		alert.Operator = rule.Operator
+		alert.Threshold = rule.Threshold
+		alerts = append(alerts, alert)
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if !alerts[i].TriggeredAt.Equal(alerts[j].TriggeredAt) {
+			return alerts[i].TriggeredAt.After(alerts[j].TriggeredAt)
+		}
+		return alerts[i].ID > alerts[j].ID
+	})
+	return alerts, nil
+}
+
+func (m *MemoryRepository) AcknowledgeAlert(ctx context.Context, userId, alertId int, acknowledgedAt time.Time) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, alert := range m.alerts {
+		if alert.ID != alertId || m.findAlertRuleById(alert.RuleId).UserId != userId {
+			continue
+		}
+		if alert.AcknowledgedAt == nil {
+			acknowledgedAt = acknowledgedAt.UTC()
+			m.alerts[i].AcknowledgedAt = &acknowledgedAt
+		}
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m *MemoryRepository) InsertWebhook(ctx context.Context, webhook entity.Webhook) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findUserById(webhook.UserId) == nil {
+		return 0, foreignKeyError("webhooks", "users")
+	}
+	m.lastWebhookId++
+	webhook.ID = m.lastWebhookId
+	webhook.Events = append([]string{}, webhook.Events...)
+	webhook.Countries = append([]string{}, webhook.Countries...)
+	webhook.CreatedAt = webhook.CreatedAt.UTC()
+	m.webhooks = append(m.webhooks, webhook)
+	return webhook.ID, nil
+}
+
+func (m *MemoryRepository) GetWebhooksByUserId(ctx context.Context, userId int) ([]entity.Webhook, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	webhooks := make([]entity.Webhook, 0)
+	for _, webhook := range m.webhooks {
+		if webhook.UserId == userId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *MemoryRepository) GetAllWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append(make([]entity.Webhook, 0, len(m.webhooks)), m.webhooks...), nil
+}
+
+func (m *MemoryRepository) DeleteWebhook(ctx context.Context, userId, webhookId int) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	webhook := m.findWebhookById(webhookId)
+	if webhook == nil || webhook.UserId != userId {
+		return 0, nil
+	}
+	webhooks := m.webhooks[:0]
+	for _, webhook := range m.webhooks {
+		if webhook.ID != webhookId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	m.webhooks = webhooks
+	deliveries := m.webhookDeliveries[:0]
+	for _, delivery := range m.webhookDeliveries {
+		if delivery.WebhookId != webhookId {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	m.webhookDeliveries = deliveries
+	return 1, nil
+}
+
+func (m *MemoryRepository) InsertWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findWebhookById(delivery.WebhookId) == nil {
+		return 0, foreignKeyError("webhook_deliveries", "webhooks")
+	}
+	m.lastWebhookDeliveryId++
+	m.webhookDeliveries = append(m.webhookDeliveries, entity.WebhookDelivery{
+		ID:            m.lastWebhookDeliveryId,
+		WebhookId:     delivery.WebhookId,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		NextAttemptAt: memoryTime(delivery.NextAttemptAt),
+		CreatedAt:     delivery.CreatedAt.UTC(),
+	})
+	return m.lastWebhookDeliveryId, nil
+}
+
+func (m *MemoryRepository) GetWebhookDeliveries(ctx context.Context, userId, webhookId, limit int) ([]entity.WebhookDelivery, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for i := len(m.webhookDeliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		delivery := m.webhookDeliveries[i]
+		if webhook := m.findWebhookById(delivery.WebhookId); delivery.WebhookId == webhookId && webhook.UserId == userId {
+			deliveries = append(deliveries, m.withWebhook(delivery))
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *MemoryRepository) GetWebhookDeliveryById(ctx context.Context, userId, deliveryId int) (entity.WebhookDelivery, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, delivery := range m.webhookDeliveries {
+		if delivery.ID == deliveryId && m.findWebhookById(delivery.WebhookId).UserId == userId {
+			return m.withWebhook(delivery), nil
+		}
+	}
+	return entity.WebhookDelivery{}, ErrNotFound
+}
+
+func (m *MemoryRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for _, delivery := range m.webhookDeliveries {
+		if delivery.Status == string(model.WebhookDeliveryStatusPending) && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, m.withWebhook(delivery))
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (m *MemoryRepository) UpdateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.webhookDeliveries {
+		if m.webhookDeliveries[i].ID != delivery.ID {
+			continue
+		}
+		stored := &m.webhookDeliveries[i]
+		stored.Status = delivery.Status
+		stored.Attempts = delivery.Attempts
+		stored.ResponseStatus = delivery.ResponseStatus
+		stored.Error = delivery.Error
+		stored.NextAttemptAt = memoryTime(delivery.NextAttemptAt)
+		stored.DeliveredAt = memoryTime(delivery.DeliveredAt)
+	}
+	return nil
+}
+
+func (m *MemoryRepository) UpsertDigestPreference(ctx context.Context, preference entity.DigestPreference) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.findUserById(preference.UserId) == nil {
+		return foreignKeyError("digest_preferences", "users")
+	}
+	for i := range m.digestPreferences {
+		if m.digestPreferences[i].UserId == preference.UserId && m.digestPreferences[i].Channel == preference.Channel {
+			m.digestPreferences[i].Destination = preference.Destination
+			m.digestPreferences[i].Frequency = preference.Frequency
+			return nil
+		}
+	}
+	m.digestPreferences = append(m.digestPreferences, entity.DigestPreference{
+		UserId:      preference.UserId,
+		Channel:     preference.Channel,
+		Destination: preference.Destination,
+		Frequency:   preference.Frequency,
+	})
+	return nil
+}
+
+func (m *MemoryRepository) GetDigestPreferencesByUserId(ctx context.Context, userId int) ([]entity.DigestPreference, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	preferences := make([]entity.DigestPreference, 0)
+	for _, preference := range m.sortedDigestPreferences() {
+		if preference.UserId == userId {
+			preferences = append(preferences, preference)
+		}
+	}
+	return preferences, nil
+}
+
+func (m *MemoryRepository) GetAllDigestPreferences(ctx context.Context) ([]entity.DigestPreference, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.sortedDigestPreferences(), nil
+}
+
+func (m *MemoryRepository) UpdateDigestSentAt(ctx context.Context, userId int, channel string, sentAt time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.digestPreferences {
+		if m.digestPreferences[i].UserId == userId && m.digestPreferences[i].Channel == channel {
+			m.digestPreferences[i].LastSentAt = memoryTime(&sentAt)
+		}
+	}
+	return nil
+}
+
+// sortedDigestPreferences returns copies of the preferences ordered like the
+// SQL query, their empty destinations being the email address of the user.
+func (m *MemoryRepository) sortedDigestPreferences() []entity.DigestPreference {
+	preferences := make([]entity.DigestPreference, 0, len(m.digestPreferences))
+	for _, preference := range m.digestPreferences {
+		if preference.Destination == "" {
+			preference.Destination = m.findUserById(preference.UserId).email
+		}
+		preference.LastSentAt = memoryTime(preference.LastSentAt)
+		preferences = append(preferences, preference)
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		if preferences[i].UserId != preferences[j].UserId {
+			return preferences[i].UserId < preferences[j].UserId
+		}
+		return preferences[i].Channel < preferences[j].Channel
+	})
+	return preferences
+}
+
+func (m *MemoryRepository) findWebhookById(webhookId int) *entity.Webhook {
+	for i := range m.webhooks {
+		if m.webhooks[i].ID == webhookId {
+			return &m.webhooks[i]
+		}
+	}
+	return nil
+}
+
+// withWebhook sets the URL and the secret of the webhook of the delivery, like
+// the SQL join does.
+func (m *MemoryRepository) withWebhook(delivery entity.WebhookDelivery) entity.WebhookDelivery {
+	webhook := m.findWebhookById(delivery.WebhookId)
+	delivery.URL = webhook.URL
+	delivery.Secret = webhook.Secret
+	return delivery
+}
+
+// memoryTime copies the optional time in UTC, so that the caller can't change
+// the stored one.
+func memoryTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func (m *MemoryRepository) findUserById(userId int) *memoryUser {
+	for i := range m.users {
+		if m.users[i].id == userId {
+			return &m.users[i]
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) findUserByEmail(email string) *memoryUser {
+	for i := range m.users {
+		if m.users[i].email == email {
+			return &m.users[i]
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) findCountryById(countryId int) *memoryCountry {
+	for i := range m.countries {
+		if m.countries[i].id == countryId {
+			return &m.countries[i]
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) findAlertRuleById(ruleId int) *entity.AlertRule {
+	for i := range m.alertRules {
+		if m.alertRules[i].ID == ruleId {
+			return &m.alertRules[i]
+		}
+	}
+	return nil
+}
+
+// findCountryByName returns the first country with the name, like the SQL
+// queries do when names are duplicated.
+func (m *MemoryRepository) findCountryByName(name string) *memoryCountry {
+	for i := range m.countries {
+		if m.countries[i].name == name {
+			return &m.countries[i]
+		}
+	}
+	return nil
+}
+
+// countriesStatistics returns the statistics of the matching countries sorted
+// by name, countries without statistics are left out like in an inner join.
+func (m *MemoryRepository) countriesStatistics(match func(countryId int) bool) []entity.CountryStatistics {
+	statistics := make([]entity.CountryStatistics, 0)
+	for _, country := range m.countries {
+		if !match(country.id) {
+			continue
+		}
+		statistic, ok := m.statistics[country.id]
+		statistics = append(statistics, entity.CountryStatistics{
+			Name:      country.name,
+			Confirmed: statistic.Confirmed,
+			Deaths:    statistic.Deaths,
+			Recovered: statistic.Recovered,
+			Missing:   !ok,
+		})
+	}
+	sort.SliceStable(statistics, func(i, j int) bool { return statistics[i].Name < statistics[j].Name })
+	return statistics
+}
+
+// subdivisionsStatistics returns the statistics of the matching subdivisions
+// sorted by country and subdivision names.
+func (m *MemoryRepository) subdivisionsStatistics(match func(subdivision memorySubdivision) bool) []entity.SubdivisionStatistics {
+	statistics := make([]entity.SubdivisionStatistics, 0)
+	for _, subdivision := range m.subdivisions {
+		statistic, ok := m.subdivisionStatistics[subdivision.id]
+		if !ok || !match(subdivision) {
+			continue
+		}
+		statistic.Country = m.findCountryById(subdivision.countryId).name
+		statistic.Name = subdivision.name
+		statistics = append(statistics, statistic)
+	}
+	sort.SliceStable(statistics, func(i, j int) bool {
+		if statistics[i].Country != statistics[j].Country {
+			return statistics[i].Country < statistics[j].Country
+		}
+		return statistics[i].Name < statistics[j].Name
+	})
+	return statistics
+}
+
+// insertPair adds a row to a many-to-many relation, failing like a unique
+// constraint when it is already there.
+func insertPair(relation map[int]map[int]bool, left, right int, table string) error {
+	if relation[left] == nil {
+		relation[left] = make(map[int]bool)
+	}
+	if relation[left][right] {
+		return uniqueError(table, "pair")
+	}
+	relation[left][right] = true
+	return nil
+}
+
+func truncateToDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func uniqueError(table, column string) error {
+	return fmt.Errorf("%w: duplicate key value violates unique constraint on %s.%s", ErrConflict, table, column)
+}
+
+func foreignKeyError(table, reference string) error {
+	return fmt.Errorf("%w: insert on %s violates foreign key constraint referencing %s", ErrNotFound, table, reference)
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgresMigrations, err := LoadMigrations(DriverPostgres)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	for i, migration := range postgresMigrations {
+		if migration.Version != i+1 {
+			t.Errorf("expected version %d; got %d", i+1, migration.Version)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			t.Errorf("expected up and down scripts for %d_%s", migration.Version, migration.Name)
+		}
+	}
+
+	sqliteMigrations, err := LoadMigrations(DriverSQLite)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if len(sqliteMigrations) != len(postgresMigrations) {
+		t.Fatalf("expected %d sqlite migrations; got %d", len(postgresMigrations), len(sqliteMigrations))
+	}
+	for i, migration := range sqliteMigrations {
+		if migration.Name != postgresMigrations[i].Name {
+			t.Errorf("expected migration %d to be %s; got %s", migration.Version, postgresMigrations[i].Name, migration.Name)
+		}
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	db := newTestDB(t)
+
+	count, err := MigrateUp(db, DriverSQLite)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	statuses, _ := GetMigrationsStatus(db, DriverSQLite)
+	if count != len(statuses) || statuses[len(statuses)-1].AppliedAt == nil {
+		t.Errorf("expected %d applied migrations; got %d", len(statuses), count)
+	}
+
+	count, err = MigrateDown(db, DriverSQLite, len(statuses))
+
+	// Test cases
+	if err != nil || count != len(statuses) {
+		t.Errorf("expected %d reverted migrations and nil error; got %d and %v", len(statuses), count, err)
+	}
+
+	// Test cases
+	count, err = MigrateUp(db, DriverSQLite)
+	if err != nil || count != len(statuses) {
+		t.Errorf("expected %d reapplied migrations and nil error; got %d and %v", len(statuses), count, err)
+	}
+}
+
+func TestMigrateSeedsSharedRegions(t *testing.T) {
+	// prapare data
+	db := newTestDB(t)
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	var regions int
+	err := db.QueryRow(`SELECT COUNT(*)
+		FROM regions_countries
+			JOIN regions ON regions_countries.region_id = regions.id
+			JOIN countries ON regions_countries.country_id = countries.id
+		WHERE countries.name = 'Palestine' AND regions.name IN ('Asia', 'Eastern Mediterranean Region')`).Scan(&regions)
+
+	// Test cases
+	if err != nil || regions != 2 {
+		t.Errorf("expected Palestine in Asia and the Eastern Mediterranean Region; got %d and %v", regions, err)
+	}
+
+	var empty int
+	err = db.QueryRow(`SELECT COUNT(*) FROM regions
+		WHERE user_id IS NULL AND NOT EXISTS (SELECT 1 FROM regions_countries WHERE regions_countries.region_id = regions.id)`).Scan(&empty)
+
+	// Test cases
+	if err != nil || empty != 0 {
+		t.Errorf("expected every shared region to have countries; got %d empty and %v", empty, err)
+	}
+}
+
+func TestNegativeLoadMigrations(t *testing.T) {
+	files := fstest.MapFS{
+		"migrations/0001_init.up.sql": {Data: []byte("CREATE TABLE users (id integer);")},
+	}
+
+	migrations, err := loadMigrations(files, "migrations")
+
+	// Test cases
+	if migrations != nil {
+		t.Errorf("expected nil migrations; got %v", migrations)
+	}
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected migration needs both an up and a down file error; got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+-- Schema of the original mydb dump, IF NOT EXISTS lets databases restored
+-- from the dump adopt the migrations.
+CREATE TABLE IF NOT EXISTS users (
+    id serial PRIMARY KEY,
+    email character varying(255) NOT NULL UNIQUE,
+    password character varying(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS countries (
+    id serial PRIMARY KEY,
+    name character varying(50) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS statistics (
+    country_id integer PRIMARY KEY CONSTRAINT fk_country_statistics REFERENCES countries(id),
+    confirmed integer DEFAULT 0,
+    recovered integer DEFAULT 0,
+    death integer DEFAULT 0,
+    last_updated timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS users_countries (
+    id serial PRIMARY KEY,
+    user_id integer REFERENCES users(id),
+    country_id integer REFERENCES countries(id),
+    CONSTRAINT unique_country_user_name UNIQUE (user_id, country_id)
+);
+
+CREATE OR REPLACE FUNCTION update_statistics_last_updated() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+   NEW.last_updated = CURRENT_TIMESTAMP;
+   RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS update_statistics_last_updated ON statistics;
+CREATE TRIGGER update_statistics_last_updated BEFORE UPDATE ON statistics FOR EACH ROW EXECUTE FUNCTION update_statistics_last_updated();
@@ -0,0 +1,9 @@
+-- Daily snapshots of the cumulative totals, one row per country and day.
+CREATE TABLE IF NOT EXISTS daily_statistics (
+    country_id integer NOT NULL REFERENCES countries(id),
+    date date NOT NULL,
+    confirmed integer DEFAULT 0,
+    recovered integer DEFAULT 0,
+    death integer DEFAULT 0,
+    PRIMARY KEY (country_id, date)
+);
@@ -0,0 +1,3 @@
+-- The countries seeded by the up migration are kept, users may follow them.
+DROP TABLE IF EXISTS regions_countries;
+DROP TABLE IF EXISTS regions;
@@ -0,0 +1,250 @@
+-- Groups of countries: continents and WHO regions shared by every user
+-- (user_id is NULL) and custom groups owned by a single user.
+CREATE TABLE IF NOT EXISTS regions (
+    id serial PRIMARY KEY,
+    name character varying(100) NOT NULL,
+    type character varying(20) NOT NULL,
+    user_id integer REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_region_name_user ON regions (name, COALESCE(user_id, 0));
+
+CREATE TABLE IF NOT EXISTS regions_countries (
+    region_id integer NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+    country_id integer NOT NULL REFERENCES countries(id),
+    PRIMARY KEY (region_id, country_id)
+);
+
+INSERT INTO regions (name, type) VALUES
+    ('Africa', 'continent'),
+    ('Asia', 'continent'),
+    ('Europe', 'continent'),
+    ('North America', 'continent'),
+    ('South America', 'continent'),
+    ('Oceania', 'continent'),
+    ('African Region', 'who_region'),
+    ('Region of the Americas', 'who_region'),
+    ('South-East Asia Region', 'who_region'),
+    ('European Region', 'who_region'),
+    ('Eastern Mediterranean Region', 'who_region'),
+    ('Western Pacific Region', 'who_region')
+ON CONFLICT DO NOTHING;
+
+-- The countries of the shared regions: the continent and the WHO region of
+-- each WHO member state and of Palestine. The countries are inserted without
+-- statistics, which are only refreshed once a user follows them.
+CREATE TEMPORARY TABLE regions_seed (
+    country character varying(50) NOT NULL,
+    continent character varying(100) NOT NULL,
+    who_region character varying(100) NOT NULL
+);
+
+INSERT INTO regions_seed (country, continent, who_region) VALUES
+    ('Afghanistan', 'Asia', 'Eastern Mediterranean Region'),
+    ('Albania', 'Europe', 'European Region'),
+    ('Algeria', 'Africa', 'African Region'),
+    ('Andorra', 'Europe', 'European Region'),
+    ('Angola', 'Africa', 'African Region'),
+    ('Antigua and Barbuda', 'North America', 'Region of the Americas'),
+    ('Argentina', 'South America', 'Region of the Americas'),
+    ('Armenia', 'Asia', 'European Region'),
+    ('Australia', 'Oceania', 'Western Pacific Region'),
+    ('Austria', 'Europe', 'European Region'),
+    ('Azerbaijan', 'Asia', 'European Region'),
+    ('Bahamas', 'North America', 'Region of the Americas'),
+    ('Bahrain', 'Asia', 'Eastern Mediterranean Region'),
+    ('Bangladesh', 'Asia', 'South-East Asia Region'),
+    ('Barbados', 'North America', 'Region of the Americas'),
+    ('Belarus', 'Europe', 'European Region'),
+    ('Belgium', 'Europe', 'European Region'),
+    ('Belize', 'North America', 'Region of the Americas'),
+    ('Benin', 'Africa', 'African Region'),
+    ('Bhutan', 'Asia', 'South-East Asia Region'),
+    ('Bolivia', 'South America', 'Region of the Americas'),
+    ('Bosnia and Herzegovina', 'Europe', 'European Region'),
+    ('Botswana', 'Africa', 'African Region'),
+    ('Brazil', 'South America', 'Region of the Americas'),
+    ('Brunei', 'Asia', 'Western Pacific Region'),
+    ('Bulgaria', 'Europe', 'European Region'),
+    ('Burkina Faso', 'Africa', 'African Region'),
+    ('Burundi', 'Africa', 'African Region'),
+    ('Cabo Verde', 'Africa', 'African Region'),
+    ('Cambodia', 'Asia', 'Western Pacific Region'),
+    ('Cameroon', 'Africa', 'African Region'),
+    ('Canada', 'North America', 'Region of the Americas'),
+    ('Central African Republic', 'Africa', 'African Region'),
+    ('Chad', 'Africa', 'African Region'),
+    ('Chile', 'South America', 'Region of the Americas'),
+    ('China', 'Asia', 'Western Pacific Region'),
+    ('Colombia', 'South America', 'Region of the Americas'),
+    ('Comoros', 'Africa', 'African Region'),
+    ('Congo', 'Africa', 'African Region'),
+    ('Cook Islands', 'Oceania', 'Western Pacific Region'),
+    ('Costa Rica', 'North America', 'Region of the Americas'),
+    ('Cote d''Ivoire', 'Africa', 'African Region'),
+    ('Croatia', 'Europe', 'European Region'),
+    ('Cuba', 'North America', 'Region of the Americas'),
+    ('Cyprus', 'Europe', 'European Region'),
+    ('Czechia', 'Europe', 'European Region'),
+    ('Democratic Republic of the Congo', 'Africa', 'African Region'),
+    ('Denmark', 'Europe', 'European Region'),
+    ('Djibouti', 'Africa', 'Eastern Mediterranean Region'),
+    ('Dominica', 'North America', 'Region of the Americas'),
+    ('Dominican Republic', 'North America', 'Region of the Americas'),
+    ('Ecuador', 'South America', 'Region of the Americas'),
+    ('Egypt', 'Africa', 'Eastern Mediterranean Region'),
+    ('El Salvador', 'North America', 'Region of the Americas'),
+    ('Equatorial Guinea', 'Africa', 'African Region'),
+    ('Eritrea', 'Africa', 'African Region'),
+    ('Estonia', 'Europe', 'European Region'),
+    ('Eswatini', 'Africa', 'African Region'),
+    ('Ethiopia', 'Africa', 'African Region'),
+    ('Fiji', 'Oceania', 'Western Pacific Region'),
+    ('Finland', 'Europe', 'European Region'),
+    ('France', 'Europe', 'European Region'),
+    ('Gabon', 'Africa', 'African Region'),
+    ('Gambia', 'Africa', 'African Region'),
+    ('Georgia', 'Asia', 'European Region'),
+    ('Germany', 'Europe', 'European Region'),
+    ('Ghana', 'Africa', 'African Region'),
+    ('Greece', 'Europe', 'European Region'),
+    ('Grenada', 'North America', 'Region of the Americas'),
+    ('Guatemala', 'North America', 'Region of the Americas'),
+    ('Guinea', 'Africa', 'African Region'),
+    ('Guinea-Bissau', 'Africa', 'African Region'),
+    ('Guyana', 'South America', 'Region of the Americas'),
+    ('Haiti', 'North America', 'Region of the Americas'),
+    ('Honduras', 'North America', 'Region of the Americas'),
+    ('Hungary', 'Europe', 'European Region'),
+    ('Iceland', 'Europe', 'European Region'),
+    ('India', 'Asia', 'South-East Asia Region'),
+    ('Indonesia', 'Asia', 'South-East Asia Region'),
+    ('Iran', 'Asia', 'Eastern Mediterranean Region'),
+    ('Iraq', 'Asia', 'Eastern Mediterranean Region'),
+    ('Ireland', 'Europe', 'European Region'),
+    ('Israel', 'Asia', 'European Region'),
+    ('Italy', 'Europe', 'European Region'),
+    ('Jamaica', 'North America', 'Region of the Americas'),
+    ('Japan', 'Asia', 'Western Pacific Region'),
+    ('Jordan', 'Asia', 'Eastern Mediterranean Region'),
+    ('Kazakhstan', 'Asia', 'European Region'),
+    ('Kenya', 'Africa', 'African Region'),
+    ('Kiribati', 'Oceania', 'Western Pacific Region'),
+    ('Kuwait', 'Asia', 'Eastern Mediterranean Region'),
+    ('Kyrgyzstan', 'Asia', 'European Region'),
+    ('Laos', 'Asia', 'Western Pacific Region'),
+    ('Latvia', 'Europe', 'European Region'),
+    ('Lebanon', 'Asia', 'Eastern Mediterranean Region'),
+    ('Lesotho', 'Africa', 'African Region'),
+    ('Liberia', 'Africa', 'African Region'),
+    ('Libya', 'Africa', 'Eastern Mediterranean Region'),
+    ('Lithuania', 'Europe', 'European Region'),
+    ('Luxembourg', 'Europe', 'European Region'),
+    ('Madagascar', 'Africa', 'African Region'),
+    ('Malawi', 'Africa', 'African Region'),
+    ('Malaysia', 'Asia', 'Western Pacific Region'),
+    ('Maldives', 'Asia', 'South-East Asia Region'),
+    ('Mali', 'Africa', 'African Region'),
+    ('Malta', 'Europe', 'European Region'),
+    ('Marshall Islands', 'Oceania', 'Western Pacific Region'),
+    ('Mauritania', 'Africa', 'African Region'),
+    ('Mauritius', 'Africa', 'African Region'),
+    ('Mexico', 'North America', 'Region of the Americas'),
+    ('Micronesia', 'Oceania', 'Western Pacific Region'),
+    ('Moldova', 'Europe', 'European Region'),
+    ('Monaco', 'Europe', 'European Region'),
+    ('Mongolia', 'Asia', 'Western Pacific Region'),
+    ('Montenegro', 'Europe', 'European Region'),
+    ('Morocco', 'Africa', 'Eastern Mediterranean Region'),
+    ('Mozambique', 'Africa', 'African Region'),
+    ('Myanmar', 'Asia', 'South-East Asia Region'),
+    ('Namibia', 'Africa', 'African Region'),
+    ('Nauru', 'Oceania', 'Western Pacific Region'),
+    ('Nepal', 'Asia', 'South-East Asia Region'),
+    ('Netherlands', 'Europe', 'European Region'),
+    ('New Zealand', 'Oceania', 'Western Pacific Region'),
+    ('Nicaragua', 'North America', 'Region of the Americas'),
+    ('Niger', 'Africa', 'African Region'),
+    ('Nigeria', 'Africa', 'African Region'),
+    ('Niue', 'Oceania', 'Western Pacific Region'),
+    ('North Korea', 'Asia', 'South-East Asia Region'),
+    ('North Macedonia', 'Europe', 'European Region'),
+    ('Norway', 'Europe', 'European Region'),
+    ('Oman', 'Asia', 'Eastern Mediterranean Region'),
+    ('Pakistan', 'Asia', 'Eastern Mediterranean Region'),
+    ('Palau', 'Oceania', 'Western Pacific Region'),
+    ('Palestine', 'Asia', 'Eastern Mediterranean Region'),
+    ('Panama', 'North America', 'Region of the Americas'),
+    ('Papua New Guinea', 'Oceania', 'Western Pacific Region'),
+    ('Paraguay', 'South America', 'Region of the Americas'),
+    ('Peru', 'South America', 'Region of the Americas'),
+    ('Philippines', 'Asia', 'Western Pacific Region'),
+    ('Poland', 'Europe', 'European Region'),
+    ('Portugal', 'Europe', 'European Region'),
+    ('Qatar', 'Asia', 'Eastern Mediterranean Region'),
+    ('Romania', 'Europe', 'European Region'),
+    ('Russia', 'Europe', 'European Region'),
+    ('Rwanda', 'Africa', 'African Region'),
+    ('Saint Kitts and Nevis', 'North America', 'Region of the Americas'),
+    ('Saint Lucia', 'North America', 'Region of the Americas'),
+    ('Saint Vincent and the Grenadines', 'North America', 'Region of the Americas'),
+    ('Samoa', 'Oceania', 'Western Pacific Region'),
+    ('San Marino', 'Europe', 'European Region'),
+    ('Sao Tome and Principe', 'Africa', 'African Region'),
+    ('Saudi Arabia', 'Asia', 'Eastern Mediterranean Region'),
+    ('Senegal', 'Africa', 'African Region'),
+    ('Serbia', 'Europe', 'European Region'),
+    ('Seychelles', 'Africa', 'African Region'),
+    ('Sierra Leone', 'Africa', 'African Region'),
+    ('Singapore', 'Asia', 'Western Pacific Region'),
+    ('Slovakia', 'Europe', 'European Region'),
+    ('Slovenia', 'Europe', 'European Region'),
+    ('Solomon Islands', 'Oceania', 'Western Pacific Region'),
+    ('Somalia', 'Africa', 'Eastern Mediterranean Region'),
+    ('South Africa', 'Africa', 'African Region'),
+    ('South Korea', 'Asia', 'Western Pacific Region'),
+    ('South Sudan', 'Africa', 'African Region'),
+    ('Spain', 'Europe', 'European Region'),
+    ('Sri Lanka', 'Asia', 'South-East Asia Region'),
+    ('Sudan', 'Africa', 'Eastern Mediterranean Region'),
+    ('Suriname', 'South America', 'Region of the Americas'),
+    ('Sweden', 'Europe', 'European Region'),
+    ('Switzerland', 'Europe', 'European Region'),
+    ('Syria', 'Asia', 'Eastern Mediterranean Region'),
+    ('Tajikistan', 'Asia', 'European Region'),
+    ('Tanzania', 'Africa', 'African Region'),
+    ('Thailand', 'Asia', 'South-East Asia Region'),
+    ('Timor-Leste', 'Asia', 'South-East Asia Region'),
+    ('Togo', 'Africa', 'African Region'),
+    ('Tonga', 'Oceania', 'Western Pacific Region'),
+    ('Trinidad and Tobago', 'North America', 'Region of the Americas'),
+    ('Tunisia', 'Africa', 'Eastern Mediterranean Region'),
+    ('Turkey', 'Asia', 'European Region'),
+    ('Turkmenistan', 'Asia', 'European Region'),
+    ('Tuvalu', 'Oceania', 'Western Pacific Region'),
+    ('Uganda', 'Africa', 'African Region'),
+    ('Ukraine', 'Europe', 'European Region'),
+    ('United Arab Emirates', 'Asia', 'Eastern Mediterranean Region'),
+    ('United Kingdom', 'Europe', 'European Region'),
+    ('United States', 'North America', 'Region of the Americas'),
+    ('Uruguay', 'South America', 'Region of the Americas'),
+    ('Uzbekistan', 'Asia', 'European Region'),
+    ('Vanuatu', 'Oceania', 'Western Pacific Region'),
+    ('Venezuela', 'South America', 'Region of the Americas'),
+    ('Vietnam', 'Asia', 'Western Pacific Region'),
+    ('Yemen', 'Asia', 'Eastern Mediterranean Region'),
+    ('Zambia', 'Africa', 'African Region'),
+    ('Zimbabwe', 'Africa', 'African Region');
+
+INSERT INTO countries (name)
+SELECT regions_seed.country FROM regions_seed
+WHERE NOT EXISTS (SELECT 1 FROM countries WHERE countries.name = regions_seed.country);
+
+INSERT INTO regions_countries (region_id, country_id)
+SELECT regions.id, countries.id
+FROM regions_seed
+    JOIN regions ON regions.user_id IS NULL AND regions.name IN (regions_seed.continent, regions_seed.who_region)
+    JOIN countries ON countries.name = regions_seed.country
+ON CONFLICT DO NOTHING;
+
+DROP TABLE regions_seed;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS users_subdivisions;
+DROP TABLE IF EXISTS subdivision_statistics;
+DROP TABLE IF EXISTS subdivisions;
+ALTER TABLE countries DROP COLUMN IF EXISTS rollup_subdivisions;
@@ -0,0 +1,29 @@
+-- Provinces and states of the countries, their statistics and the users
+-- subscribed to them. A country with rollup_subdivisions set gets its totals
+-- from the sum of its subdivisions.
+ALTER TABLE countries ADD COLUMN IF NOT EXISTS rollup_subdivisions boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS subdivisions (
+    id serial PRIMARY KEY,
+    country_id integer NOT NULL REFERENCES countries(id),
+    name character varying(100) NOT NULL,
+    CONSTRAINT unique_subdivision_country_name UNIQUE (country_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS subdivision_statistics (
+    subdivision_id integer PRIMARY KEY REFERENCES subdivisions(id),
+    confirmed integer DEFAULT 0,
+    recovered integer DEFAULT 0,
+    death integer DEFAULT 0,
+    last_updated timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS update_subdivision_statistics_last_updated ON subdivision_statistics;
+CREATE TRIGGER update_subdivision_statistics_last_updated BEFORE UPDATE ON subdivision_statistics FOR EACH ROW EXECUTE FUNCTION update_statistics_last_updated();
+
+CREATE TABLE IF NOT EXISTS users_subdivisions (
+    id serial PRIMARY KEY,
+    user_id integer REFERENCES users(id),
+    subdivision_id integer REFERENCES subdivisions(id),
+    CONSTRAINT unique_subdivision_user UNIQUE (user_id, subdivision_id)
+);
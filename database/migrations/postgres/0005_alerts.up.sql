@@ -0,0 +1,23 @@
+-- Threshold rules of the users on the countries they follow and the alerts
+-- they raised. triggered remembers whether the condition held at the last
+-- refresh, so that an alert is only raised when it starts to hold.
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id serial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id),
+    country_id integer NOT NULL REFERENCES countries(id),
+    metric character varying(30) NOT NULL,
+    operator character varying(10) NOT NULL,
+    threshold double precision NOT NULL,
+    triggered boolean NOT NULL DEFAULT false,
+    CONSTRAINT unique_alert_rule UNIQUE (user_id, country_id, metric, operator, threshold)
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id serial PRIMARY KEY,
+    rule_id integer NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    value double precision NOT NULL,
+    triggered_at timestamp without time zone NOT NULL,
+    acknowledged_at timestamp without time zone
+);
+
+CREATE INDEX IF NOT EXISTS alerts_rule_id ON alerts (rule_id);
@@ -0,0 +1,11 @@
+-- Digest preferences of the users, one per channel. An empty destination of
+-- the email channel means the email address of the user, and the OFF
+-- frequency opts out while keeping the destination.
+CREATE TABLE IF NOT EXISTS digest_preferences (
+    user_id integer NOT NULL REFERENCES users(id),
+    channel character varying(10) NOT NULL,
+    destination text NOT NULL DEFAULT '',
+    frequency character varying(10) NOT NULL,
+    last_sent_at timestamp without time zone,
+    PRIMARY KEY (user_id, channel)
+);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_statistics_last_updated;
+DROP TABLE IF EXISTS users_countries;
+DROP TABLE IF EXISTS statistics;
+DROP TABLE IF EXISTS countries;
+DROP TABLE IF EXISTS users;
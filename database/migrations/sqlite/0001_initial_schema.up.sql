@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email character varying(255) NOT NULL UNIQUE,
+    password character varying(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS countries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name character varying(50) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS statistics (
+    country_id integer PRIMARY KEY CONSTRAINT fk_country_statistics REFERENCES countries(id),
+    confirmed integer DEFAULT 0,
+    recovered integer DEFAULT 0,
+    death integer DEFAULT 0,
+    last_updated timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS users_countries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id integer REFERENCES users(id),
+    country_id integer REFERENCES countries(id),
+    CONSTRAINT unique_country_user_name UNIQUE (user_id, country_id)
+);
+
+-- SQLite triggers can't modify NEW, update the row once it is written instead
+CREATE TRIGGER IF NOT EXISTS update_statistics_last_updated AFTER UPDATE OF confirmed, recovered, death ON statistics FOR EACH ROW
+BEGIN
+    UPDATE statistics SET last_updated = CURRENT_TIMESTAMP WHERE country_id = NEW.country_id;
+END;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS users_subdivisions;
+DROP TRIGGER IF EXISTS update_subdivision_statistics_last_updated;
+DROP TABLE IF EXISTS subdivision_statistics;
+DROP TABLE IF EXISTS subdivisions;
+ALTER TABLE countries DROP COLUMN rollup_subdivisions;
@@ -0,0 +1,31 @@
+-- Provinces and states of the countries, their statistics and the users
+-- subscribed to them. A country with rollup_subdivisions set gets its totals
+-- from the sum of its subdivisions.
+ALTER TABLE countries ADD COLUMN rollup_subdivisions boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS subdivisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    country_id integer NOT NULL REFERENCES countries(id),
+    name character varying(100) NOT NULL,
+    CONSTRAINT unique_subdivision_country_name UNIQUE (country_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS subdivision_statistics (
+    subdivision_id integer PRIMARY KEY REFERENCES subdivisions(id),
+    confirmed integer DEFAULT 0,
+    recovered integer DEFAULT 0,
+    death integer DEFAULT 0,
+    last_updated timestamp DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS update_subdivision_statistics_last_updated AFTER UPDATE OF confirmed, recovered, death ON subdivision_statistics FOR EACH ROW
+BEGIN
+    UPDATE subdivision_statistics SET last_updated = CURRENT_TIMESTAMP WHERE subdivision_id = NEW.subdivision_id;
+END;
+
+CREATE TABLE IF NOT EXISTS users_subdivisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id integer REFERENCES users(id),
+    subdivision_id integer REFERENCES subdivisions(id),
+    CONSTRAINT unique_subdivision_user UNIQUE (user_id, subdivision_id)
+);
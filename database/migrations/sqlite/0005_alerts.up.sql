@@ -0,0 +1,23 @@
+-- Threshold rules of the users on the countries they follow and the alerts
+-- they raised. triggered remembers whether the condition held at the last
+-- refresh, so that an alert is only raised when it starts to hold.
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL REFERENCES users(id),
+    country_id integer NOT NULL REFERENCES countries(id),
+    metric character varying(30) NOT NULL,
+    operator character varying(10) NOT NULL,
+    threshold real NOT NULL,
+    triggered boolean NOT NULL DEFAULT false,
+    CONSTRAINT unique_alert_rule UNIQUE (user_id, country_id, metric, operator, threshold)
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id integer NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    value real NOT NULL,
+    triggered_at timestamp NOT NULL,
+    acknowledged_at timestamp
+);
+
+CREATE INDEX IF NOT EXISTS alerts_rule_id ON alerts (rule_id);
@@ -0,0 +1,30 @@
+-- Webhooks of the users and the log of their deliveries. events and
+-- countries are comma separated filters, empty meaning every event type or
+-- every country the user follows. A pending delivery is sent once
+-- next_attempt_at is reached.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL REFERENCES users(id),
+    url text NOT NULL,
+    secret character varying(64) NOT NULL,
+    events text NOT NULL DEFAULT '',
+    countries text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id integer NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event character varying(30) NOT NULL,
+    payload text NOT NULL,
+    status character varying(10) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    response_status integer,
+    error text,
+    next_attempt_at timestamp,
+    created_at timestamp NOT NULL,
+    delivered_at timestamp
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
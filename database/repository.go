@@ -1,114 +1,186 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"errors"
-	"fmt"
+	"strings"
+	"time"
 
 	"github.com/FaresAbuIram/COVID19-Statistics/entity"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type SQLRepositoryInterface interface {
-	UsersCountById(userId int) (int, error)
-	CountriesCountByname(name string) (int, error)
-	InsertCountry(name string) (int, error)
-	InsertStatistic(countryId int) error
-	GetCountryIdByName(name string) (int, error)
-	InsertIntoUsersCountries(userId, countryId int) error
-	GetAllCountriesByUserId(userId int) ([]*model.Country, error)
-	GetPercentageOfDeathToConfirmedByCountryName(userId int, countryName string) (float64, error)
-	GetTopThreeCountriesByUserIdAndType(userId int, status string) ([]*model.Country, error)
-	GetAllCountries() (map[int]string, error)
-	GetAllStatistics() ([]entity.Statistics, error)
-	UpdateArrayOfStatistics(statistics []entity.Statistics)
-	UsersCountByEmail(email string) (int, error)
-	InsertNewUser(email string, password []byte) error
-	FindUserByEmail(email string) (int, []byte, error)
+	UsersCountById(ctx context.Context, userId int) (int, error)
+	CountriesCountByname(ctx context.Context, name string) (int, error)
+	InsertCountry(ctx context.Context, name string) (int, error)
+	InsertStatistic(ctx context.Context, countryId int) error
+	GetCountryIdByName(ctx context.Context, name string) (int, error)
+	InsertIntoUsersCountries(ctx context.Context, userId, countryId int) error
+	GetAllCountriesByUserId(ctx context.Context, userId int) ([]*model.Country, error)
+	GetTopThreeCountriesByUserIdAndType(ctx context.Context, userId int, status string) ([]*model.Country, error)
+	GetAllCountries(ctx context.Context) (map[int]string, error)
+	GetAllStatistics(ctx context.Context) ([]entity.Statistics, error)
+	UpdateArrayOfStatistics(ctx context.Context, statistics []entity.Statistics)
+	UsersCountByEmail(ctx context.Context, email string) (int, error)
+	InsertNewUser(ctx context.Context, email string, password []byte) error
+	FindUserByEmail(ctx context.Context, email string) (int, []byte, error)
+	UpsertDailyStatistic(ctx context.Context, statistic entity.DailyStatistic) error
+	GetDailyStatisticsByCountryName(ctx context.Context, countryName string, from, to time.Time) ([]entity.DailyStatistic, error)
+	InsertRegion(ctx context.Context, name, regionType string, userId int, countryIds []int) (int, error)
+	GetRegionByName(ctx context.Context, userId int, name string) (entity.Region, error)
+	GetRegionsByUserId(ctx context.Context, userId int) ([]entity.Region, error)
+	InsertIntoRegionsCountries(ctx context.Context, regionId, countryId int) error
+	GetCountriesStatisticsByRegionId(ctx context.Context, regionId int) ([]entity.CountryStatistics, error)
+	GetAllCountriesStatistics(ctx context.Context) ([]entity.CountryStatistics, error)
+	UpsertSubdivision(ctx context.Context, countryId int, name string) (int, error)
+	UpsertSubdivisionStatistic(ctx context.Context, statistic entity.SubdivisionStatistics) error
+	GetSubdivisionIdByName(ctx context.Context, countryName, name string) (int, error)
+	InsertIntoUsersSubdivisions(ctx context.Context, userId, subdivisionId int) error
+	GetSubdivisionsStatisticsByUserId(ctx context.Context, userId int) ([]entity.SubdivisionStatistics, error)
+	GetSubdivisionsStatisticsByCountryName(ctx context.Context, countryName string) ([]entity.SubdivisionStatistics, error)
+	GetCountryIdsWithSubdivisionRollup(ctx context.Context) ([]int, error)
+	UpdateSubdivisionRollup(ctx context.Context, countryName string, enabled bool) (int, error)
+	UsersCountriesCount(ctx context.Context, userId, countryId int) (int, error)
+	GetStatisticByCountryId(ctx context.Context, countryId int) (entity.Statistics, error)
+	GetDailyStatisticByCountryIdAndDate(ctx context.Context, countryId int, date time.Time) (entity.DailyStatistic, error)
+	InsertAlertRule(ctx context.Context, rule entity.AlertRule) (int, error)
+	GetAlertRulesByUserId(ctx context.Context, userId int) ([]entity.AlertRule, error)
+	GetAllAlertRules(ctx context.Context) ([]entity.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, userId, ruleId int) (int, error)
+	UpdateAlertRuleTriggered(ctx context.Context, ruleId int, triggered bool) error
+	InsertAlert(ctx context.Context, alert entity.Alert) (int, error)
+	GetAlertsByUserId(ctx context.Context, userId int, unacknowledgedOnly bool) ([]entity.Alert, error)
+	AcknowledgeAlert(ctx context.Context, userId, alertId int, acknowledgedAt time.Time) (int, error)
+	InsertWebhook(ctx context.Context, webhook entity.Webhook) (int, error)
+	GetWebhooksByUserId(ctx context.Context, userId int) ([]entity.Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, userId, webhookId int) (int, error)
+	InsertWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (int, error)
+	GetWebhookDeliveries(ctx context.Context, userId, webhookId, limit int) ([]entity.WebhookDelivery, error)
+	GetWebhookDeliveryById(ctx context.Context, userId, deliveryId int) (entity.WebhookDelivery, error)
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) error
+	UpsertDigestPreference(ctx context.Context, preference entity.DigestPreference) error
+	GetDigestPreferencesByUserId(ctx context.Context, userId int) ([]entity.DigestPreference, error)
+	GetAllDigestPreferences(ctx context.Context) ([]entity.DigestPreference, error)
+	UpdateDigestSentAt(ctx context.Context, userId int, channel string, sentAt time.Time) error
 }
 
 type SQLRepository struct {
 	DB *sql.DB
+	// QueryTimeout bounds every query on top of the caller's context, zero
+	// means no limit.
+	QueryTimeout time.Duration
+	// Metrics records the duration of the queries, nil records nothing.
+	Metrics *metrics.Metrics
 }
 
 func NewSQLRepository(db *sql.DB) *SQLRepository {
 	return &SQLRepository{
-		DB: db,
+		DB:           db,
+		QueryTimeout: DefaultQueryTimeout,
 	}
 }
 
-func (sq *SQLRepository) UsersCountById(userId int) (int, error) {
-	var count int
-	err := sq.DB.QueryRow("SELECT COUNT(*) FROM users WHERE id = $1", userId).Scan(&count)
-	return count, err
+// startQuery derives the context of a single query, named after the
+// repository method, from the caller's one. The returned function ends the
+// query, its span and records its duration.
+func (sq *SQLRepository) startQuery(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "db "+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBOperation(name)))
+	var cancel context.CancelFunc
+	if sq.QueryTimeout <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, sq.QueryTimeout)
+	}
+	return ctx, func() {
+		cancel()
+		span.End()
+		sq.Metrics.ObserveQuery(name, time.Since(start))
+	}
 }
 
-func (sq *SQLRepository) CountriesCountByname(name string) (int, error) {
+func (sq *SQLRepository) UsersCountById(ctx context.Context, userId int) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "UsersCountById")
+	defer cancel()
+
 	var count int
-	err := sq.DB.QueryRow("SELECT COUNT(*) FROM countries WHERE name = $1", name).Scan(&count)
-	return count, err
+	err := sq.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE id = $1", userId).Scan(&count)
+	return count, translateError(err)
 }
 
-func (sq *SQLRepository) InsertCountry(name string) (int, error) {
-	var countryId int
-	err := sq.DB.QueryRow("INSERT INTO countries (name) VALUES ($1) RETURNING id", name).Scan(&countryId)
-	return countryId, err
-}
+func (sq *SQLRepository) CountriesCountByname(ctx context.Context, name string) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "CountriesCountByname")
+	defer cancel()
 
-func (sq *SQLRepository) InsertStatistic(countryId int) error {
-	_, err := sq.DB.Exec("INSERT INTO statistics (country_id) VALUES ($1)", countryId)
-	return err
+	var count int
+	err := sq.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM countries WHERE name = $1", name).Scan(&count)
+	return count, translateError(err)
 }
 
-func (sq *SQLRepository) GetCountryIdByName(name string) (int, error) {
+func (sq *SQLRepository) InsertCountry(ctx context.Context, name string) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "InsertCountry")
+	defer cancel()
+
 	var countryId int
-	err := sq.DB.QueryRow("SELECT id from countries WHERE name = $1", name).Scan(&countryId)
-	return countryId, err
+	err := sq.DB.QueryRowContext(ctx, "INSERT INTO countries (name) VALUES ($1) RETURNING id", name).Scan(&countryId)
+	return countryId, translateError(err)
 }
 
-func (sq *SQLRepository) InsertIntoUsersCountries(userId, countryId int) error {
-	_, err := sq.DB.Exec("INSERT INTO users_countries (user_id, country_id) VALUES ($1, $2)", userId, countryId)
-	return err
+// InsertStatistic inserts the empty statistics of the country, if it has
+// none yet.
+func (sq *SQLRepository) InsertStatistic(ctx context.Context, countryId int) error {
+	ctx, cancel := sq.startQuery(ctx, "InsertStatistic")
+	defer cancel()
+
+	_, err := sq.DB.ExecContext(ctx, "INSERT INTO statistics (country_id) VALUES ($1) ON CONFLICT DO NOTHING", countryId)
+	return translateError(err)
 }
 
-func (sq *SQLRepository) GetAllCountriesByUserId(userId int) ([]*model.Country, error) {
-	rows, err := sq.DB.Query("SELECT name FROM countries WHERE id IN (SELECT country_id FROM users_countries WHERE user_id = $1);", userId)
-	if err != nil {
-		return nil, err
-	}
+func (sq *SQLRepository) GetCountryIdByName(ctx context.Context, name string) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetCountryIdByName")
+	defer cancel()
 
-	countries := make([]*model.Country, 0)
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return nil, err
-		}
-		countries = append(countries, &model.Country{Name: name})
-	}
+	var countryId int
+	err := sq.DB.QueryRowContext(ctx, "SELECT id from countries WHERE name = $1", name).Scan(&countryId)
+	return countryId, translateError(err)
+}
+
+func (sq *SQLRepository) InsertIntoUsersCountries(ctx context.Context, userId, countryId int) error {
+	ctx, cancel := sq.startQuery(ctx, "InsertIntoUsersCountries")
+	defer cancel()
 
-	return countries, nil
+	_, err := sq.DB.ExecContext(ctx, "INSERT INTO users_countries (user_id, country_id) VALUES ($1, $2)", userId, countryId)
+	return translateError(err)
 }
 
-func (sq *SQLRepository) GetPercentageOfDeathToConfirmedByCountryName(userId int, countryName string) (float64, error) {
-	countryId, err := sq.GetCountryIdByName(countryName)
-	if err != nil {
-		return 0.0, err
-	}
-	query := `SELECT
-					 (CAST(statistics.death AS FLOAT) / statistics.confirmed) * 100 AS death_to_confirmed_ratio 
-			  FROM users_countries JOIN statistics ON users_countries.country_id = statistics.country_id 
-			  WHERE users_countries.user_id = $1 AND users_countries.country_id = $2;
-	`
-	var percentage float64
-	err = sq.DB.QueryRow(query, userId, countryId).Scan(&percentage)
-	if err != nil {
-		return 0.0, err
-	}
+func (sq *SQLRepository) GetAllCountriesByUserId(ctx context.Context, userId int) ([]*model.Country, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllCountriesByUserId")
+	defer cancel()
 
-	return percentage, nil
+	query := `SELECT
+					countries.name
+				FROM
+					users_countries
+					JOIN countries ON users_countries.country_id = countries.id
+				WHERE
+					users_countries.user_id = $1
+				ORDER BY
+					countries.id
+				`
+	return sq.queryCountries(ctx, query, userId)
 }
 
-func (sq *SQLRepository) GetTopThreeCountriesByUserIdAndType(userId int, status string) ([]*model.Country, error) {
+func (sq *SQLRepository) GetTopThreeCountriesByUserIdAndType(ctx context.Context, userId int, status string) ([]*model.Country, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetTopThreeCountriesByUserIdAndType")
+	defer cancel()
+
 	query := `SELECT
 					countries.name
 				FROM
@@ -121,99 +193,853 @@ func (sq *SQLRepository) GetTopThreeCountriesByUserIdAndType(userId int, status
 					CASE
 					WHEN $2 = 'confirmed' THEN statistics.confirmed
 					WHEN $3 = 'death' THEN statistics.death
-					END DESC
+					END DESC,
+					countries.id
 				LIMIT
-					3  
+					3
 				`
+	return sq.queryCountries(ctx, query, userId, status, status)
+}
 
-	rows, err := sq.DB.Query(query, userId, status, status)
+// queryCountries runs a query selecting country names.
+func (sq *SQLRepository) queryCountries(ctx context.Context, query string, args ...interface{}) ([]*model.Country, error) {
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
+	defer rows.Close()
 
 	countries := make([]*model.Country, 0)
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 		countries = append(countries, &model.Country{Name: name})
 	}
-	return countries, nil
+
+	return countries, translateError(rows.Err())
 }
 
-func (sq *SQLRepository) GetAllCountries() (map[int]string, error) {
+func (sq *SQLRepository) GetAllCountries(ctx context.Context) (map[int]string, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllCountries")
+	defer cancel()
+
 	// get all countries
-	rows, err := sq.DB.Query("SELECT * from countries")
+	rows, err := sq.DB.QueryContext(ctx, "SELECT id, name FROM countries")
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
+	defer rows.Close()
 
 	countries := make(map[int]string)
 	for rows.Next() {
 		var country entity.Country
 		if err := rows.Scan(&country.ID, &country.Name); err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 		countries[country.ID] = country.Name
 	}
 
-	return countries, nil
+	return countries, translateError(rows.Err())
 }
 
-func (sq *SQLRepository) GetAllStatistics() ([]entity.Statistics, error) {
+func (sq *SQLRepository) GetAllStatistics(ctx context.Context) ([]entity.Statistics, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllStatistics")
+	defer cancel()
+
 	// get all statistics
-	rows, err := sq.DB.Query("SELECT * from statistics")
+	rows, err := sq.DB.QueryContext(ctx, "SELECT country_id, confirmed, death, recovered, last_updated FROM statistics ORDER BY country_id")
 	if err != nil {
-		return []entity.Statistics{}, err
+		return nil, translateError(err)
 	}
+	defer rows.Close()
 
 	statistics := make([]entity.Statistics, 0)
 	for rows.Next() {
 		var statistic entity.Statistics
 		if err := rows.Scan(&statistic.CountryId, &statistic.Confirmed, &statistic.Deaths, &statistic.Recovered, &statistic.LastUpdated); err != nil {
-			return []entity.Statistics{}, err
+			return nil, translateError(err)
 		}
 		statistics = append(statistics, statistic)
 	}
 
-	return statistics, nil
+	return statistics, translateError(rows.Err())
 }
 
-func (sq *SQLRepository) UpdateArrayOfStatistics(statistics []entity.Statistics) {
+func (sq *SQLRepository) UpdateArrayOfStatistics(ctx context.Context, statistics []entity.Statistics) {
 	// Update the database with the data
 	for _, statistic := range statistics {
-		_, err := sq.DB.Exec("UPDATE statistics SET confirmed = $1, death = $2, recovered = $3 WHERE country_id = $4", statistic.Confirmed, statistic.Deaths, statistic.Recovered, statistic.CountryId)
+		if ctx.Err() != nil {
+			return
+		}
+		queryCtx, cancel := sq.startQuery(ctx, "UpdateArrayOfStatistics")
+		_, err := sq.DB.ExecContext(queryCtx, "UPDATE statistics SET confirmed = $1, death = $2, recovered = $3 WHERE country_id = $4", statistic.Confirmed, statistic.Deaths, statistic.Recovered, statistic.CountryId)
+		cancel()
 		if err != nil {
 			continue
 		}
 	}
 }
 
-func (sq *SQLRepository) UsersCountByEmail(email string) (int, error) {
+func (sq *SQLRepository) UsersCountByEmail(ctx context.Context, email string) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "UsersCountByEmail")
+	defer cancel()
+
 	var count int
-	err := sq.DB.QueryRow("SELECT COUNT(*) FROM users WHERE email = $1", email).Scan(&count)
+	err := sq.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE email = $1", email).Scan(&count)
 	if err != nil {
-		return 0, err
+		return 0, translateError(err)
 	}
 
 	return count, nil
 }
 
-func (sq *SQLRepository) InsertNewUser(email string, password []byte) error {
+func (sq *SQLRepository) InsertNewUser(ctx context.Context, email string, password []byte) error {
+	ctx, cancel := sq.startQuery(ctx, "InsertNewUser")
+	defer cancel()
+
 	// Insert the new user into the database
-	_, err := sq.DB.Exec("INSERT INTO users (email, password) VALUES ($1, $2)", email, password)
-	return err
+	_, err := sq.DB.ExecContext(ctx, "INSERT INTO users (email, password) VALUES ($1, $2)", email, password)
+	return translateError(err)
 }
 
-func (sq *SQLRepository) FindUserByEmail(email string) (int, []byte, error) {
+// FindUserByEmail returns ErrNotFound when no user has the email, other
+// errors are returned as is.
+func (sq *SQLRepository) FindUserByEmail(ctx context.Context, email string) (int, []byte, error) {
+	ctx, cancel := sq.startQuery(ctx, "FindUserByEmail")
+	defer cancel()
+
 	// Find the user with the given email address
 	var id int
 	var hashedPassword []byte
-	err := sq.DB.QueryRow("SELECT id, password FROM users WHERE email = $1", email).Scan(&id, &hashedPassword)
+	err := sq.DB.QueryRowContext(ctx, "SELECT id, password FROM users WHERE email = $1", email).Scan(&id, &hashedPassword)
 	if err != nil {
-		return 0, nil, errors.New(fmt.Sprintf("user with email %s not found", email))
+		return 0, nil, translateError(err)
 	}
 
 	return id, hashedPassword, nil
 }
+
+func (sq *SQLRepository) UpsertDailyStatistic(ctx context.Context, statistic entity.DailyStatistic) error {
+	ctx, cancel := sq.startQuery(ctx, "UpsertDailyStatistic")
+	defer cancel()
+
+	query := `INSERT INTO daily_statistics (country_id, date, confirmed, death, recovered)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (country_id, date)
+			  DO UPDATE SET confirmed = EXCLUDED.confirmed, death = EXCLUDED.death, recovered = EXCLUDED.recovered`
+	_, err := sq.DB.ExecContext(ctx, query, statistic.CountryId, statistic.Date, statistic.Confirmed, statistic.Deaths, statistic.Recovered)
+	return translateError(err)
+}
+
+func (sq *SQLRepository) GetDailyStatisticsByCountryName(ctx context.Context, countryName string, from, to time.Time) ([]entity.DailyStatistic, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetDailyStatisticsByCountryName")
+	defer cancel()
+
+	query := `SELECT
+					daily_statistics.country_id, daily_statistics.date, daily_statistics.confirmed, daily_statistics.death, daily_statistics.recovered
+				FROM
+					daily_statistics
+					JOIN countries ON daily_statistics.country_id = countries.id
+				WHERE
+					countries.name = $1 AND daily_statistics.date BETWEEN $2 AND $3
+				ORDER BY
+					daily_statistics.date
+				`
+	rows, err := sq.DB.QueryContext(ctx, query, countryName, from, to)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	statistics := make([]entity.DailyStatistic, 0)
+	for rows.Next() {
+		var statistic entity.DailyStatistic
+		if err := rows.Scan(&statistic.CountryId, &statistic.Date, &statistic.Confirmed, &statistic.Deaths, &statistic.Recovered); err != nil {
+			return nil, translateError(err)
+		}
+		statistics = append(statistics, statistic)
+	}
+
+	return statistics, translateError(rows.Err())
+}
+
+// InsertRegion inserts the region along with its countries in a single
+// transaction, so that a region is never left with part of them.
+func (sq *SQLRepository) InsertRegion(ctx context.Context, name, regionType string, userId int, countryIds []int) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "InsertRegion")
+	defer cancel()
+
+	tx, err := sq.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	defer tx.Rollback()
+
+	var regionId int
+	err = tx.QueryRowContext(ctx, "INSERT INTO regions (name, type, user_id) VALUES ($1, $2, $3) RETURNING id", name, regionType, nullableId(userId)).Scan(&regionId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	for _, countryId := range countryIds {
+		_, err := tx.ExecContext(ctx, "INSERT INTO regions_countries (region_id, country_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", regionId, countryId)
+		if err != nil {
+			return 0, translateError(err)
+		}
+	}
+	return regionId, translateError(tx.Commit())
+}
+
+// GetRegionByName looks the region up among the shared regions and the ones
+// owned by the user, the user's own region wins when both have the same name.
+func (sq *SQLRepository) GetRegionByName(ctx context.Context, userId int, name string) (entity.Region, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetRegionByName")
+	defer cancel()
+
+	query := `SELECT
+					id, name, type, COALESCE(user_id, 0)
+				FROM
+					regions
+				WHERE
+					name = $1 AND (user_id IS NULL OR user_id = $2)
+				ORDER BY
+					user_id NULLS LAST
+				LIMIT
+					1
+				`
+	var region entity.Region
+	err := sq.DB.QueryRowContext(ctx, query, name, userId).Scan(&region.ID, &region.Name, &region.Type, &region.UserId)
+	return region, translateError(err)
+}
+
+func (sq *SQLRepository) GetRegionsByUserId(ctx context.Context, userId int) ([]entity.Region, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetRegionsByUserId")
+	defer cancel()
+
+	rows, err := sq.DB.QueryContext(ctx, "SELECT id, name, type, COALESCE(user_id, 0) FROM regions WHERE user_id IS NULL OR user_id = $1 ORDER BY type, name", userId)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	regions := make([]entity.Region, 0)
+	for rows.Next() {
+		var region entity.Region
+		if err := rows.Scan(&region.ID, &region.Name, &region.Type, &region.UserId); err != nil {
+			return nil, translateError(err)
+		}
+		regions = append(regions, region)
+	}
+
+	return regions, translateError(rows.Err())
+}
+
+func (sq *SQLRepository) InsertIntoRegionsCountries(ctx context.Context, regionId, countryId int) error {
+	ctx, cancel := sq.startQuery(ctx, "InsertIntoRegionsCountries")
+	defer cancel()
+
+	_, err := sq.DB.ExecContext(ctx, "INSERT INTO regions_countries (region_id, country_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", regionId, countryId)
+	return translateError(err)
+}
+
+// GetCountriesStatisticsByRegionId returns every country of the region, the
+// ones without statistics yet being flagged as Missing.
+func (sq *SQLRepository) GetCountriesStatisticsByRegionId(ctx context.Context, regionId int) ([]entity.CountryStatistics, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetCountriesStatisticsByRegionId")
+	defer cancel()
+
+	query := `SELECT
+					countries.name, COALESCE(statistics.confirmed, 0), COALESCE(statistics.death, 0), COALESCE(statistics.recovered, 0), statistics.country_id IS NULL
+				FROM
+					regions_countries
+					JOIN countries ON regions_countries.country_id = countries.id
+					LEFT JOIN statistics ON regions_countries.country_id = statistics.country_id
+				WHERE
+					regions_countries.region_id = $1
+				ORDER BY
+					countries.name
+				`
+	return sq.queryCountriesStatistics(ctx, query, regionId)
+}
+
+// GetAllCountriesStatistics returns every country, the ones without
+// statistics yet being flagged as Missing.
+func (sq *SQLRepository) GetAllCountriesStatistics(ctx context.Context) ([]entity.CountryStatistics, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllCountriesStatistics")
+	defer cancel()
+
+	query := `SELECT
+					countries.name, COALESCE(statistics.confirmed, 0), COALESCE(statistics.death, 0), COALESCE(statistics.recovered, 0), statistics.country_id IS NULL
+				FROM
+					countries
+					LEFT JOIN statistics ON countries.id = statistics.country_id
+				ORDER BY
+					countries.name
+				`
+	return sq.queryCountriesStatistics(ctx, query)
+}
+
+func (sq *SQLRepository) queryCountriesStatistics(ctx context.Context, query string, args ...interface{}) ([]entity.CountryStatistics, error) {
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	statistics := make([]entity.CountryStatistics, 0)
+	for rows.Next() {
+		var statistic entity.CountryStatistics
+		if err := rows.Scan(&statistic.Name, &statistic.Confirmed, &statistic.Deaths, &statistic.Recovered, &statistic.Missing); err != nil {
+			return nil, translateError(err)
+		}
+		statistics = append(statistics, statistic)
+	}
+
+	return statistics, translateError(rows.Err())
+}
+
+// nullableId stores the zero id as NULL.
+func nullableId(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
+
+func (sq *SQLRepository) UpsertSubdivision(ctx context.Context, countryId int, name string) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "UpsertSubdivision")
+	defer cancel()
+
+	query := `INSERT INTO subdivisions (country_id, name) VALUES ($1, $2)
+			  ON CONFLICT (country_id, name) DO UPDATE SET name = EXCLUDED.name
+			  RETURNING id`
+	var subdivisionId int
+	err := sq.DB.QueryRowContext(ctx, query, countryId, name).Scan(&subdivisionId)
+	return subdivisionId, translateError(err)
+}
+
+func (sq *SQLRepository) UpsertSubdivisionStatistic(ctx context.Context, statistic entity.SubdivisionStatistics) error {
+	ctx, cancel := sq.startQuery(ctx, "UpsertSubdivisionStatistic")
+	defer cancel()
+
+	query := `INSERT INTO subdivision_statistics (subdivision_id, confirmed, death, recovered)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (subdivision_id)
+			  DO UPDATE SET confirmed = EXCLUDED.confirmed, death = EXCLUDED.death, recovered = EXCLUDED.recovered`
+	_, err := sq.DB.ExecContext(ctx, query, statistic.SubdivisionId, statistic.Confirmed, statistic.Deaths, statistic.Recovered)
+	return translateError(err)
+}
+
+func (sq *SQLRepository) GetSubdivisionIdByName(ctx context.Context, countryName, name string) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetSubdivisionIdByName")
+	defer cancel()
+
+	query := `SELECT
+					subdivisions.id
+				FROM
+					subdivisions
+					JOIN countries ON subdivisions.country_id = countries.id
+				WHERE
+					countries.name = $1 AND subdivisions.name = $2
+				`
+	var subdivisionId int
+	err := sq.DB.QueryRowContext(ctx, query, countryName, name).Scan(&subdivisionId)
+	return subdivisionId, translateError(err)
+}
+
+func (sq *SQLRepository) InsertIntoUsersSubdivisions(ctx context.Context, userId, subdivisionId int) error {
+	ctx, cancel := sq.startQuery(ctx, "InsertIntoUsersSubdivisions")
+	defer cancel()
+
+	_, err := sq.DB.ExecContext(ctx, "INSERT INTO users_subdivisions (user_id, subdivision_id) VALUES ($1, $2)", userId, subdivisionId)
+	return translateError(err)
+}
+
+func (sq *SQLRepository) GetSubdivisionsStatisticsByUserId(ctx context.Context, userId int) ([]entity.SubdivisionStatistics, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetSubdivisionsStatisticsByUserId")
+	defer cancel()
+
+	query := `SELECT
+					subdivisions.id, countries.name, subdivisions.name,
+					subdivision_statistics.confirmed, subdivision_statistics.death, subdivision_statistics.recovered
+				FROM
+					users_subdivisions
+					JOIN subdivisions ON users_subdivisions.subdivision_id = subdivisions.id
+					JOIN countries ON subdivisions.country_id = countries.id
+					JOIN subdivision_statistics ON subdivisions.id = subdivision_statistics.subdivision_id
+				WHERE
+					users_subdivisions.user_id = $1
+				ORDER BY
+					countries.name, subdivisions.name
+				`
+	return sq.querySubdivisionsStatistics(ctx, query, userId)
+}
+
+func (sq *SQLRepository) GetSubdivisionsStatisticsByCountryName(ctx context.Context, countryName string) ([]entity.SubdivisionStatistics, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetSubdivisionsStatisticsByCountryName")
+	defer cancel()
+
+	query := `SELECT
+					subdivisions.id, countries.name, subdivisions.name,
+					subdivision_statistics.confirmed, subdivision_statistics.death, subdivision_statistics.recovered
+				FROM
+					subdivisions
+					JOIN countries ON subdivisions.country_id = countries.id
+					JOIN subdivision_statistics ON subdivisions.id = subdivision_statistics.subdivision_id
+				WHERE
+					countries.name = $1
+				ORDER BY
+					subdivisions.name
+				`
+	return sq.querySubdivisionsStatistics(ctx, query, countryName)
+}
+
+func (sq *SQLRepository) querySubdivisionsStatistics(ctx context.Context, query string, args ...interface{}) ([]entity.SubdivisionStatistics, error) {
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	statistics := make([]entity.SubdivisionStatistics, 0)
+	for rows.Next() {
+		var statistic entity.SubdivisionStatistics
+		if err := rows.Scan(&statistic.SubdivisionId, &statistic.Country, &statistic.Name, &statistic.Confirmed, &statistic.Deaths, &statistic.Recovered); err != nil {
+			return nil, translateError(err)
+		}
+		statistics = append(statistics, statistic)
+	}
+
+	return statistics, translateError(rows.Err())
+}
+
+func (sq *SQLRepository) GetCountryIdsWithSubdivisionRollup(ctx context.Context) ([]int, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetCountryIdsWithSubdivisionRollup")
+	defer cancel()
+
+	rows, err := sq.DB.QueryContext(ctx, "SELECT id FROM countries WHERE rollup_subdivisions")
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	countryIds := make([]int, 0)
+	for rows.Next() {
+		var countryId int
+		if err := rows.Scan(&countryId); err != nil {
+			return nil, translateError(err)
+		}
+		countryIds = append(countryIds, countryId)
+	}
+
+	return countryIds, translateError(rows.Err())
+}
+
+// UpdateSubdivisionRollup returns the number of updated countries, 0 when the
+// country doesn't exist.
+func (sq *SQLRepository) UpdateSubdivisionRollup(ctx context.Context, countryName string, enabled bool) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "UpdateSubdivisionRollup")
+	defer cancel()
+
+	result, err := sq.DB.ExecContext(ctx, "UPDATE countries SET rollup_subdivisions = $1 WHERE name = $2", enabled, countryName)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+func (sq *SQLRepository) UsersCountriesCount(ctx context.Context, userId, countryId int) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "UsersCountriesCount")
+	defer cancel()
+
+	var count int
+	err := sq.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users_countries WHERE user_id = $1 AND country_id = $2", userId, countryId).Scan(&count)
+	return count, translateError(err)
+}
+
+func (sq *SQLRepository) GetStatisticByCountryId(ctx context.Context, countryId int) (entity.Statistics, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetStatisticByCountryId")
+	defer cancel()
+
+	var statistic entity.Statistics
+	err := sq.DB.QueryRowContext(ctx, "SELECT country_id, confirmed, death, recovered, last_updated FROM statistics WHERE country_id = $1", countryId).
+		Scan(&statistic.CountryId, &statistic.Confirmed, &statistic.Deaths, &statistic.Recovered, &statistic.LastUpdated)
+	return statistic, translateError(err)
+}
+
+func (sq *SQLRepository) GetDailyStatisticByCountryIdAndDate(ctx context.Context, countryId int, date time.Time) (entity.DailyStatistic, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetDailyStatisticByCountryIdAndDate")
+	defer cancel()
+
+	var statistic entity.DailyStatistic
+	err := sq.DB.QueryRowContext(ctx, "SELECT country_id, date, confirmed, death, recovered FROM daily_statistics WHERE country_id = $1 AND date = $2", countryId, date).
+		Scan(&statistic.CountryId, &statistic.Date, &statistic.Confirmed, &statistic.Deaths, &statistic.Recovered)
+	return statistic, translateError(err)
+}
+
+func (sq *SQLRepository) InsertAlertRule(ctx context.Context, rule entity.AlertRule) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "InsertAlertRule")
+	defer cancel()
+
+	var ruleId int
+	err := sq.DB.QueryRowContext(ctx, "INSERT INTO alert_rules (user_id, country_id, metric, operator, threshold) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		rule.UserId, rule.CountryId, rule.Metric, rule.Operator, rule.Threshold).Scan(&ruleId)
+	return ruleId, translateError(err)
+}
+
+func (sq *SQLRepository) GetAlertRulesByUserId(ctx context.Context, userId int) ([]entity.AlertRule, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAlertRulesByUserId")
+	defer cancel()
+
+	return sq.queryAlertRules(ctx, "WHERE alert_rules.user_id = $1", userId)
+}
+
+func (sq *SQLRepository) GetAllAlertRules(ctx context.Context) ([]entity.AlertRule, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllAlertRules")
+	defer cancel()
+
+	return sq.queryAlertRules(ctx, "")
+}
+
+func (sq *SQLRepository) queryAlertRules(ctx context.Context, where string, args ...interface{}) ([]entity.AlertRule, error) {
+	query := `SELECT
+					alert_rules.id, alert_rules.user_id, alert_rules.country_id, countries.name,
+					alert_rules.metric, alert_rules.operator, alert_rules.threshold, alert_rules.triggered
+				FROM
+					alert_rules
+					JOIN countries ON alert_rules.country_id = countries.id
+				` + where + `
+				ORDER BY
+					alert_rules.id
+				`
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	rules := make([]entity.AlertRule, 0)
+	for rows.Next() {
+		var rule entity.AlertRule
+		if err := rows.Scan(&rule.ID, &rule.UserId, &rule.CountryId, &rule.Country, &rule.Metric, &rule.Operator, &rule.Threshold, &rule.Triggered); err != nil {
+			return nil, translateError(err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, translateError(rows.Err())
+}
+
+// DeleteAlertRule deletes the rule along with its alerts and returns the
+// number of deleted rules, 0 when the user has no such rule.
+func (sq *SQLRepository) DeleteAlertRule(ctx context.Context, userId, ruleId int) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "DeleteAlertRule")
+	defer cancel()
+
+	result, err := sq.DB.ExecContext(ctx, "DELETE FROM alert_rules WHERE id = $1 AND user_id = $2", ruleId, userId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+func (sq *SQLRepository) UpdateAlertRuleTriggered(ctx context.Context, ruleId int, triggered bool) error {
+	ctx, cancel := sq.startQuery(ctx, "UpdateAlertRuleTriggered")
+	defer cancel()
+
+	_, err := sq.DB.ExecContext(ctx, "UPDATE alert_rules SET triggered = $1 WHERE id = $2", triggered, ruleId)
+	return translateError(err)
+}
+
+func (sq *SQLRepository) InsertAlert(ctx context.Context, alert entity.Alert) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "InsertAlert")
+	defer cancel()
+
+	var alertId int
+	err := sq.DB.QueryRowContext(ctx, "INSERT INTO alerts (rule_id, value, triggered_at) VALUES ($1, $2, $3) RETURNING id",
+		alert.RuleId, alert.Value, alert.TriggeredAt.UTC()).Scan(&alertId)
+	return alertId, translateError(err)
+}
+
+// GetAlertsByUserId returns the alerts of the user, the latest first.
+func (sq *SQLRepository) GetAlertsByUserId(ctx context.Context, userId int, unacknowledgedOnly bool) ([]entity.Alert, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAlertsByUserId")
+	defer cancel()
+
+	query := `SELECT
+					alerts.id, alerts.rule_id, alert_rules.user_id, countries.name, alert_rules.metric, alert_rules.operator,
+					alert_rules.threshold, alerts.value, alerts.triggered_at, alerts.acknowledged_at
+				FROM
+					alerts
+					JOIN alert_rules ON alerts.rule_id = alert_rules.id
+					JOIN countries ON alert_rules.country_id = countries.id
+				WHERE
+					alert_rules.user_id = $1 AND ($2 = false OR alerts.acknowledged_at IS NULL)
+				ORDER BY
+					alerts.triggered_at DESC, alerts.id DESC
+				`
+	rows, err := sq.DB.QueryContext(ctx, query, userId, unacknowledgedOnly)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	alerts := make([]entity.Alert, 0)
+	for rows.Next() {
+		var alert entity.Alert
+		if err := rows.Scan(&alert.ID, &alert.RuleId, &alert.UserId, &alert.Country, &alert.Metric, &alert.Operator,
+			&alert.Threshold, &alert.Value, &alert.TriggeredAt, &alert.AcknowledgedAt); err != nil {
+			return nil, translateError(err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, translateError(rows.Err())
+}
+
+// AcknowledgeAlert returns the number of alerts of the user with the id, an
+// alert keeps the time it was first acknowledged at.
+func (sq *SQLRepository) AcknowledgeAlert(ctx context.Context, userId, alertId int, acknowledgedAt time.Time) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "AcknowledgeAlert")
+	defer cancel()
+
+	query := `UPDATE alerts SET acknowledged_at = COALESCE(acknowledged_at, $1)
+			  WHERE id = $2 AND rule_id IN (SELECT id FROM alert_rules WHERE user_id = $3)`
+	result, err := sq.DB.ExecContext(ctx, query, acknowledgedAt.UTC(), alertId, userId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+func (sq *SQLRepository) InsertWebhook(ctx context.Context, webhook entity.Webhook) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "InsertWebhook")
+	defer cancel()
+
+	var webhookId int
+	err := sq.DB.QueryRowContext(ctx, "INSERT INTO webhooks (user_id, url, secret, events, countries, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		webhook.UserId, webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","), strings.Join(webhook.Countries, ","), webhook.CreatedAt.UTC()).Scan(&webhookId)
+	return webhookId, translateError(err)
+}
+
+func (sq *SQLRepository) GetWebhooksByUserId(ctx context.Context, userId int) ([]entity.Webhook, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetWebhooksByUserId")
+	defer cancel()
+
+	return sq.queryWebhooks(ctx, "WHERE user_id = $1", userId)
+}
+
+func (sq *SQLRepository) GetAllWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllWebhooks")
+	defer cancel()
+
+	return sq.queryWebhooks(ctx, "")
+}
+
+func (sq *SQLRepository) queryWebhooks(ctx context.Context, where string, args ...interface{}) ([]entity.Webhook, error) {
+	query := "SELECT id, user_id, url, secret, events, countries, created_at FROM webhooks " + where + " ORDER BY id"
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]entity.Webhook, 0)
+	for rows.Next() {
+		var webhook entity.Webhook
+		var events, countries string
+		if err := rows.Scan(&webhook.ID, &webhook.UserId, &webhook.URL, &webhook.Secret, &events, &countries, &webhook.CreatedAt); err != nil {
+			return nil, translateError(err)
+		}
+		webhook.Events = splitList(events)
+		webhook.Countries = splitList(countries)
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, translateError(rows.Err())
+}
+
+// DeleteWebhook deletes the webhook along with its deliveries and returns the
+// number of deleted webhooks, 0 when the user has no such webhook.
+func (sq *SQLRepository) DeleteWebhook(ctx context.Context, userId, webhookId int) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "DeleteWebhook")
+	defer cancel()
+
+	result, err := sq.DB.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1 AND user_id = $2", webhookId, userId)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+func (sq *SQLRepository) InsertWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (int, error) {
+	ctx, cancel := sq.startQuery(ctx, "InsertWebhookDelivery")
+	defer cancel()
+
+	var deliveryId int
+	err := sq.DB.QueryRowContext(ctx, "INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		delivery.WebhookId, delivery.Event, delivery.Payload, delivery.Status, utcTime(delivery.NextAttemptAt), delivery.CreatedAt.UTC()).Scan(&deliveryId)
+	return deliveryId, translateError(err)
+}
+
+// GetWebhookDeliveries returns the last deliveries of the webhook of the
+// user, the latest first.
+func (sq *SQLRepository) GetWebhookDeliveries(ctx context.Context, userId, webhookId, limit int) ([]entity.WebhookDelivery, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetWebhookDeliveries")
+	defer cancel()
+
+	return sq.queryWebhookDeliveries(ctx, "WHERE webhooks.user_id = $1 AND webhooks.id = $2 ORDER BY webhook_deliveries.id DESC LIMIT $3", userId, webhookId, limit)
+}
+
+func (sq *SQLRepository) GetWebhookDeliveryById(ctx context.Context, userId, deliveryId int) (entity.WebhookDelivery, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetWebhookDeliveryById")
+	defer cancel()
+
+	deliveries, err := sq.queryWebhookDeliveries(ctx, "WHERE webhooks.user_id = $1 AND webhook_deliveries.id = $2", userId, deliveryId)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+	if len(deliveries) == 0 {
+		return entity.WebhookDelivery{}, ErrNotFound
+	}
+	return deliveries[0], nil
+}
+
+// GetDueWebhookDeliveries returns the pending deliveries whose next attempt
+// is due at now, the oldest first.
+func (sq *SQLRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetDueWebhookDeliveries")
+	defer cancel()
+
+	return sq.queryWebhookDeliveries(ctx, "WHERE webhook_deliveries.status = $1 AND webhook_deliveries.next_attempt_at <= $2 ORDER BY webhook_deliveries.next_attempt_at, webhook_deliveries.id LIMIT $3",
+		string(model.WebhookDeliveryStatusPending), now.UTC(), limit)
+}
+
+func (sq *SQLRepository) queryWebhookDeliveries(ctx context.Context, where string, args ...interface{}) ([]entity.WebhookDelivery, error) {
+	query := `SELECT
+					webhook_deliveries.id, webhook_deliveries.webhook_id, webhooks.url, webhooks.secret,
+					webhook_deliveries.event, webhook_deliveries.payload, webhook_deliveries.status, webhook_deliveries.attempts,
+					webhook_deliveries.response_status, webhook_deliveries.error, webhook_deliveries.next_attempt_at,
+					webhook_deliveries.created_at, webhook_deliveries.delivered_at
+				FROM
+					webhook_deliveries
+					JOIN webhooks ON webhook_deliveries.webhook_id = webhooks.id
+				` + where
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery entity.WebhookDelivery
+		if err := rows.Scan(&delivery.ID, &delivery.WebhookId, &delivery.URL, &delivery.Secret,
+			&delivery.Event, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+			&delivery.ResponseStatus, &delivery.Error, &delivery.NextAttemptAt,
+			&delivery.CreatedAt, &delivery.DeliveredAt); err != nil {
+			return nil, translateError(err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, translateError(rows.Err())
+}
+
+// UpdateWebhookDelivery stores the outcome of an attempt of the delivery.
+func (sq *SQLRepository) UpdateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	ctx, cancel := sq.startQuery(ctx, "UpdateWebhookDelivery")
+	defer cancel()
+
+	query := `UPDATE webhook_deliveries
+			  SET status = $1, attempts = $2, response_status = $3, error = $4, next_attempt_at = $5, delivered_at = $6
+			  WHERE id = $7`
+	_, err := sq.DB.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		utcTime(delivery.NextAttemptAt), utcTime(delivery.DeliveredAt), delivery.ID)
+	return translateError(err)
+}
+
+// UpsertDigestPreference sets the destination and the frequency of the
+// channel of the user, keeping the time the last digest was sent at.
+func (sq *SQLRepository) UpsertDigestPreference(ctx context.Context, preference entity.DigestPreference) error {
+	ctx, cancel := sq.startQuery(ctx, "UpsertDigestPreference")
+	defer cancel()
+
+	query := `INSERT INTO digest_preferences (user_id, channel, destination, frequency) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id, channel)
+			  DO UPDATE SET destination = EXCLUDED.destination, frequency = EXCLUDED.frequency`
+	_, err := sq.DB.ExecContext(ctx, query, preference.UserId, preference.Channel, preference.Destination, preference.Frequency)
+	return translateError(err)
+}
+
+// GetDigestPreferencesByUserId returns the preferences of the user, their
+// empty destinations being the email address of the user.
+func (sq *SQLRepository) GetDigestPreferencesByUserId(ctx context.Context, userId int) ([]entity.DigestPreference, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetDigestPreferencesByUserId")
+	defer cancel()
+
+	return sq.queryDigestPreferences(ctx, "WHERE digest_preferences.user_id = $1", userId)
+}
+
+func (sq *SQLRepository) GetAllDigestPreferences(ctx context.Context) ([]entity.DigestPreference, error) {
+	ctx, cancel := sq.startQuery(ctx, "GetAllDigestPreferences")
+	defer cancel()
+
+	return sq.queryDigestPreferences(ctx, "")
+}
+
+func (sq *SQLRepository) queryDigestPreferences(ctx context.Context, where string, args ...interface{}) ([]entity.DigestPreference, error) {
+	query := `SELECT
+					digest_preferences.user_id, digest_preferences.channel,
+					COALESCE(NULLIF(digest_preferences.destination, ''), users.email),
+					digest_preferences.frequency, digest_preferences.last_sent_at
+				FROM
+					digest_preferences
+					JOIN users ON digest_preferences.user_id = users.id
+				` + where + `
+				ORDER BY
+					digest_preferences.user_id, digest_preferences.channel
+				`
+	rows, err := sq.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	preferences := make([]entity.DigestPreference, 0)
+	for rows.Next() {
+		var preference entity.DigestPreference
+		if err := rows.Scan(&preference.UserId, &preference.Channel, &preference.Destination, &preference.Frequency, &preference.LastSentAt); err != nil {
+			return nil, translateError(err)
+		}
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, translateError(rows.Err())
+}
+
+func (sq *SQLRepository) UpdateDigestSentAt(ctx context.Context, userId int, channel string, sentAt time.Time) error {
+	ctx, cancel := sq.startQuery(ctx, "UpdateDigestSentAt")
+	defer cancel()
+
+	_, err := sq.DB.ExecContext(ctx, "UPDATE digest_preferences SET last_sent_at = $1 WHERE user_id = $2 AND channel = $3", sentAt.UTC(), userId, channel)
+	return translateError(err)
+}
+
+// splitList reads the comma separated values stored by strings.Join.
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+// utcTime returns the optional time in UTC, like the other stored times.
+func utcTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
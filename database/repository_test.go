@@ -0,0 +1,822 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+)
+
+// newTestDB opens an empty in-memory SQLite database closed at the end of the test.
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newTestRepository returns a repository backed by a migrated in-memory
+// SQLite database.
+func newTestRepository(t *testing.T) SQLRepositoryInterface {
+	db := newTestDB(t)
+	if _, err := MigrateUp(db, DriverSQLite); err != nil {
+		t.Fatalf("failed to migrate sqlite: %v", err)
+	}
+	clearSeededCountries(t, db, "DELETE FROM regions_countries; DELETE FROM countries; DELETE FROM sqlite_sequence WHERE name = 'countries'")
+	return NewSQLRepository(db)
+}
+
+// newPostgresTestRepository returns a repository backed by the Postgres
+// database of TEST_POSTGRES_DSN, its schema is dropped and migrated again so
+// never point it at a database holding real data.
+func newPostgresTestRepository(t *testing.T) SQLRepositoryInterface {
+	db, err := sql.Open(DriverPostgres, os.Getenv("TEST_POSTGRES_DSN"))
+	if err != nil {
+		t.Fatalf("failed to open postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := LoadMigrations(DriverPostgres)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := MigrateDown(db, DriverPostgres, len(migrations)); err != nil {
+		t.Fatalf("failed to reset postgres: %v", err)
+	}
+	if _, err := MigrateUp(db, DriverPostgres); err != nil {
+		t.Fatalf("failed to migrate postgres: %v", err)
+	}
+	clearSeededCountries(t, db, "TRUNCATE countries RESTART IDENTITY CASCADE")
+	return NewSQLRepository(db)
+}
+
+// clearSeededCountries removes the countries seeded by the regions migration,
+// which the memory repository doesn't have, so that every implementation
+// starts without countries.
+func clearSeededCountries(t *testing.T, db *sql.DB, query string) {
+	if _, err := db.Exec(query); err != nil {
+		t.Fatalf("failed to clear the seeded countries: %v", err)
+	}
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) SQLRepositoryInterface { return NewMemoryRepository() })
+}
+
+func TestSQLiteRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, newTestRepository)
+}
+
+func TestPostgresRepositoryContract(t *testing.T) {
+	if os.Getenv("TEST_POSTGRES_DSN") == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	testRepositoryContract(t, newPostgresTestRepository)
+}
+
+// testRepositoryContract runs the behaviour every SQLRepositoryInterface
+// implementation must share against a fresh repository for each case.
+func testRepositoryContract(t *testing.T, newRepository func(t *testing.T) SQLRepositoryInterface) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, repository SQLRepositoryInterface)
+	}{
+		{"Users", testContractUsers},
+		{"Subscriptions", testContractSubscriptions},
+		{"DailyStatistics", testContractDailyStatistics},
+		{"Regions", testContractRegions},
+		{"Subdivisions", testContractSubdivisions},
+		{"Alerts", testContractAlerts},
+		{"Webhooks", testContractWebhooks},
+		{"DigestPreferences", testContractDigestPreferences},
+		{"Concurrency", testContractConcurrency},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newRepository(t))
+		})
+	}
+}
+
+// insertTestUser inserts a user and returns its id.
+func insertTestUser(t *testing.T, repository SQLRepositoryInterface, email string) int {
+	ctx := context.Background()
+	if err := repository.InsertNewUser(ctx, email, []byte("hashed")); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	userId, _, err := repository.FindUserByEmail(ctx, email)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	return userId
+}
+
+// insertTestCountry inserts a country along with its statistics and returns
+// its id.
+func insertTestCountry(t *testing.T, repository SQLRepositoryInterface, name string, confirmed, deaths, recovered int) int {
+	ctx := context.Background()
+	countryId, err := repository.InsertCountry(ctx, name)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if err := repository.InsertStatistic(ctx, countryId); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	repository.UpdateArrayOfStatistics(ctx, []entity.Statistics{{CountryId: countryId, Confirmed: confirmed, Deaths: deaths, Recovered: recovered}})
+	return countryId
+}
+
+func testContractUsers(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+
+	_, password, err := repository.FindUserByEmail(ctx, "test@test.com")
+
+	// Test cases
+	if err != nil || string(password) != "hashed" {
+		t.Errorf("expected the inserted user; got %v, %v", string(password), err)
+	}
+
+	// Test cases
+	if err := repository.InsertNewUser(ctx, "test@test.com", []byte("other")); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict; got %v", err)
+	}
+
+	count, err := repository.UsersCountByEmail(ctx, "test@test.com")
+
+	// Test cases
+	if err != nil || count != 1 {
+		t.Errorf("expected 1 user; got %v and %v", count, err)
+	}
+
+	count, err = repository.UsersCountById(ctx, userId+1)
+
+	// Test cases
+	if err != nil || count != 0 {
+		t.Errorf("expected 0 users; got %v and %v", count, err)
+	}
+
+	_, _, err = repository.FindUserByEmail(ctx, "unknown@test.com")
+
+	// Test cases
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+}
+
+func testContractSubscriptions(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	for i, name := range []string{"Palestine", "Jordan", "Syria", "Egypt"} {
+		countryId := insertTestCountry(t, repository, name, (i+1)*100, 4-i, (i+1)*10)
+		if err := repository.InsertIntoUsersCountries(ctx, userId, countryId); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+	insertTestCountry(t, repository, "Lebanon", 1000, 1000, 0)
+
+	// Test cases
+	if err := repository.InsertIntoUsersCountries(ctx, userId, 1); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict; got %v", err)
+	}
+
+	// Test cases
+	if err := repository.InsertIntoUsersCountries(ctx, userId, 42); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown country; got %v", err)
+	}
+
+	countryId, err := repository.GetCountryIdByName(ctx, "Jordan")
+
+	// Test cases
+	if err != nil || countryId != 2 {
+		t.Errorf("expected country id 2; got %v and %v", countryId, err)
+	}
+
+	_, err = repository.GetCountryIdByName(ctx, "Iraq")
+
+	// Test cases
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	count, err := repository.CountriesCountByname(ctx, "Lebanon")
+
+	// Test cases
+	if err != nil || count != 1 {
+		t.Errorf("expected 1 country; got %v and %v", count, err)
+	}
+
+	countries, err := repository.GetAllCountriesByUserId(ctx, userId)
+
+	// Test cases
+	if err != nil || len(countries) != 4 {
+		t.Errorf("expected 4 countries and nil error; got %v and %v", len(countries), err)
+	}
+
+	allCountries, err := repository.GetAllCountries(ctx)
+
+	// Test cases
+	if err != nil || len(allCountries) != 5 || allCountries[5] != "Lebanon" {
+		t.Errorf("expected 5 countries with Lebanon last; got %v and %v", allCountries, err)
+	}
+
+	topThreeCountries, err := repository.GetTopThreeCountriesByUserIdAndType(ctx, userId, "confirmed")
+
+	// Test cases
+	if err != nil || len(topThreeCountries) != 3 || topThreeCountries[0].Name != "Egypt" || topThreeCountries[2].Name != "Jordan" {
+		t.Errorf("expected Egypt, Syria and Jordan; got %v and %v", topThreeCountries, err)
+	}
+
+	topThreeCountries, err = repository.GetTopThreeCountriesByUserIdAndType(ctx, userId, "death")
+
+	// Test cases
+	if err != nil || len(topThreeCountries) != 3 || topThreeCountries[0].Name != "Palestine" {
+		t.Errorf("expected Palestine first of 3 countries; got %v and %v", topThreeCountries, err)
+	}
+
+	count, err = repository.UsersCountriesCount(ctx, userId, 5)
+
+	// Test cases
+	if err != nil || count != 0 {
+		t.Errorf("expected the user not to be subscribed to Lebanon; got %v and %v", count, err)
+	}
+
+	statistic, err := repository.GetStatisticByCountryId(ctx, 1)
+
+	// Test cases
+	if err != nil || statistic.Confirmed != 100 || statistic.LastUpdated == nil {
+		t.Errorf("expected 100 confirmed cases with their last update time; got %v and %v", statistic, err)
+	}
+
+	_, err = repository.GetStatisticByCountryId(ctx, 42)
+
+	// Test cases
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	statistics, err := repository.GetAllStatistics(ctx)
+
+	// Test cases
+	if err != nil || len(statistics) != 5 {
+		t.Fatalf("expected 5 statistics; got %v and %v", statistics, err)
+	}
+
+	// Test cases
+	if statistics[0].CountryId != 1 || statistics[0].Confirmed != 100 || statistics[0].Deaths != 4 || statistics[0].Recovered != 10 || statistics[0].LastUpdated == nil {
+		t.Errorf("expected 100 confirmed, 4 deaths and 10 recovered cases of Palestine; got %+v", statistics[0])
+	}
+}
+
+func testContractDailyStatistics(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	countryId, err := repository.InsertCountry(ctx, "Palestine")
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	firstDay := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		err := repository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: countryId, Date: firstDay.AddDate(0, 0, day), Confirmed: day})
+		if err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+	err = repository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: countryId, Date: firstDay, Confirmed: 10})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	statistics, err := repository.GetDailyStatisticsByCountryName(ctx, "Palestine", firstDay, firstDay.AddDate(0, 0, 1))
+
+	// Test cases
+	if err != nil || len(statistics) != 2 {
+		t.Fatalf("expected 2 daily statistics; got %v and %v", statistics, err)
+	}
+
+	// Test cases
+	if statistics[0].Confirmed != 10 || !statistics[0].Date.Equal(firstDay) {
+		t.Errorf("expected the upserted 10 confirmed cases on %v; got %v", firstDay, statistics[0])
+	}
+
+	statistic, err := repository.GetDailyStatisticByCountryIdAndDate(ctx, countryId, firstDay.AddDate(0, 0, 2))
+
+	// Test cases
+	if err != nil || statistic.Confirmed != 2 {
+		t.Errorf("expected 2 confirmed cases; got %v and %v", statistic, err)
+	}
+
+	_, err = repository.GetDailyStatisticByCountryIdAndDate(ctx, countryId, firstDay.AddDate(0, 0, 3))
+
+	// Test cases
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+}
+
+func testContractRegions(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	otherUserId := insertTestUser(t, repository, "other@test.com")
+	palestineId := insertTestCountry(t, repository, "Palestine", 100, 10, 50)
+	jordanId := insertTestCountry(t, repository, "Jordan", 200, 20, 100)
+	insertTestCountry(t, repository, "Egypt", 300, 30, 150)
+	// a country seeded in a region without statistics, nobody following it
+	syriaId, err := repository.InsertCountry(ctx, "Syria")
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	regionId, err := repository.InsertRegion(ctx, "Levant", "custom", userId, []int{palestineId, palestineId, syriaId})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if _, err := repository.InsertRegion(ctx, "Levant", "custom", userId, nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict; got %v", err)
+	}
+
+	// Test cases
+	if _, err := repository.InsertRegion(ctx, "Levant", "custom", otherUserId, nil); err != nil {
+		t.Errorf("expected another user to reuse the name; got %v", err)
+	}
+
+	// Test cases
+	if _, err := repository.InsertRegion(ctx, "Maghreb", "custom", userId, []int{jordanId, 99999}); err == nil {
+		t.Errorf("expected foreign key error; got nil")
+	}
+	if _, err := repository.GetRegionByName(ctx, userId, "Maghreb"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the region not to be inserted without its countries; got %v", err)
+	}
+
+	for _, countryId := range []int{jordanId, jordanId} {
+		if err := repository.InsertIntoRegionsCountries(ctx, regionId, countryId); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+
+	region, err := repository.GetRegionByName(ctx, userId, "Levant")
+
+	// Test cases
+	if err != nil || region.ID != regionId || region.UserId != userId {
+		t.Errorf("expected the region of the user; got %v and %v", region, err)
+	}
+
+	region, err = repository.GetRegionByName(ctx, userId, "Asia")
+
+	// Test cases
+	if err != nil || region.Type != "continent" || region.UserId != 0 {
+		t.Errorf("expected the shared Asia continent; got %v and %v", region, err)
+	}
+
+	_, err = repository.GetRegionByName(ctx, userId, "Atlantis")
+
+	// Test cases
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	regions, err := repository.GetRegionsByUserId(ctx, userId)
+
+	// Test cases
+	if err != nil || len(regions) != 13 || regions[0].Name != "Africa" || regions[6].Name != "Levant" {
+		t.Errorf("expected 12 shared regions and Levant sorted by type and name; got %v and %v", regions, err)
+	}
+
+	statistics, err := repository.GetCountriesStatisticsByRegionId(ctx, regionId)
+
+	// Test cases
+	if err != nil || len(statistics) != 3 || statistics[0].Name != "Jordan" || statistics[0].Recovered != 100 || statistics[0].Missing {
+		t.Fatalf("expected Jordan, Palestine and Syria; got %v and %v", statistics, err)
+	}
+
+	// Test cases
+	if statistics[2].Name != "Syria" || !statistics[2].Missing || statistics[2].Confirmed != 0 {
+		t.Errorf("expected Syria without statistics; got %v", statistics[2])
+	}
+
+	statistics, err = repository.GetAllCountriesStatistics(ctx)
+
+	// Test cases
+	if err != nil || len(statistics) != 4 || statistics[0].Name != "Egypt" || statistics[0].Deaths != 30 || !statistics[3].Missing {
+		t.Errorf("expected Egypt, Jordan, Palestine and Syria without statistics; got %v and %v", statistics, err)
+	}
+}
+
+func testContractSubdivisions(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	countryId := insertTestCountry(t, repository, "Canada", 0, 0, 0)
+
+	ontarioId, err := repository.UpsertSubdivision(ctx, countryId, "Ontario")
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	albertaId, err := repository.UpsertSubdivision(ctx, countryId, "Alberta")
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if _, err := repository.UpsertSubdivision(ctx, countryId, "Yukon"); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	subdivisionId, err := repository.UpsertSubdivision(ctx, countryId, "Ontario")
+
+	// Test cases
+	if err != nil || subdivisionId != ontarioId {
+		t.Errorf("expected the existing Ontario id %v; got %v and %v", ontarioId, subdivisionId, err)
+	}
+
+	for _, statistic := range []entity.SubdivisionStatistics{
+		{SubdivisionId: ontarioId, Confirmed: 10, Deaths: 1, Recovered: 5},
+		{SubdivisionId: albertaId, Confirmed: 5, Deaths: 1, Recovered: 1},
+		{SubdivisionId: ontarioId, Confirmed: 20, Deaths: 2, Recovered: 10},
+	} {
+		if err := repository.UpsertSubdivisionStatistic(ctx, statistic); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+
+	subdivisionId, err = repository.GetSubdivisionIdByName(ctx, "Canada", "Alberta")
+
+	// Test cases
+	if err != nil || subdivisionId != albertaId {
+		t.Errorf("expected Alberta id %v; got %v and %v", albertaId, subdivisionId, err)
+	}
+
+	_, err = repository.GetSubdivisionIdByName(ctx, "Canada", "Texas")
+
+	// Test cases
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	if err := repository.InsertIntoUsersSubdivisions(ctx, userId, ontarioId); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if err := repository.InsertIntoUsersSubdivisions(ctx, userId, ontarioId); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict; got %v", err)
+	}
+
+	statistics, err := repository.GetSubdivisionsStatisticsByUserId(ctx, userId)
+
+	// Test cases
+	if err != nil || len(statistics) != 1 || statistics[0].Name != "Ontario" || statistics[0].Country != "Canada" || statistics[0].Confirmed != 20 {
+		t.Errorf("expected the upserted Ontario statistics; got %v and %v", statistics, err)
+	}
+
+	statistics, err = repository.GetSubdivisionsStatisticsByCountryName(ctx, "Canada")
+
+	// Test cases
+	if err != nil || len(statistics) != 2 || statistics[0].Name != "Alberta" {
+		t.Errorf("expected Alberta and Ontario; got %v and %v", statistics, err)
+	}
+
+	count, err := repository.UpdateSubdivisionRollup(ctx, "Canada", true)
+
+	// Test cases
+	if err != nil || count != 1 {
+		t.Errorf("expected 1 updated country; got %v and %v", count, err)
+	}
+
+	countryIds, err := repository.GetCountryIdsWithSubdivisionRollup(ctx)
+
+	// Test cases
+	if err != nil || len(countryIds) != 1 || countryIds[0] != countryId {
+		t.Errorf("expected Canada to roll up its subdivisions; got %v and %v", countryIds, err)
+	}
+
+	count, err = repository.UpdateSubdivisionRollup(ctx, "Atlantis", true)
+
+	// Test cases
+	if err != nil || count != 0 {
+		t.Errorf("expected 0 updated countries; got %v and %v", count, err)
+	}
+}
+
+func testContractAlerts(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	otherUserId := insertTestUser(t, repository, "other@test.com")
+	countryId := insertTestCountry(t, repository, "Palestine", 0, 0, 0)
+	rule := entity.AlertRule{UserId: userId, CountryId: countryId, Metric: "NEW_CASES", Operator: "ABOVE", Threshold: 100}
+
+	ruleId, err := repository.InsertAlertRule(ctx, rule)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if _, err := repository.InsertAlertRule(ctx, rule); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict; got %v", err)
+	}
+
+	// Test cases
+	if _, err := repository.InsertAlertRule(ctx, entity.AlertRule{UserId: userId, CountryId: 999, Metric: "NEW_CASES", Operator: "ABOVE"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	if err := repository.UpdateAlertRuleTriggered(ctx, ruleId, true); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	rules, err := repository.GetAllAlertRules(ctx)
+
+	// Test cases
+	if err != nil || len(rules) != 1 || rules[0].Country != "Palestine" || !rules[0].Triggered || rules[0].Threshold != 100 {
+		t.Errorf("expected the triggered rule of Palestine; got %v and %v", rules, err)
+	}
+
+	rules, err = repository.GetAlertRulesByUserId(ctx, otherUserId)
+
+	// Test cases
+	if err != nil || len(rules) != 0 {
+		t.Errorf("expected no rules for the other user; got %v and %v", rules, err)
+	}
+
+	triggeredAt := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	firstId, err := repository.InsertAlert(ctx, entity.Alert{RuleId: ruleId, Value: 150, TriggeredAt: triggeredAt})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	secondId, err := repository.InsertAlert(ctx, entity.Alert{RuleId: ruleId, Value: 200, TriggeredAt: triggeredAt.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	count, err := repository.AcknowledgeAlert(ctx, otherUserId, firstId, triggeredAt)
+
+	// Test cases
+	if err != nil || count != 0 {
+		t.Errorf("expected the alert of another user not to be acknowledged; got %v and %v", count, err)
+	}
+
+	count, err = repository.AcknowledgeAlert(ctx, userId, firstId, triggeredAt.AddDate(0, 0, 2))
+
+	// Test cases
+	if err != nil || count != 1 {
+		t.Errorf("expected 1 acknowledged alert; got %v and %v", count, err)
+	}
+
+	alerts, err := repository.GetAlertsByUserId(ctx, userId, false)
+
+	// Test cases
+	if err != nil || len(alerts) != 2 || alerts[0].ID != secondId || alerts[0].Country != "Palestine" || alerts[0].Value != 200 || alerts[0].AcknowledgedAt != nil {
+		t.Errorf("expected the latest alert first; got %v and %v", alerts, err)
+	}
+
+	// Test cases
+	if err == nil && len(alerts) == 2 && (alerts[1].AcknowledgedAt == nil || !alerts[1].TriggeredAt.Equal(triggeredAt)) {
+		t.Errorf("expected the first alert to be acknowledged; got %+v", alerts[1])
+	}
+
+	alerts, err = repository.GetAlertsByUserId(ctx, userId, true)
+
+	// Test cases
+	if err != nil || len(alerts) != 1 || alerts[0].ID != secondId {
+		t.Errorf("expected the unacknowledged alert only; got %v and %v", alerts, err)
+	}
+
+	count, err = repository.DeleteAlertRule(ctx, otherUserId, ruleId)
+
+	// Test cases
+	if err != nil || count != 0 {
+		t.Errorf("expected the rule of another user not to be deleted; got %v and %v", count, err)
+	}
+
+	count, err = repository.DeleteAlertRule(ctx, userId, ruleId)
+	alerts, _ = repository.GetAlertsByUserId(ctx, userId, false)
+
+	// Test cases
+	if err != nil || count != 1 || len(alerts) != 0 {
+		t.Errorf("expected the rule and its alerts to be deleted; got %v, %v and %v", count, alerts, err)
+	}
+}
+
+func testContractWebhooks(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	otherUserId := insertTestUser(t, repository, "other@test.com")
+	createdAt := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	webhook := entity.Webhook{UserId: userId, URL: "https://example.com/hook", Secret: "secret", Events: []string{"threshold-alert"}, CreatedAt: createdAt}
+
+	webhookId, err := repository.InsertWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if _, err := repository.InsertWebhook(ctx, entity.Webhook{UserId: 999, URL: "https://example.com", CreatedAt: createdAt}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	webhooks, err := repository.GetAllWebhooks(ctx)
+
+	// Test cases
+	if err != nil || len(webhooks) != 1 || webhooks[0].URL != webhook.URL || webhooks[0].Secret != "secret" ||
+		!reflect.DeepEqual(webhooks[0].Events, []string{"threshold-alert"}) || len(webhooks[0].Countries) != 0 || !webhooks[0].CreatedAt.Equal(createdAt) {
+		t.Errorf("expected the webhook; got %+v and %v", webhooks, err)
+	}
+
+	webhooks, err = repository.GetWebhooksByUserId(ctx, otherUserId)
+
+	// Test cases
+	if err != nil || len(webhooks) != 0 {
+		t.Errorf("expected no webhooks for the other user; got %v and %v", webhooks, err)
+	}
+
+	firstId, err := repository.InsertWebhookDelivery(ctx, entity.WebhookDelivery{WebhookId: webhookId, Event: "threshold-alert", Payload: "{}", Status: "PENDING", NextAttemptAt: &createdAt, CreatedAt: createdAt})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	later := createdAt.Add(time.Hour)
+	secondId, err := repository.InsertWebhookDelivery(ctx, entity.WebhookDelivery{WebhookId: webhookId, Event: "threshold-alert", Payload: "{}", Status: "PENDING", NextAttemptAt: &later, CreatedAt: createdAt})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	due, err := repository.GetDueWebhookDeliveries(ctx, createdAt, 10)
+
+	// Test cases
+	if err != nil || len(due) != 1 || due[0].ID != firstId || due[0].URL != webhook.URL || due[0].Secret != "secret" || due[0].Attempts != 0 {
+		t.Errorf("expected the first delivery to be due along with its webhook; got %+v and %v", due, err)
+	}
+
+	status, message := 500, "webhook answered 500 Internal Server Error"
+	if err := repository.UpdateWebhookDelivery(ctx, entity.WebhookDelivery{ID: firstId, Status: "PENDING", Attempts: 1, ResponseStatus: &status, Error: &message, NextAttemptAt: &later}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if err := repository.UpdateWebhookDelivery(ctx, entity.WebhookDelivery{ID: secondId, Status: "SUCCEEDED", Attempts: 1, DeliveredAt: &later}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	due, err = repository.GetDueWebhookDeliveries(ctx, later, 10)
+
+	// Test cases
+	if err != nil || len(due) != 1 || due[0].ID != firstId || due[0].Attempts != 1 || due[0].ResponseStatus == nil || *due[0].ResponseStatus != 500 || due[0].Error == nil || *due[0].Error != message {
+		t.Errorf("expected the failed delivery to be due again; got %+v and %v", due, err)
+	}
+
+	deliveries, err := repository.GetWebhookDeliveries(ctx, userId, webhookId, 10)
+
+	// Test cases
+	if err != nil || len(deliveries) != 2 || deliveries[0].ID != secondId || deliveries[0].Status != "SUCCEEDED" || deliveries[0].DeliveredAt == nil || deliveries[0].NextAttemptAt != nil {
+		t.Errorf("expected the latest delivery first; got %+v and %v", deliveries, err)
+	}
+
+	deliveries, err = repository.GetWebhookDeliveries(ctx, otherUserId, webhookId, 10)
+
+	// Test cases
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("expected no deliveries for the other user; got %v and %v", deliveries, err)
+	}
+
+	delivery, err := repository.GetWebhookDeliveryById(ctx, userId, firstId)
+
+	// Test cases
+	if err != nil || delivery.ID != firstId || delivery.Payload != "{}" {
+		t.Errorf("expected the first delivery; got %+v and %v", delivery, err)
+	}
+
+	// Test cases
+	if _, err := repository.GetWebhookDeliveryById(ctx, otherUserId, firstId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the other user; got %v", err)
+	}
+
+	count, err := repository.DeleteWebhook(ctx, otherUserId, webhookId)
+
+	// Test cases
+	if err != nil || count != 0 {
+		t.Errorf("expected the webhook of another user not to be deleted; got %v and %v", count, err)
+	}
+
+	count, err = repository.DeleteWebhook(ctx, userId, webhookId)
+	due, _ = repository.GetDueWebhookDeliveries(ctx, later, 10)
+
+	// Test cases
+	if err != nil || count != 1 || len(due) != 0 {
+		t.Errorf("expected the webhook and its deliveries to be deleted; got %v, %v and %v", count, due, err)
+	}
+}
+
+func testContractDigestPreferences(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	otherUserId := insertTestUser(t, repository, "other@test.com")
+
+	if err := repository.UpsertDigestPreference(ctx, entity.DigestPreference{UserId: userId, Channel: "EMAIL", Frequency: "DAILY"}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if err := repository.UpsertDigestPreference(ctx, entity.DigestPreference{UserId: userId, Channel: "CHAT", Destination: "https://hooks.example.com/1", Frequency: "WEEKLY"}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if err := repository.UpsertDigestPreference(ctx, entity.DigestPreference{UserId: 999, Channel: "EMAIL", Frequency: "DAILY"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	sentAt := time.Date(2023, 5, 1, 7, 0, 0, 0, time.UTC)
+	if err := repository.UpdateDigestSentAt(ctx, userId, "EMAIL", sentAt); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if err := repository.UpsertDigestPreference(ctx, entity.DigestPreference{UserId: userId, Channel: "EMAIL", Frequency: "OFF"}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	preferences, err := repository.GetDigestPreferencesByUserId(ctx, userId)
+
+	// Test cases
+	if err != nil || len(preferences) != 2 || preferences[0].Channel != "CHAT" || preferences[0].LastSentAt != nil || preferences[0].Destination != "https://hooks.example.com/1" {
+		t.Fatalf("expected the chat preference first; got %+v and %v", preferences, err)
+	}
+
+	// Test cases
+	if email := preferences[1]; email.Destination != "test@test.com" || email.Frequency != "OFF" || email.LastSentAt == nil || !email.LastSentAt.Equal(sentAt) {
+		t.Errorf("expected the email preference turned off to keep the time it was sent at; got %+v", email)
+	}
+
+	preferences, err = repository.GetDigestPreferencesByUserId(ctx, otherUserId)
+
+	// Test cases
+	if err != nil || len(preferences) != 0 {
+		t.Errorf("expected no preferences for the other user; got %v and %v", preferences, err)
+	}
+
+	preferences, err = repository.GetAllDigestPreferences(ctx)
+
+	// Test cases
+	if err != nil || len(preferences) != 2 {
+		t.Errorf("expected every preference; got %v and %v", preferences, err)
+	}
+}
+
+func testContractConcurrency(t *testing.T, repository SQLRepositoryInterface) {
+	// prapare data
+	ctx := context.Background()
+	userId := insertTestUser(t, repository, "test@test.com")
+	countryId := insertTestCountry(t, repository, "Palestine", 0, 0, 0)
+
+	var wg sync.WaitGroup
+	failures := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			repository.UpdateArrayOfStatistics(ctx, []entity.Statistics{{CountryId: countryId, Confirmed: i}})
+		}(i)
+		go func() {
+			defer wg.Done()
+			err := repository.InsertIntoUsersCountries(ctx, userId, countryId)
+			if errors.Is(err, ErrConflict) {
+				failures <- err
+			} else if err != nil {
+				t.Errorf("expected nil or ErrConflict; got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(failures)
+
+	// Test cases
+	if len(failures) != 9 {
+		t.Errorf("expected exactly one subscription to succeed; got %v failures", len(failures))
+	}
+
+	count, err := repository.UsersCountriesCount(ctx, userId, countryId)
+
+	// Test cases
+	if err != nil || count != 1 {
+		t.Errorf("expected 1 subscription; got %v and %v", count, err)
+	}
+}
+
+func TestSQLiteQueryCancellation(t *testing.T) {
+	// prapare data
+	sqlRepository := newTestRepository(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := sqlRepository.UsersCountById(ctx, 1)
+
+	// Test cases
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled; got %v", err)
+	}
+}
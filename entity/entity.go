@@ -8,12 +8,22 @@ type RegisterResponseSuccess struct {
 
 type UserResponseFailure struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
 }
 
 type LoginResponseSuccess struct {
 	Token string `json:"token"`
 }
 
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
 type AddCountryRequest struct {
 	Name string `json:"name"`
 }
@@ -28,7 +38,7 @@ type CountryName struct {
 }
 
 type Percentage struct {
-	Value string `json:"value"`
+	Value float64 `json:"value"`
 }
 
 type Statistics struct {
@@ -40,7 +50,135 @@ type Statistics struct {
 }
 
 type CovidData struct {
-	Confirmed int `json:"Confirmed"`
-	Deaths    int `json:"Deaths"`
-	Recovered int `json:"Recovered"`
+	Province  string `json:"Province"`
+	Confirmed int    `json:"Confirmed"`
+	Deaths    int    `json:"Deaths"`
+	Recovered int    `json:"Recovered"`
+}
+
+type DailyStatistic struct {
+	CountryId int       `json:"country_id"`
+	Date      time.Time `json:"date"`
+	Confirmed int       `json:"confirmed"`
+	Deaths    int       `json:"death"`
+	Recovered int       `json:"recovered"`
+}
+
+type Region struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	UserId int    `json:"user_id"`
+}
+
+type CountryStatistics struct {
+	Name      string `json:"name"`
+	Confirmed int    `json:"confirmed"`
+	Deaths    int    `json:"death"`
+	Recovered int    `json:"recovered"`
+	// Missing tells the country has no statistics yet, nobody following it.
+	Missing bool `json:"-"`
+}
+
+type CreateRegionRequest struct {
+	Name      string   `json:"name"`
+	Countries []string `json:"countries"`
+}
+
+type SubdivisionStatistics struct {
+	SubdivisionId int    `json:"subdivision_id"`
+	Country       string `json:"country"`
+	Name          string `json:"name"`
+	Confirmed     int    `json:"confirmed"`
+	Deaths        int    `json:"death"`
+	Recovered     int    `json:"recovered"`
+}
+
+type AddSubdivisionRequest struct {
+	Country string `json:"country"`
+	Name    string `json:"name"`
+}
+
+type SubdivisionRollupRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type AlertRule struct {
+	ID        int     `json:"id"`
+	UserId    int     `json:"user_id"`
+	CountryId int     `json:"country_id"`
+	Country   string  `json:"country"`
+	Metric    string  `json:"metric"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	Triggered bool    `json:"triggered"`
+}
+
+type Alert struct {
+	ID             int        `json:"id"`
+	RuleId         int        `json:"rule_id"`
+	UserId         int        `json:"user_id"`
+	Country        string     `json:"country"`
+	Metric         string     `json:"metric"`
+	Operator       string     `json:"operator"`
+	Threshold      float64    `json:"threshold"`
+	Value          float64    `json:"value"`
+	TriggeredAt    time.Time  `json:"triggered_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+}
+
+type CreateAlertRuleRequest struct {
+	Country   string  `json:"country"`
+	Metric    string  `json:"metric"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+}
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	UserId    int       `json:"user_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	Countries []string  `json:"countries"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID        int `json:"id"`
+	WebhookId int `json:"webhook_id"`
+	// URL and Secret are the ones of the webhook, set by
+	// GetDueWebhookDeliveries.
+	URL            string     `json:"url"`
+	Secret         string     `json:"secret"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus *int       `json:"response_status"`
+	Error          *string    `json:"error"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Countries []string `json:"countries"`
+}
+
+type DigestPreference struct {
+	UserId  int    `json:"user_id"`
+	Channel string `json:"channel"`
+	// Destination is the email address or the URL the digests are sent to,
+	// the email address of the user when stored empty.
+	Destination string     `json:"destination"`
+	Frequency   string     `json:"frequency"`
+	LastSentAt  *time.Time `json:"last_sent_at"`
+}
+
+type DigestPreferenceRequest struct {
+	Destination string `json:"destination"`
+	Frequency   string `json:"frequency"`
 }
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Types of the published events.
+const (
+	TypeStatisticsChanged = "statistics-changed"
+	TypeThresholdAlert    = "threshold-alert"
+)
+
+// Event is a published event, its ID grows with each event of the bus.
+type Event struct {
+	ID      uint64
+	Type    string
+	Country string
+	// UserID restricts the event to a single user, 0 means every user.
+	UserID int
+	// Data is the payload matching the type, such as StatisticsUpdated.
+	Data any
+}
+
+// StatisticsUpdated is published once the refresher stored the new totals of
+// a country.
+type StatisticsUpdated struct {
+	Country   string    `json:"country"`
+	Confirmed int       `json:"confirmed"`
+	Deaths    int       `json:"deaths"`
+	Recovered int       `json:"recovered"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ThresholdAlert is published when the condition of an alert rule starts to
+// hold.
+type ThresholdAlert struct {
+	AlertID     int       `json:"alertId"`
+	RuleID      int       `json:"ruleId"`
+	Country     string    `json:"country"`
+	Metric      string    `json:"metric"`
+	Operator    string    `json:"operator"`
+	Threshold   float64   `json:"threshold"`
+	Value       float64   `json:"value"`
+	TriggeredAt time.Time `json:"triggeredAt"`
+}
+
+// DefaultBuffer is the number of events a subscriber may fall behind by
+// before being dropped.
+const DefaultBuffer = 256
+
+// DefaultReplay is the number of past events kept for the subscribers
+// resuming after a disconnection.
+const DefaultReplay = 1024
+
+// Bus hands the published events to every subscriber. Publishing never
+// blocks: a subscriber whose buffer is full is dropped, its channel being
+// closed, and may resume from the last event it got. A nil *Bus drops the
+// events and never hands any.
+type Bus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	replay      []Event
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus returns a bus keeping the last replay events.
+func NewBus(replay int) *Bus {
+	return &Bus{
+		replay:      make([]Event, 0, replay),
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns the channel of the events published from now on, it is
+// closed once ctx is done or the subscriber is dropped.
+func (b *Bus) Subscribe(ctx context.Context, buffer int) <-chan Event {
+	var lastID uint64
+	if b != nil {
+		b.mu.Lock()
+		lastID = b.lastID
+		b.mu.Unlock()
+	}
+	return b.Resume(ctx, buffer, lastID)
+}
+
+// Resume is Subscribe, the channel first holding the kept events published
+// after lastID. The events too old to be kept are lost.
+func (b *Bus) Resume(ctx context.Context, buffer int, lastID uint64) <-chan Event {
+	if b == nil {
+		events := make(chan Event)
+		go func() {
+			<-ctx.Done()
+			close(events)
+		}()
+		return events
+	}
+
+	b.mu.Lock()
+	var missed []Event
+	for _, event := range b.replay {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+	events := make(chan Event, len(missed)+buffer)
+	for _, event := range missed {
+		events <- event
+	}
+	b.subscribers[events] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		b.drop(events)
+		b.mu.Unlock()
+	}()
+	return events
+}
+
+// Publish numbers the event and hands it to the subscribers, it returns how
+// many were dropped for being too slow.
+func (b *Bus) Publish(event Event) int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if len(b.replay) < cap(b.replay) {
+		b.replay = append(b.replay, event)
+	} else if len(b.replay) > 0 {
+		copy(b.replay, b.replay[1:])
+		b.replay[len(b.replay)-1] = event
+	}
+
+	dropped := 0
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			b.drop(subscriber)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// drop closes the channel of the subscriber unless it already was, b.mu
+// being held.
+func (b *Bus) drop(subscriber chan Event) {
+	if _, ok := b.subscribers[subscriber]; ok {
+		delete(b.subscribers, subscriber)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,92 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+)
+
+func TestBus(t *testing.T) {
+	// prapare data
+	bus := events.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	first := bus.Subscribe(ctx, 1)
+	second := bus.Subscribe(context.Background(), 1)
+
+	dropped := bus.Publish(events.Event{Type: events.TypeStatisticsChanged, Country: "Palestine"})
+
+	// Test cases
+	if event := <-first; dropped != 0 || event.Country != "Palestine" || event.ID != 1 || (<-second).ID != 1 {
+		t.Errorf("expected both subscribers to get the first event; got %+v and %d dropped", event, dropped)
+	}
+
+	cancel()
+	_, open := <-first
+
+	// Test cases
+	if open {
+		t.Errorf("expected the channel to be closed once the context is done")
+	}
+}
+
+func TestBusDropsSlowSubscribers(t *testing.T) {
+	// prapare data
+	bus := events.NewBus(10)
+	slow := bus.Subscribe(context.Background(), 1)
+
+	bus.Publish(events.Event{Country: "Jordan"})
+	dropped := bus.Publish(events.Event{Country: "Egypt"})
+
+	// Test cases
+	if event, open := <-slow; dropped != 1 || !open || event.Country != "Jordan" {
+		t.Errorf("expected the buffered event then the subscriber dropped; got %+v and %d dropped", event, dropped)
+	}
+
+	// Test cases
+	if _, open := <-slow; open {
+		t.Errorf("expected the channel of the dropped subscriber to be closed")
+	}
+}
+
+func TestBusResume(t *testing.T) {
+	// prapare data
+	bus := events.NewBus(2)
+	for _, country := range []string{"Palestine", "Jordan", "Egypt"} {
+		bus.Publish(events.Event{Country: country})
+	}
+
+	resumed := bus.Resume(context.Background(), 1, 1)
+	bus.Publish(events.Event{Country: "Syria"})
+
+	// Test cases
+	for _, want := range []uint64{2, 3, 4} {
+		if event := <-resumed; event.ID != want {
+			t.Errorf("expected event %d; got %+v", want, event)
+		}
+	}
+
+	// Test cases
+	if event := <-bus.Resume(context.Background(), 1, 0); event.ID != 3 {
+		t.Errorf("expected the events older than the replay to be lost; got %+v", event)
+	}
+}
+
+func TestNilBus(t *testing.T) {
+	// prapare data
+	var bus *events.Bus
+	ctx, cancel := context.WithCancel(context.Background())
+	subscriber := bus.Subscribe(ctx, 1)
+
+	// Test cases
+	if dropped := bus.Publish(events.Event{Country: "Palestine"}); dropped != 0 {
+		t.Errorf("expected the nil bus to drop the event; got %d dropped", dropped)
+	}
+
+	cancel()
+
+	// Test cases
+	if _, open := <-subscriber; open {
+		t.Errorf("expected the channel to be closed once the context is done")
+	}
+}
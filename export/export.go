@@ -0,0 +1,211 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Formats of the exports.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// DateLayout is the format of the dates in the CSV and NDJSON exports.
+const DateLayout = "2006-01-02"
+
+// Columns are the columns of every export, in order, whatever its format.
+var Columns = []string{"country", "date", "confirmed", "deaths", "recovered", "new_confirmed", "new_deaths"}
+
+// Row is a line of an export, its nil fields being empty in CSV and null in
+// NDJSON and Parquet.
+type Row struct {
+	Country   string
+	Date      *time.Time
+	Confirmed int
+	Deaths    int
+	Recovered int
+	// NewConfirmed and NewDeaths are the increases since the previous day.
+	NewConfirmed *int
+	NewDeaths    *int
+}
+
+// Writer encodes the rows of an export as they come, Close ending it.
+type Writer interface {
+	Write(row Row) error
+	Close() error
+}
+
+// Config tells how the exports are written and linked to.
+type Config struct {
+	// BaseURL is where the clients reach the API, the export links point to
+	// its /export route.
+	BaseURL string
+	// LinkExpiry is how long an export link can be downloaded.
+	LinkExpiry time.Duration
+	// RowGroupSize is the number of rows a Parquet row group holds, the rows
+	// of a group being buffered until it's written.
+	RowGroupSize int
+}
+
+// Validate checks the settings.
+func (c Config) Validate() error {
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return fmt.Errorf("invalid EXPORT_BASE_URL %s, expected an http or https URL", c.BaseURL)
+	}
+	if c.LinkExpiry <= 0 {
+		return fmt.Errorf("invalid EXPORT_LINK_EXPIRY %s, expected a positive duration", c.LinkExpiry)
+	}
+	if c.RowGroupSize <= 0 {
+		return fmt.Errorf("invalid EXPORT_ROW_GROUP_SIZE %d, expected a positive number", c.RowGroupSize)
+	}
+	return nil
+}
+
+// ContentType returns the media type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/vnd.apache.parquet"
+}
+
+// NewWriter returns a writer of the format to w. The CSV and NDJSON rows are
+// written as they come, the Parquet ones by row groups of rowGroupSize.
+func NewWriter(w io.Writer, format string, rowGroupSize int) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := &csvWriter{writer: csv.NewWriter(w)}
+		return writer, writer.writer.Write(Columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter{writer: parquet.NewGenericWriter[parquetRow](w, parquet.MaxRowsPerRowGroup(int64(rowGroupSize)))}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %s", format)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvWriter) Write(row Row) error {
+	date := ""
+	if row.Date != nil {
+		date = row.Date.UTC().Format(DateLayout)
+	}
+	return c.writer.Write([]string{
+		row.Country,
+		date,
+		strconv.Itoa(row.Confirmed),
+		strconv.Itoa(row.Deaths),
+		strconv.Itoa(row.Recovered),
+		formatInt(row.NewConfirmed),
+		formatInt(row.NewDeaths),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// ndjsonRow is a Row named after Columns.
+type ndjsonRow struct {
+	Country      string  `json:"country"`
+	Date         *string `json:"date"`
+	Confirmed    int     `json:"confirmed"`
+	Deaths       int     `json:"deaths"`
+	Recovered    int     `json:"recovered"`
+	NewConfirmed *int    `json:"new_confirmed"`
+	NewDeaths    *int    `json:"new_deaths"`
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(row Row) error {
+	var date *string
+	if row.Date != nil {
+		formatted := row.Date.UTC().Format(DateLayout)
+		date = &formatted
+	}
+	return n.encoder.Encode(ndjsonRow{
+		Country:      row.Country,
+		Date:         date,
+		Confirmed:    row.Confirmed,
+		Deaths:       row.Deaths,
+		Recovered:    row.Recovered,
+		NewConfirmed: row.NewConfirmed,
+		NewDeaths:    row.NewDeaths,
+	})
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// parquetRow is a Row named after Columns. Its date is the number of days
+// since the Unix epoch, 0 being null.
+type parquetRow struct {
+	Country      string `parquet:"country"`
+	Date         int32  `parquet:"date,date,optional"`
+	Confirmed    int64  `parquet:"confirmed"`
+	Deaths       int64  `parquet:"deaths"`
+	Recovered    int64  `parquet:"recovered"`
+	NewConfirmed *int64 `parquet:"new_confirmed,optional"`
+	NewDeaths    *int64 `parquet:"new_deaths,optional"`
+}
+
+type parquetWriter struct {
+	writer *parquet.GenericWriter[parquetRow]
+}
+
+func (p *parquetWriter) Write(row Row) error {
+	var date int32
+	if row.Date != nil {
+		date = int32(row.Date.Unix() / (24 * 60 * 60))
+	}
+	_, err := p.writer.Write([]parquetRow{{
+		Country:      row.Country,
+		Date:         date,
+		Confirmed:    int64(row.Confirmed),
+		Deaths:       int64(row.Deaths),
+		Recovered:    int64(row.Recovered),
+		NewConfirmed: int64Ptr(row.NewConfirmed),
+		NewDeaths:    int64Ptr(row.NewDeaths),
+	}})
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	return p.writer.Close()
+}
+
+func formatInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func int64Ptr(value *int) *int64 {
+	if value == nil {
+		return nil
+	}
+	converted := int64(*value)
+	return &converted
+}
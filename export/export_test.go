@@ -0,0 +1,144 @@
+package export_test
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/export"
+	"github.com/parquet-go/parquet-go"
+)
+
+func testRows() []export.Row {
+	date := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	newConfirmed, newDeaths := 100, 2
+	return []export.Row{
+		{Country: "Palestine", Date: &date, Confirmed: 1200, Deaths: 12, Recovered: 0, NewConfirmed: &newConfirmed, NewDeaths: &newDeaths},
+		{Country: "Jordan", Confirmed: 550, Deaths: 5, Recovered: 3},
+	}
+}
+
+func writeRows(t *testing.T, format string, rowGroupSize int) []byte {
+	var output bytes.Buffer
+	writer, err := export.NewWriter(&output, format, rowGroupSize)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	for _, row := range testRows() {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	return output.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	// prapare data
+	output := string(writeRows(t, export.FormatCSV, 1))
+
+	// Test cases
+	want := "country,date,confirmed,deaths,recovered,new_confirmed,new_deaths\n" +
+		"Palestine,2023-05-01,1200,12,0,100,2\n" +
+		"Jordan,,550,5,3,,\n"
+	if output != want {
+		t.Errorf("expected %q; got %q", want, output)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	// prapare data
+	output := string(writeRows(t, export.FormatNDJSON, 1))
+
+	// Test cases
+	want := `{"country":"Palestine","date":"2023-05-01","confirmed":1200,"deaths":12,"recovered":0,"new_confirmed":100,"new_deaths":2}` + "\n" +
+		`{"country":"Jordan","date":null,"confirmed":550,"deaths":5,"recovered":3,"new_confirmed":null,"new_deaths":null}` + "\n"
+	if output != want {
+		t.Errorf("expected %q; got %q", want, output)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	// prapare data
+	output := writeRows(t, export.FormatParquet, 1)
+
+	file, err := parquet.OpenFile(bytes.NewReader(output), int64(len(output)))
+
+	// Test cases
+	if err != nil || file.NumRows() != 2 || len(file.RowGroups()) != 2 {
+		t.Fatalf("expected 2 rows in 2 row groups; got %v", err)
+	}
+
+	// Test cases
+	var columns []string
+	for _, field := range file.Schema().Fields() {
+		columns = append(columns, field.Name())
+	}
+	if strings.Join(columns, ",") != strings.Join(export.Columns, ",") {
+		t.Errorf("expected the columns %v; got %v", export.Columns, columns)
+	}
+
+	// Test cases
+	if date := file.Schema().Fields()[1]; !date.Optional() || date.Type().LogicalType().Date == nil {
+		t.Errorf("expected an optional date column; got %v", date.Type())
+	}
+
+	rows := make([]parquet.Row, 2)
+	count, _ := file.RowGroups()[1].Rows().ReadRows(rows)
+
+	// Test cases
+	if count != 1 || rows[0][0].String() != "Jordan" || !rows[0][1].IsNull() || rows[0][2].Int64() != 550 || !rows[0][5].IsNull() {
+		t.Errorf("expected the Jordan row with null date and increases; got %v", rows[0])
+	}
+}
+
+func TestNegativeNewWriter(t *testing.T) {
+	// Test cases
+	if _, err := export.NewWriter(&bytes.Buffer{}, "xlsx", 1); err == nil {
+		t.Errorf("expected unknown export format error; got nil")
+	}
+}
+
+func TestToken(t *testing.T) {
+	// prapare data
+	query := url.Values{"format": {"csv"}, "countries": {"Palestine,Jordan"}}
+	token, err := export.NewToken("secret", 7, query, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	signed := url.Values{"countries": {"Palestine,Jordan"}, "format": {"csv"}, export.TokenParameter: {token}}
+
+	userId, err := export.ParseToken("secret", token, signed)
+
+	// Test cases
+	if err != nil || userId != 7 {
+		t.Errorf("expected user 7; got %d and %v", userId, err)
+	}
+
+	// Test cases
+	for name, test := range map[string]struct {
+		secret string
+		token  string
+		query  url.Values
+	}{
+		"other query":  {"secret", token, url.Values{"format": {"parquet"}, "countries": {"Palestine,Jordan"}}},
+		"other secret": {"other", token, query},
+		"expired":      {"secret", mustToken(t, query, time.Now().Add(-time.Minute)), query},
+	} {
+		if _, err := export.ParseToken(test.secret, test.token, test.query); err == nil {
+			t.Errorf("%s: expected invalid export link error; got nil", name)
+		}
+	}
+}
+
+func mustToken(t *testing.T, query url.Values, expiresAt time.Time) string {
+	token, err := export.NewToken("secret", 7, query, expiresAt)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	return token
+}
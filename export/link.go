@@ -0,0 +1,66 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// TokenParameter is the query parameter of the export links holding their
+// token.
+const TokenParameter = "token"
+
+// NewToken returns the token of an export link of the user, valid for the
+// query until expiresAt. It's signed with a key derived from secret, so that
+// it can't be used as an authorization token, nor the other way around.
+func NewToken(secret string, userId int, query url.Values, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userId,
+		"query":   canonicalQuery(query),
+		"exp":     expiresAt.Unix(),
+	})
+	return token.SignedString(tokenKey(secret))
+}
+
+// ParseToken returns the user of the export link token, which must have
+// been made for the query.
+func ParseToken(secret, tokenString string, query url.Values) (int, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return tokenKey(secret), nil
+	})
+	if err != nil {
+		return 0, errors.New("Invalid or expired export link")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["query"] != canonicalQuery(query) {
+		return 0, errors.New("Invalid or expired export link")
+	}
+	userID, err := strconv.Atoi(fmt.Sprintf("%.0f", claims["user_id"]))
+	if err != nil {
+		return 0, errors.New("Invalid user ID in export link")
+	}
+	return userID, nil
+}
+
+// canonicalQuery encodes the query without its token, sorted by key.
+func canonicalQuery(query url.Values) string {
+	values := make(url.Values, len(query))
+	for key, value := range query {
+		if key != TokenParameter {
+			values[key] = value
+		}
+	}
+	return values.Encode()
+}
+
+func tokenKey(secret string) []byte {
+	return []byte("export:" + secret)
+}
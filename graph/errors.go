@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Codes set in the extensions.code field of the GraphQL errors.
+const (
+	ErrorCodeValidation          = "VALIDATION"
+	ErrorCodeNotFound            = "NOT_FOUND"
+	ErrorCodeConflict            = "CONFLICT"
+	ErrorCodeUnauthenticated     = "UNAUTHENTICATED"
+	ErrorCodeForbidden           = "FORBIDDEN"
+	ErrorCodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	ErrorCodeRateLimited         = "RATE_LIMITED"
+	ErrorCodeInternal            = "INTERNAL"
+)
+
+// InternalErrorMessage replaces the message of the errors the clients
+// shouldn't see, such as the database ones.
+const InternalErrorMessage = "internal server error"
+
+// ErrorPresenter tells the clients what kind of service error happened
+// through extensions.code. The errors of the GraphQL layer, such as a query
+// that doesn't parse or an invalid argument, keep their message and are
+// VALIDATION errors unless gqlgen already gave them a code; any other error
+// is hidden behind an INTERNAL one, the services log it beforehand.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	code := errorCode(err)
+	var gqlErr *gqlerror.Error
+	if code == "" && !errors.As(err, &gqlErr) {
+		return &gqlerror.Error{
+			Message:    InternalErrorMessage,
+			Path:       graphql.GetPath(ctx),
+			Extensions: map[string]interface{}{"code": ErrorCodeInternal},
+		}
+	}
+
+	gqlErr = graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = make(map[string]interface{})
+	}
+	if code != "" {
+		gqlErr.Extensions["code"] = code
+	} else if _, ok := gqlErr.Extensions["code"]; !ok {
+		gqlErr.Extensions["code"] = ErrorCodeValidation
+	}
+	return gqlErr
+}
+
+// Recover returns the function logging the panic of a resolver along with
+// its stack and turning it into an INTERNAL error.
+func Recover(log logger.Logger) graphql.RecoverFunc {
+	return func(ctx context.Context, err interface{}) error {
+		log.Error(ctx, "resolver panicked", "panic", err, "stack", string(debug.Stack()))
+		return fmt.Errorf("panic: %v", err)
+	}
+}
+
+func errorCode(err error) string {
+	var validationError *services.ValidationError
+	var notFoundError *services.NotFoundError
+	var conflictError *services.ConflictError
+	var unauthenticatedError *services.UnauthenticatedError
+	var forbiddenError *services.ForbiddenError
+	var upstreamError *services.UpstreamError
+	switch {
+	case errors.As(err, &validationError):
+		return ErrorCodeValidation
+	case errors.As(err, &notFoundError):
+		return ErrorCodeNotFound
+	case errors.As(err, &conflictError):
+		return ErrorCodeConflict
+	case errors.As(err, &unauthenticatedError):
+		return ErrorCodeUnauthenticated
+	case errors.As(err, &forbiddenError):
+		return ErrorCodeForbidden
+	case errors.As(err, &upstreamError):
+		return ErrorCodeUpstreamUnavailable
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestErrorPresenter(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	cases := []struct {
+		err     error
+		message string
+		code    string
+	}{
+		{&services.ValidationError{Message: "invalid date"}, "invalid date", graph.ErrorCodeValidation},
+		{&services.NotFoundError{Resource: "country", Name: "Atlantis"}, "country Atlantis not found", graph.ErrorCodeNotFound},
+		{fmt.Errorf("adding: %w", &services.ConflictError{Message: "already subscribed"}), "adding: already subscribed", graph.ErrorCodeConflict},
+		{&services.UnauthenticatedError{Message: "invalid email or password"}, "invalid email or password", graph.ErrorCodeUnauthenticated},
+		{&services.ForbiddenError{Message: "not subscribed"}, "not subscribed", graph.ErrorCodeForbidden},
+		{&services.UpstreamError{Err: errors.New("timeout")}, "COVID-19 API unavailable: timeout", graph.ErrorCodeUpstreamUnavailable},
+		{gqlerror.Errorf("RECOVERED is not a valid RatioMetric"), "RECOVERED is not a valid RatioMetric", graph.ErrorCodeValidation},
+		{errors.New("pq: password authentication failed"), graph.InternalErrorMessage, graph.ErrorCodeInternal},
+	}
+
+	for _, c := range cases {
+		gqlErr := graph.ErrorPresenter(ctx, c.err)
+
+		// Test cases
+		if gqlErr.Message != c.message || gqlErr.Extensions["code"] != c.code {
+			t.Errorf("expected %s with code %s; got %s with code %v", c.message, c.code, gqlErr.Message, gqlErr.Extensions["code"])
+		}
+	}
+}
+
+func TestErrorPresenterKeepsGraphQLCode(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	err := &gqlerror.Error{Message: "Unexpected Name", Extensions: map[string]interface{}{"code": "GRAPHQL_PARSE_FAILED"}}
+
+	gqlErr := graph.ErrorPresenter(ctx, err)
+
+	// Test cases
+	if gqlErr.Extensions["code"] != "GRAPHQL_PARSE_FAILED" {
+		t.Errorf("expected GRAPHQL_PARSE_FAILED; got %v", gqlErr.Extensions["code"])
+	}
+}
+
+func TestRecover(t *testing.T) {
+	// prapare data
+	output := &bytes.Buffer{}
+	log := logger.New(output, logger.Config{Format: logger.FormatJSON, Level: slog.LevelInfo})
+
+	err := graph.Recover(log)(context.Background(), "boom")
+	var record map[string]interface{}
+	if decodeErr := json.Unmarshal(output.Bytes(), &record); decodeErr != nil {
+		t.Fatalf("expected a JSON record; got %v", decodeErr)
+	}
+
+	// Test cases
+	if err == nil || err.Error() != "panic: boom" {
+		t.Errorf("expected panic: boom error; got %v", err)
+	}
+
+	// Test cases
+	stack, _ := record["stack"].(string)
+	if record["level"] != "ERROR" || record["panic"] != "boom" || !strings.Contains(stack, "goroutine") {
+		t.Errorf("expected the panic logged at the error level with its stack; got %v", record)
+	}
+}
@@ -2,6 +2,62 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type AggregateInput struct {
+	Region string `json:"region"`
+}
+
+type Alert struct {
+	ID             int           `json:"id"`
+	RuleID         int           `json:"ruleId"`
+	Country        string        `json:"country"`
+	Metric         AlertMetric   `json:"metric"`
+	Operator       AlertOperator `json:"operator"`
+	Threshold      float64       `json:"threshold"`
+	Value          float64       `json:"value"`
+	TriggeredAt    string        `json:"triggeredAt"`
+	AcknowledgedAt *string       `json:"acknowledgedAt,omitempty"`
+}
+
+type AlertInput struct {
+	ID int `json:"id"`
+}
+
+type AlertRule struct {
+	ID        int           `json:"id"`
+	Country   string        `json:"country"`
+	Metric    AlertMetric   `json:"metric"`
+	Operator  AlertOperator `json:"operator"`
+	Threshold float64       `json:"threshold"`
+	Triggered bool          `json:"triggered"`
+}
+
+type AlertRuleInput struct {
+	Country   string        `json:"country"`
+	Metric    AlertMetric   `json:"metric"`
+	Operator  AlertOperator `json:"operator"`
+	Threshold float64       `json:"threshold"`
+}
+
+type Comparison struct {
+	Series  []*CountrySeries     `json:"series"`
+	Summary []*ComparisonSummary `json:"summary"`
+}
+
+type ComparisonSummary struct {
+	Country                    string  `json:"country"`
+	Metric                     Metric  `json:"metric"`
+	First                      float64 `json:"first"`
+	Last                       float64 `json:"last"`
+	Change                     float64 `json:"change"`
+	DifferenceFromFirstCountry float64 `json:"differenceFromFirstCountry"`
+}
+
 type Country struct {
 	Name string `json:"name"`
 }
@@ -11,6 +67,65 @@ type CountryInput struct {
 	Name   string `json:"name"`
 }
 
+type CountrySeries struct {
+	Country string         `json:"country"`
+	Metric  Metric         `json:"metric"`
+	Points  []*SeriesPoint `json:"points"`
+}
+
+type CountryStatistics struct {
+	Name      string `json:"name"`
+	Confirmed int    `json:"confirmed"`
+	Deaths    int    `json:"deaths"`
+	Recovered int    `json:"recovered"`
+	Active    int    `json:"active"`
+}
+
+type DailyIndicator struct {
+	Date               string   `json:"date"`
+	Confirmed          int      `json:"confirmed"`
+	Deaths             int      `json:"deaths"`
+	NewCases           int      `json:"newCases"`
+	NewDeaths          int      `json:"newDeaths"`
+	NewCasesAverage    *float64 `json:"newCasesAverage,omitempty"`
+	NewDeathsAverage   *float64 `json:"newDeathsAverage,omitempty"`
+	WeekOverWeekGrowth *float64 `json:"weekOverWeekGrowth,omitempty"`
+	DoublingTime       *float64 `json:"doublingTime,omitempty"`
+	ReproductionNumber *float64 `json:"reproductionNumber,omitempty"`
+}
+
+type DigestPreference struct {
+	Channel     DigestChannel   `json:"channel"`
+	Destination string          `json:"destination"`
+	Frequency   DigestFrequency `json:"frequency"`
+	LastSentAt  *string         `json:"lastSentAt,omitempty"`
+}
+
+type DigestPreferenceInput struct {
+	Channel     DigestChannel   `json:"channel"`
+	Destination *string         `json:"destination,omitempty"`
+	Frequency   DigestFrequency `json:"frequency"`
+}
+
+type ExportInput struct {
+	Dataset   ExportDataset `json:"dataset"`
+	Format    ExportFormat  `json:"format"`
+	Countries []string      `json:"countries,omitempty"`
+	From      *string       `json:"from,omitempty"`
+	To        *string       `json:"to,omitempty"`
+}
+
+type ExportLink struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type IndicatorsInput struct {
+	Name string  `json:"name"`
+	From *string `json:"from,omitempty"`
+	To   *string `json:"to,omitempty"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -21,11 +136,87 @@ type PercentageInput struct {
 	Name   string `json:"name"`
 }
 
+type Ratio struct {
+	Country          string      `json:"country"`
+	Date             *string     `json:"date,omitempty"`
+	Numerator        RatioMetric `json:"numerator"`
+	Denominator      RatioMetric `json:"denominator"`
+	NumeratorValue   int         `json:"numeratorValue"`
+	DenominatorValue int         `json:"denominatorValue"`
+	Value            *float64    `json:"value,omitempty"`
+	Percentage       *float64    `json:"percentage,omitempty"`
+}
+
+type RatioInput struct {
+	Name        string      `json:"name"`
+	Numerator   RatioMetric `json:"numerator"`
+	Denominator RatioMetric `json:"denominator"`
+	Date        *string     `json:"date,omitempty"`
+}
+
+type Region struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type RegionAggregate struct {
+	Region           string               `json:"region"`
+	Confirmed        int                  `json:"confirmed"`
+	Deaths           int                  `json:"deaths"`
+	Recovered        int                  `json:"recovered"`
+	Active           int                  `json:"active"`
+	Countries        []*CountryStatistics `json:"countries"`
+	MissingCountries []string             `json:"missingCountries"`
+}
+
+type RegionCountryInput struct {
+	Region string `json:"region"`
+	Name   string `json:"name"`
+}
+
+type RegionInput struct {
+	Name      string   `json:"name"`
+	Countries []string `json:"countries"`
+}
+
 type RegisterInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type SeriesPoint struct {
+	Date  string  `json:"date"`
+	Day   int     `json:"day"`
+	Value float64 `json:"value"`
+}
+
+type StatisticsUpdate struct {
+	Country   string `json:"country"`
+	Confirmed int    `json:"confirmed"`
+	Deaths    int    `json:"deaths"`
+	Recovered int    `json:"recovered"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type SubdivisionInput struct {
+	Country string `json:"country"`
+	Name    string `json:"name"`
+}
+
+type SubdivisionRollupInput struct {
+	Country string `json:"country"`
+	Enabled bool   `json:"enabled"`
+}
+
+type SubdivisionStatistics struct {
+	Country   string `json:"country"`
+	Name      string `json:"name"`
+	Confirmed int    `json:"confirmed"`
+	Deaths    int    `json:"deaths"`
+	Recovered int    `json:"recovered"`
+	Active    int    `json:"active"`
+}
+
 type TopThreeCountriesInput struct {
 	UserID int    `json:"userId"`
 	Type   string `json:"type"`
@@ -36,3 +227,466 @@ type User struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+type Webhook struct {
+	ID        int            `json:"id"`
+	URL       string         `json:"url"`
+	Events    []WebhookEvent `json:"events"`
+	Countries []string       `json:"countries"`
+	Secret    *string        `json:"secret,omitempty"`
+	CreatedAt string         `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID             int                   `json:"id"`
+	WebhookID      int                   `json:"webhookId"`
+	Event          WebhookEvent          `json:"event"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus *int                  `json:"responseStatus,omitempty"`
+	Error          *string               `json:"error,omitempty"`
+	NextAttemptAt  *string               `json:"nextAttemptAt,omitempty"`
+	CreatedAt      string                `json:"createdAt"`
+	DeliveredAt    *string               `json:"deliveredAt,omitempty"`
+}
+
+type WebhookInput struct {
+	URL       string         `json:"url"`
+	Events    []WebhookEvent `json:"events,omitempty"`
+	Countries []string       `json:"countries,omitempty"`
+}
+
+type WebhookItemInput struct {
+	ID int `json:"id"`
+}
+
+type AlertMetric string
+
+const (
+	AlertMetricNewCases         AlertMetric = "NEW_CASES"
+	AlertMetricWeeklyGrowth     AlertMetric = "WEEKLY_GROWTH"
+	AlertMetricCaseFatalityRate AlertMetric = "CASE_FATALITY_RATE"
+)
+
+var AllAlertMetric = []AlertMetric{
+	AlertMetricNewCases,
+	AlertMetricWeeklyGrowth,
+	AlertMetricCaseFatalityRate,
+}
+
+func (e AlertMetric) IsValid() bool {
+	switch e {
+	case AlertMetricNewCases, AlertMetricWeeklyGrowth, AlertMetricCaseFatalityRate:
+		return true
+	}
+	return false
+}
+
+func (e AlertMetric) String() string {
+	return string(e)
+}
+
+func (e *AlertMetric) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertMetric(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertMetric", str)
+	}
+	return nil
+}
+
+func (e AlertMetric) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AlertOperator string
+
+const (
+	AlertOperatorAbove AlertOperator = "ABOVE"
+	AlertOperatorBelow AlertOperator = "BELOW"
+)
+
+var AllAlertOperator = []AlertOperator{
+	AlertOperatorAbove,
+	AlertOperatorBelow,
+}
+
+func (e AlertOperator) IsValid() bool {
+	switch e {
+	case AlertOperatorAbove, AlertOperatorBelow:
+		return true
+	}
+	return false
+}
+
+func (e AlertOperator) String() string {
+	return string(e)
+}
+
+func (e *AlertOperator) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertOperator(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertOperator", str)
+	}
+	return nil
+}
+
+func (e AlertOperator) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DigestChannel string
+
+const (
+	DigestChannelEmail DigestChannel = "EMAIL"
+	DigestChannelChat  DigestChannel = "CHAT"
+)
+
+var AllDigestChannel = []DigestChannel{
+	DigestChannelEmail,
+	DigestChannelChat,
+}
+
+func (e DigestChannel) IsValid() bool {
+	switch e {
+	case DigestChannelEmail, DigestChannelChat:
+		return true
+	}
+	return false
+}
+
+func (e DigestChannel) String() string {
+	return string(e)
+}
+
+func (e *DigestChannel) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DigestChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DigestChannel", str)
+	}
+	return nil
+}
+
+func (e DigestChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DigestFrequency string
+
+const (
+	DigestFrequencyDaily  DigestFrequency = "DAILY"
+	DigestFrequencyWeekly DigestFrequency = "WEEKLY"
+	DigestFrequencyOff    DigestFrequency = "OFF"
+)
+
+var AllDigestFrequency = []DigestFrequency{
+	DigestFrequencyDaily,
+	DigestFrequencyWeekly,
+	DigestFrequencyOff,
+}
+
+func (e DigestFrequency) IsValid() bool {
+	switch e {
+	case DigestFrequencyDaily, DigestFrequencyWeekly, DigestFrequencyOff:
+		return true
+	}
+	return false
+}
+
+func (e DigestFrequency) String() string {
+	return string(e)
+}
+
+func (e *DigestFrequency) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DigestFrequency(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DigestFrequency", str)
+	}
+	return nil
+}
+
+func (e DigestFrequency) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExportDataset string
+
+const (
+	ExportDatasetDaily  ExportDataset = "DAILY"
+	ExportDatasetTotals ExportDataset = "TOTALS"
+)
+
+var AllExportDataset = []ExportDataset{
+	ExportDatasetDaily,
+	ExportDatasetTotals,
+}
+
+func (e ExportDataset) IsValid() bool {
+	switch e {
+	case ExportDatasetDaily, ExportDatasetTotals:
+		return true
+	}
+	return false
+}
+
+func (e ExportDataset) String() string {
+	return string(e)
+}
+
+func (e *ExportDataset) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportDataset(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportDataset", str)
+	}
+	return nil
+}
+
+func (e ExportDataset) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "CSV"
+	ExportFormatNdjson  ExportFormat = "NDJSON"
+	ExportFormatParquet ExportFormat = "PARQUET"
+)
+
+var AllExportFormat = []ExportFormat{
+	ExportFormatCSV,
+	ExportFormatNdjson,
+	ExportFormatParquet,
+}
+
+func (e ExportFormat) IsValid() bool {
+	switch e {
+	case ExportFormatCSV, ExportFormatNdjson, ExportFormatParquet:
+		return true
+	}
+	return false
+}
+
+func (e ExportFormat) String() string {
+	return string(e)
+}
+
+func (e *ExportFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportFormat", str)
+	}
+	return nil
+}
+
+func (e ExportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Metric string
+
+const (
+	MetricConfirmed Metric = "CONFIRMED"
+	MetricDeaths    Metric = "DEATHS"
+	MetricRecovered Metric = "RECOVERED"
+	MetricActive    Metric = "ACTIVE"
+	MetricNewCases  Metric = "NEW_CASES"
+	MetricNewDeaths Metric = "NEW_DEATHS"
+)
+
+var AllMetric = []Metric{
+	MetricConfirmed,
+	MetricDeaths,
+	MetricRecovered,
+	MetricActive,
+	MetricNewCases,
+	MetricNewDeaths,
+}
+
+func (e Metric) IsValid() bool {
+	switch e {
+	case MetricConfirmed, MetricDeaths, MetricRecovered, MetricActive, MetricNewCases, MetricNewDeaths:
+		return true
+	}
+	return false
+}
+
+func (e Metric) String() string {
+	return string(e)
+}
+
+func (e *Metric) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Metric(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Metric", str)
+	}
+	return nil
+}
+
+func (e Metric) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RatioMetric string
+
+const (
+	RatioMetricConfirmed RatioMetric = "CONFIRMED"
+	RatioMetricDeaths    RatioMetric = "DEATHS"
+	RatioMetricRecovered RatioMetric = "RECOVERED"
+	RatioMetricActive    RatioMetric = "ACTIVE"
+)
+
+var AllRatioMetric = []RatioMetric{
+	RatioMetricConfirmed,
+	RatioMetricDeaths,
+	RatioMetricRecovered,
+	RatioMetricActive,
+}
+
+func (e RatioMetric) IsValid() bool {
+	switch e {
+	case RatioMetricConfirmed, RatioMetricDeaths, RatioMetricRecovered, RatioMetricActive:
+		return true
+	}
+	return false
+}
+
+func (e RatioMetric) String() string {
+	return string(e)
+}
+
+func (e *RatioMetric) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RatioMetric(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RatioMetric", str)
+	}
+	return nil
+}
+
+func (e RatioMetric) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusSucceeded,
+	WebhookDeliveryStatusFailed,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventStatisticsChanged WebhookEvent = "STATISTICS_CHANGED"
+	WebhookEventThresholdAlert    WebhookEvent = "THRESHOLD_ALERT"
+)
+
+var AllWebhookEvent = []WebhookEvent{
+	WebhookEventStatisticsChanged,
+	WebhookEventThresholdAlert,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventStatisticsChanged, WebhookEventThresholdAlert:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (e *WebhookEvent) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEvent", str)
+	}
+	return nil
+}
+
+func (e WebhookEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
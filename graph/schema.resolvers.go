@@ -8,36 +8,253 @@ import (
 	"context"
 
 	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
 )
 
 // Register is the resolver for the register field.
 func (r *mutationResolver) Register(ctx context.Context, input model.RegisterInput) (bool, error) {
-	return r.UserService.CreateNewUser(input.Email, input.Password)
+	return r.UserService.CreateNewUser(ctx, input.Email, input.Password)
 }
 
 // Login is the resolver for the login field.
 func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (string, error) {
-	return r.UserService.Login(input.Email, input.Password)
+	return r.UserService.Login(ctx, input.Email, input.Password)
 }
 
 // AddCountry is the resolver for the addCountry field.
 func (r *mutationResolver) AddCountry(ctx context.Context, input *model.CountryInput) (bool, error) {
-	return r.Covid19Service.AddCountry(input.Name, input.UserID)
+	if input == nil {
+		return false, &services.ValidationError{Message: "missing input"}
+	}
+	return r.Covid19Service.AddCountry(ctx, input.Name, input.UserID)
+}
+
+// CreateRegion is the resolver for the createRegion field.
+func (r *mutationResolver) CreateRegion(ctx context.Context, input model.RegionInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.CreateRegion(ctx, userID, input.Name, input.Countries)
+}
+
+// AddCountryToRegion is the resolver for the addCountryToRegion field.
+func (r *mutationResolver) AddCountryToRegion(ctx context.Context, input model.RegionCountryInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.AddCountryToRegion(ctx, userID, input.Region, input.Name)
+}
+
+// AddSubdivision is the resolver for the addSubdivision field.
+func (r *mutationResolver) AddSubdivision(ctx context.Context, input model.SubdivisionInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.AddSubdivision(ctx, userID, input.Country, input.Name)
+}
+
+// SetSubdivisionRollup is the resolver for the setSubdivisionRollup field.
+func (r *mutationResolver) SetSubdivisionRollup(ctx context.Context, input model.SubdivisionRollupInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.SetSubdivisionRollup(ctx, userID, input.Country, input.Enabled)
+}
+
+// CreateAlertRule is the resolver for the createAlertRule field.
+func (r *mutationResolver) CreateAlertRule(ctx context.Context, input model.AlertRuleInput) (*model.AlertRule, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.CreateAlertRule(ctx, userID, input.Country, input.Metric, input.Operator, input.Threshold)
+}
+
+// DeleteAlertRule is the resolver for the deleteAlertRule field.
+func (r *mutationResolver) DeleteAlertRule(ctx context.Context, input model.AlertInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.DeleteAlertRule(ctx, userID, input.ID)
+}
+
+// AcknowledgeAlert is the resolver for the acknowledgeAlert field.
+func (r *mutationResolver) AcknowledgeAlert(ctx context.Context, input model.AlertInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.AcknowledgeAlert(ctx, userID, input.ID)
+}
+
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, input model.WebhookInput) (*model.Webhook, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.CreateWebhook(ctx, userID, input.URL, input.Events, input.Countries)
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, input model.WebhookItemInput) (bool, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return r.Covid19Service.DeleteWebhook(ctx, userID, input.ID)
+}
+
+// RedeliverWebhook is the resolver for the redeliverWebhook field.
+func (r *mutationResolver) RedeliverWebhook(ctx context.Context, input model.WebhookItemInput) (*model.WebhookDelivery, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.RedeliverWebhook(ctx, userID, input.ID)
+}
+
+// SetDigestPreference is the resolver for the setDigestPreference field.
+func (r *mutationResolver) SetDigestPreference(ctx context.Context, input model.DigestPreferenceInput) (*model.DigestPreference, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.SetDigestPreference(ctx, userID, input.Channel, input.Destination, input.Frequency)
 }
 
 // List is the resolver for the list field.
 func (r *queryResolver) List(ctx context.Context, userID int) ([]*model.Country, error) {
-	return r.Covid19Service.GetCountries(userID)
+	return r.Covid19Service.GetCountries(ctx, userID)
 }
 
 // PercentageeOfDeathToConfirmed is the resolver for the percentageeOfDeathToConfirmed field.
 func (r *queryResolver) PercentageeOfDeathToConfirmed(ctx context.Context, input model.PercentageInput) (float64, error) {
-	return r.Covid19Service.PercentageOfDeathToConfirmed(input.UserID, input.Name)
+	return r.Covid19Service.PercentageOfDeathToConfirmed(ctx, input.UserID, input.Name)
 }
 
 // GetTopThreeCountries is the resolver for the getTopThreeCountries field.
 func (r *queryResolver) GetTopThreeCountries(ctx context.Context, input model.TopThreeCountriesInput) ([]*model.Country, error) {
-	return r.Covid19Service.GetTopThreeCountries(input.UserID, input.Type)
+	return r.Covid19Service.GetTopThreeCountries(ctx, input.UserID, input.Type)
+}
+
+// Indicators is the resolver for the indicators field.
+func (r *queryResolver) Indicators(ctx context.Context, input model.IndicatorsInput) ([]*model.DailyIndicator, error) {
+	return r.Covid19Service.GetIndicators(ctx, input.Name, input.From, input.To)
+}
+
+// Regions is the resolver for the regions field.
+func (r *queryResolver) Regions(ctx context.Context) ([]*model.Region, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetRegions(ctx, userID)
+}
+
+// Aggregate is the resolver for the aggregate field.
+func (r *queryResolver) Aggregate(ctx context.Context, input model.AggregateInput) (*model.RegionAggregate, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetRegionAggregate(ctx, userID, input.Region)
+}
+
+// Compare is the resolver for the compare field.
+func (r *queryResolver) Compare(ctx context.Context, countries []string, metrics []model.Metric, from *string, to *string, alignAfterCases *int) (*model.Comparison, error) {
+	return r.Covid19Service.Compare(ctx, countries, metrics, from, to, alignAfterCases)
+}
+
+// Subdivisions is the resolver for the subdivisions field.
+func (r *queryResolver) Subdivisions(ctx context.Context) ([]*model.SubdivisionStatistics, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetSubdivisions(ctx, userID)
+}
+
+// CountrySubdivisions is the resolver for the countrySubdivisions field.
+func (r *queryResolver) CountrySubdivisions(ctx context.Context, name string) ([]*model.SubdivisionStatistics, error) {
+	return r.Covid19Service.GetCountrySubdivisions(ctx, name)
+}
+
+// Ratio is the resolver for the ratio field.
+func (r *queryResolver) Ratio(ctx context.Context, input model.RatioInput) (*model.Ratio, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.Ratio(ctx, userID, input.Name, input.Numerator, input.Denominator, input.Date)
+}
+
+// AlertRules is the resolver for the alertRules field.
+func (r *queryResolver) AlertRules(ctx context.Context) ([]*model.AlertRule, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetAlertRules(ctx, userID)
+}
+
+// Alerts is the resolver for the alerts field.
+func (r *queryResolver) Alerts(ctx context.Context, unacknowledged *bool) ([]*model.Alert, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetAlerts(ctx, userID, unacknowledged != nil && *unacknowledged)
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context) ([]*model.Webhook, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetWebhooks(ctx, userID)
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, webhookID int) ([]*model.WebhookDelivery, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetWebhookDeliveries(ctx, userID, webhookID)
+}
+
+// DigestPreferences is the resolver for the digestPreferences field.
+func (r *queryResolver) DigestPreferences(ctx context.Context) ([]*model.DigestPreference, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetDigestPreferences(ctx, userID)
+}
+
+// ExportLink is the resolver for the exportLink field.
+func (r *queryResolver) ExportLink(ctx context.Context, input model.ExportInput) (*model.ExportLink, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.GetExportLink(ctx, userID, input)
+}
+
+// StatisticsUpdated is the resolver for the statisticsUpdated field.
+func (r *subscriptionResolver) StatisticsUpdated(ctx context.Context, countries []string) (<-chan *model.StatisticsUpdate, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Covid19Service.SubscribeStatistics(ctx, userID, countries)
 }
 
 // Mutation returns MutationResolver implementation.
@@ -46,5 +263,9 @@ func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,161 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrorCodeDepthLimit is set on the operations nested deeper than allowed.
+const ErrorCodeDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// ServerConfig tells how the operations are limited.
+type ServerConfig struct {
+	// ComplexityLimit bounds the number of fields an operation selects, zero
+	// means no limit.
+	ComplexityLimit int
+	// DepthLimit bounds how deep the selections of an operation are nested,
+	// zero means no limit.
+	DepthLimit int
+	// Introspection lets the clients query the schema.
+	Introspection bool
+	// PersistedQueries is the number of documents kept for the clients
+	// sending their hash only, zero disables the persisted queries.
+	PersistedQueries int
+	// WebsocketInit checks the connection_init payload of the websocket
+	// clients, it may put the user in the context with ContextWithUserID.
+	WebsocketInit transport.WebsocketInitFunc
+	// Logger records the panics of the resolvers, nil discards them.
+	Logger logger.Logger
+}
+
+type userIDKey struct{}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user.
+func ContextWithUserID(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the user authenticated by ContextWithUserID, 0
+// if there is none.
+func UserIDFromContext(ctx context.Context) int {
+	userID, _ := ctx.Value(userIDKey{}).(int)
+	return userID
+}
+
+// authenticatedUserID returns the user of UserIDFromContext, the resolvers
+// of the fields private to a user failing without it.
+func authenticatedUserID(ctx context.Context) (int, error) {
+	userID := UserIDFromContext(ctx)
+	if userID == 0 {
+		return 0, &services.UnauthenticatedError{Message: "authorization token not provided"}
+	}
+	return userID, nil
+}
+
+// NewServer builds the GraphQL server of the resolver, the extensions are
+// used on top of the limits.
+func NewServer(resolver *Resolver, config ServerConfig, extensions ...graphql.HandlerExtension) *handler.Server {
+	server := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+
+	server.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		InitFunc:              config.WebsocketInit,
+	})
+	server.AddTransport(transport.Options{})
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.AddTransport(transport.MultipartForm{})
+
+	server.SetQueryCache(lru.New(1000))
+	server.SetErrorPresenter(ErrorPresenter)
+	log := config.Logger
+	if log == nil {
+		log = logger.Discard()
+	}
+	server.SetRecoverFunc(Recover(log))
+
+	if config.Introspection {
+		server.Use(extension.Introspection{})
+	}
+	if config.PersistedQueries > 0 {
+		server.Use(extension.AutomaticPersistedQuery{Cache: lru.New(config.PersistedQueries)})
+	}
+	if config.ComplexityLimit > 0 {
+		server.Use(extension.FixedComplexityLimit(config.ComplexityLimit))
+	}
+	if config.DepthLimit > 0 {
+		server.Use(DepthLimit{Limit: config.DepthLimit})
+	}
+	for _, extension := range extensions {
+		server.Use(extension)
+	}
+	return server
+}
+
+// DepthLimit refuses the operations whose selections are nested deeper than
+// Limit. The introspection fields aren't counted, their depth being set by
+// the clients' tooling.
+type DepthLimit struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = DepthLimit{}
+
+func (DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (DepthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, operationContext *graphql.OperationContext) *gqlerror.Error {
+	if operationContext.Operation == nil {
+		return nil
+	}
+	if depth := selectionDepth(operationContext.Operation.SelectionSet); depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, ErrorCodeDepthLimit)
+		return err
+	}
+	return nil
+}
+
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		var nested int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+			nested = 1 + selectionDepth(selection.SelectionSet)
+		case *ast.InlineFragment:
+			nested = selectionDepth(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				nested = selectionDepth(selection.Definition.SelectionSet)
+			}
+		}
+		if nested > depth {
+			depth = nested
+		}
+	}
+	return depth
+}
@@ -0,0 +1,110 @@
+package graph_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+)
+
+// compareQuery selects fields three levels deep.
+const compareQuery = `{ compare(countries: ["Palestine"], metrics: [CONFIRMED]) { series { country } } }`
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, server http.Handler, body string) response {
+	request := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	var res response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("expected a GraphQL response; got %s", recorder.Body.String())
+	}
+	return res
+}
+
+func queryBody(query string) string {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	return string(body)
+}
+
+func TestNewServerLimits(t *testing.T) {
+	// prapare data
+	cases := []struct {
+		config graph.ServerConfig
+		code   string
+	}{
+		{graph.ServerConfig{DepthLimit: 2}, graph.ErrorCodeDepthLimit},
+		{graph.ServerConfig{ComplexityLimit: 2}, "COMPLEXITY_LIMIT_EXCEEDED"},
+	}
+
+	for _, c := range cases {
+		res := post(t, graph.NewServer(&graph.Resolver{}, c.config), queryBody(compareQuery))
+
+		// Test cases
+		if len(res.Errors) != 1 || res.Errors[0].Extensions.Code != c.code {
+			t.Errorf("expected %s error; got %+v", c.code, res.Errors)
+		}
+	}
+}
+
+func TestNewServerDepthIgnoresIntrospection(t *testing.T) {
+	// prapare data
+	server := graph.NewServer(&graph.Resolver{}, graph.ServerConfig{DepthLimit: 1, Introspection: true})
+
+	res := post(t, server, queryBody(`{ __schema { queryType { fields { type { ofType { name } } } } } }`))
+
+	// Test cases
+	if len(res.Errors) != 0 {
+		t.Errorf("expected the introspection query to pass; got %+v", res.Errors)
+	}
+}
+
+func TestNegativeNewServerIntrospection(t *testing.T) {
+	// prapare data
+	server := graph.NewServer(&graph.Resolver{}, graph.ServerConfig{})
+
+	res := post(t, server, queryBody(`{ __schema { queryType { name } } }`))
+
+	// Test cases
+	if len(res.Errors) == 0 {
+		t.Errorf("expected introspection to be disabled; got %s", res.Data)
+	}
+}
+
+func TestNewServerPersistedQueries(t *testing.T) {
+	// prapare data
+	server := graph.NewServer(&graph.Resolver{}, graph.ServerConfig{PersistedQueries: 10})
+	query := `{ __typename }`
+	hash := sha256.Sum256([]byte(query))
+	extensions := `"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "` + hex.EncodeToString(hash[:]) + `"}}`
+
+	unknown := post(t, server, `{`+extensions+`}`)
+	registered := post(t, server, `{"query": "`+query+`", `+extensions+`}`)
+	persisted := post(t, server, `{`+extensions+`}`)
+
+	// Test cases
+	if len(unknown.Errors) != 1 || unknown.Errors[0].Extensions.Code != "PERSISTED_QUERY_NOT_FOUND" {
+		t.Errorf("expected PERSISTED_QUERY_NOT_FOUND before the query is sent; got %+v", unknown.Errors)
+	}
+
+	// Test cases
+	if len(registered.Errors) != 0 || len(persisted.Errors) != 0 || string(persisted.Data) != `{"__typename":"Query"}` {
+		t.Errorf("expected the hash alone to run the query once sent; got %s and %+v", persisted.Data, persisted.Errors)
+	}
+}
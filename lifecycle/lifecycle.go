@@ -0,0 +1,165 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Hook is a component started and stopped along with the app. Start must not
+// block, long running work goes in a goroutine stopped by Stop. Both may be
+// nil.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager starts its hooks in the order they were appended and stops them in
+// the reverse order, so that a hook may rely on the ones appended before it.
+type Manager struct {
+	// ShutdownTimeout bounds the time all the hooks take to stop.
+	ShutdownTimeout time.Duration
+
+	hooks  []Hook
+	failed chan error
+}
+
+func NewManager(shutdownTimeout time.Duration) *Manager {
+	return &Manager{
+		ShutdownTimeout: shutdownTimeout,
+		failed:          make(chan error, 1),
+	}
+}
+
+// Append adds a hook started after the ones already appended.
+func (m *Manager) Append(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Fail tells the manager a hook can't go on, which stops the app as if ctx
+// of Run was done.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failed <- err:
+	default:
+	}
+}
+
+// Run starts the hooks and waits until ctx is done or a hook fails, then
+// stops the started hooks. A hook failing to start stops the ones started
+// before it. The first error is returned, the others are logged.
+func (m *Manager) Run(ctx context.Context) error {
+	var runErr error
+	started := 0
+	for _, hook := range m.hooks {
+		if hook.Start != nil {
+			if err := hook.Start(ctx); err != nil {
+				runErr = fmt.Errorf("failed to start %s: %w", hook.Name, err)
+				break
+			}
+		}
+		started++
+	}
+
+	if runErr == nil {
+		select {
+		case <-ctx.Done():
+		case err := <-m.failed:
+			runErr = err
+		}
+		log.Println("shutting down")
+	}
+
+	stopErr := m.stop(m.hooks[:started])
+	if runErr != nil {
+		return runErr
+	}
+	return stopErr
+}
+
+func (m *Manager) stop(hooks []Hook) error {
+	ctx := context.Background()
+	if m.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.ShutdownTimeout)
+		defer cancel()
+	}
+
+	var firstErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].Stop == nil {
+			continue
+		}
+		if err := hooks[i].Stop(ctx); err != nil {
+			err = fmt.Errorf("failed to stop %s: %w", hooks[i].Name, err)
+			log.Println(err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Worker returns a hook running work in a goroutine. Its context is cancelled
+// on stop, which then waits for work to return.
+func Worker(name string, work func(ctx context.Context)) Hook {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			// not derived from the context of Run, so that the worker is only
+			// stopped once the hooks appended after it are
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				work(ctx)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Server returns a hook serving the requests on server.Addr. Stopping it
+// waits for the in-flight requests, which are cut once ctx is done. Serving
+// errors are reported to fail.
+func Server(name string, server *http.Server, fail func(error)) Hook {
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					fail(fmt.Errorf("failed to run %s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			if err := server.Shutdown(ctx); err != nil {
+				server.Close()
+				return err
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/lifecycle"
+)
+
+// recorder appends the events of the hooks in the order they happen.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) hook(name string, startErr error) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestManagerRun(t *testing.T) {
+	// prapare data
+	events := &recorder{}
+	manager := lifecycle.NewManager(time.Second)
+	manager.Append(events.hook("database", nil))
+	manager.Append(events.hook("worker", nil))
+	manager.Append(events.hook("server", nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := manager.Run(ctx)
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	expected := []string{"start database", "start worker", "start server", "stop server", "stop worker", "stop database"}
+	if !reflect.DeepEqual(events.events, expected) {
+		t.Errorf("expected %v; got %v", expected, events.events)
+	}
+}
+
+func TestNegativeManagerRun(t *testing.T) {
+	// prapare data
+	events := &recorder{}
+	manager := lifecycle.NewManager(time.Second)
+	manager.Append(events.hook("database", nil))
+	manager.Append(events.hook("server", errors.New("address already in use")))
+	manager.Append(events.hook("worker", nil))
+
+	err := manager.Run(context.Background())
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected failed to start server error; got nil")
+	}
+
+	// Test cases
+	expected := []string{"start database", "start server", "stop database"}
+	if !reflect.DeepEqual(events.events, expected) {
+		t.Errorf("expected %v; got %v", expected, events.events)
+	}
+}
+
+func TestManagerFail(t *testing.T) {
+	// prapare data
+	events := &recorder{}
+	manager := lifecycle.NewManager(time.Second)
+	manager.Append(events.hook("database", nil))
+	manager.Fail(errors.New("server stopped"))
+
+	err := manager.Run(context.Background())
+
+	// Test cases
+	if err == nil || err.Error() != "server stopped" {
+		t.Errorf("expected server stopped error; got %v", err)
+	}
+
+	// Test cases
+	if !reflect.DeepEqual(events.events, []string{"start database", "stop database"}) {
+		t.Errorf("expected the database to be stopped; got %v", events.events)
+	}
+}
+
+func TestWorker(t *testing.T) {
+	// prapare data
+	stopped := false
+	worker := lifecycle.Worker("refresher", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped = true
+	})
+
+	err := worker.Start(context.Background())
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	err = worker.Stop(context.Background())
+
+	// Test cases
+	if err != nil || !stopped {
+		t.Errorf("expected the worker to return once cancelled; got %v and %v", stopped, err)
+	}
+}
+
+func TestNegativeWorker(t *testing.T) {
+	// prapare data
+	release := make(chan struct{})
+	defer close(release)
+	worker := lifecycle.Worker("stuck", func(context.Context) {
+		<-release
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	worker.Start(context.Background())
+	err := worker.Stop(ctx)
+
+	// Test cases
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+}
+
+func TestServerDrainsRequests(t *testing.T) {
+	// prapare data
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	handling := make(chan struct{})
+	server := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(handling)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})}
+	hook := lifecycle.Server("server", server, func(err error) { t.Error(err) })
+	if err := hook.Start(context.Background()); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	status := make(chan int)
+	go func() {
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-handling
+	err = hook.Stop(context.Background())
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if code := <-status; code != http.StatusOK {
+		t.Errorf("expected the in-flight request to succeed; got %v", code)
+	}
+}
@@ -1,32 +1,165 @@
 package logger
 
 import (
-	"log"
-	"os"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formats of the records.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
 )
 
-type LoggerCollection struct {
-	ErrorLogger *log.Logger
-	InfoLogger  *log.Logger
-	DebugLogger *log.Logger
+// Redacted replaces the values of the secret fields.
+const Redacted = "[REDACTED]"
+
+// secretKeys are the parts of the field names whose values are redacted.
+var secretKeys = []string{"password", "token", "secret", "authorization", "dsn"}
+
+// Logger writes leveled structured records. The args are key-value pairs,
+// the fields added to ctx with AppendContext and the ones added with With are
+// repeated in every record.
+type Logger interface {
+	Debug(ctx context.Context, msg string, args ...interface{})
+	Info(ctx context.Context, msg string, args ...interface{})
+	Warn(ctx context.Context, msg string, args ...interface{})
+	Error(ctx context.Context, msg string, args ...interface{})
+	With(args ...interface{}) Logger
 }
 
-func NewLoggerCollection() *LoggerCollection {
-	return &LoggerCollection{
-		ErrorLogger: log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-		InfoLogger:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime),
-		DebugLogger: log.New(os.Stdout, "DEBUG: ", log.Ldate|log.Ltime|log.Lshortfile),
+// Config tells how the records are written.
+type Config struct {
+	// Format is FormatJSON or FormatText.
+	Format string
+	// Level is the lowest level written.
+	Level slog.Level
+}
+
+// Validate checks the format.
+func (c Config) Validate() error {
+	if c.Format != FormatJSON && c.Format != FormatText {
+		return fmt.Errorf("invalid log format %s, expected %s or %s", c.Format, FormatJSON, FormatText)
 	}
+	return nil
 }
 
-func (l *LoggerCollection) AddErrorLogger(message string) {
-	l.ErrorLogger.Println(message)
+// SlogLogger is the Logger backed by log/slog.
+type SlogLogger struct {
+	logger *slog.Logger
 }
 
-func (l *LoggerCollection) AddInfoLogger(message string) {
-	l.InfoLogger.Println(message)
+// New returns a logger writing to w, the values of the fields named like a
+// password, a token or a secret are redacted.
+func New(w io.Writer, config Config) *SlogLogger {
+	options := &slog.HandlerOptions{Level: config.Level, ReplaceAttr: redact}
+	var handler slog.Handler
+	if config.Format == FormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return &SlogLogger{logger: slog.New(contextHandler{handler})}
 }
 
-func (l *LoggerCollection) AddDebugLogger(message string) {
-	l.DebugLogger.Println(message)
+// Discard returns a logger writing nothing.
+func Discard() *SlogLogger {
+	return New(io.Discard, Config{Format: FormatText})
+}
+
+// Slog returns the underlying logger, to be used as the default one.
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+func (l *SlogLogger) Debug(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.DebugContext(ctx, msg, args...)
+}
+
+func (l *SlogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.InfoContext(ctx, msg, args...)
+}
+
+func (l *SlogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.WarnContext(ctx, msg, args...)
+}
+
+func (l *SlogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.ErrorContext(ctx, msg, args...)
+}
+
+func (l *SlogLogger) With(args ...interface{}) Logger {
+	return &SlogLogger{logger: l.logger.With(args...)}
+}
+
+type contextKey struct{}
+
+// AppendContext returns a copy of ctx holding the fields as well, such as the
+// ID of the request being served.
+func AppendContext(ctx context.Context, args ...interface{}) context.Context {
+	record := slog.Record{}
+	record.Add(args...)
+	fields := append([]slog.Attr{}, contextFields(ctx)...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = append(fields, attr)
+		return true
+	})
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// ContextValue returns the value of the field of ctx named key, nil if none.
+func ContextValue(ctx context.Context, key string) interface{} {
+	fields := contextFields(ctx)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return fields[i].Value.Any()
+		}
+	}
+	return nil
+}
+
+func contextFields(ctx context.Context) []slog.Attr {
+	fields, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return fields
+}
+
+// contextHandler adds the fields of the context, and the IDs of the trace and
+// the span it holds, to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := contextFields(ctx)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields[:len(fields):len(fields)], slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	if len(fields) != 0 {
+		record = record.Clone()
+		record.AddAttrs(fields...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, secretKey := range secretKeys {
+		if strings.Contains(key, secretKey) {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+	return attr
 }
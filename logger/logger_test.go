@@ -0,0 +1,110 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func decode(t *testing.T, output *bytes.Buffer) map[string]interface{} {
+	var record map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record; got %q", output.String())
+	}
+	return record
+}
+
+func TestLoggerContextFields(t *testing.T) {
+	// prapare data
+	output := &bytes.Buffer{}
+	log := logger.New(output, logger.Config{Format: logger.FormatJSON, Level: slog.LevelInfo})
+	ctx := logger.AppendContext(context.Background(), "request_id", "abc", "route", "/ratio/:name")
+	ctx = logger.AppendContext(ctx, "user_id", 7)
+
+	log.With("component", "services").Info(ctx, "ratio computed", "country", "Palestine")
+	record := decode(t, output)
+
+	// Test cases
+	expected := map[string]interface{}{"level": "INFO", "msg": "ratio computed", "country": "Palestine", "component": "services", "request_id": "abc", "route": "/ratio/:name", "user_id": float64(7)}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("expected %s to be %v; got %v", key, value, record[key])
+		}
+	}
+
+	// Test cases
+	if value := logger.ContextValue(ctx, "request_id"); value != "abc" {
+		t.Errorf("expected abc; got %v", value)
+	}
+	if value := logger.ContextValue(context.Background(), "request_id"); value != nil {
+		t.Errorf("expected nil; got %v", value)
+	}
+}
+
+func TestLoggerRedactsSecrets(t *testing.T) {
+	// prapare data
+	output := &bytes.Buffer{}
+	log := logger.New(output, logger.Config{Format: logger.FormatJSON})
+
+	log.Info(context.Background(), "login", "email", "user@example.com", "password", "hunter2", "Authorization", "eyJhbGciOi", "db_dsn", "postgres://u:p@host/db")
+	record := decode(t, output)
+
+	// Test cases
+	for _, key := range []string{"password", "Authorization", "db_dsn"} {
+		if record[key] != logger.Redacted {
+			t.Errorf("expected %s to be redacted; got %v", key, record[key])
+		}
+	}
+
+	// Test cases
+	if record["email"] != "user@example.com" {
+		t.Errorf("expected the email to be kept; got %v", record["email"])
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	// prapare data
+	output := &bytes.Buffer{}
+	log := logger.New(output, logger.Config{Format: logger.FormatText, Level: slog.LevelWarn})
+
+	log.Debug(context.Background(), "debug")
+	log.Info(context.Background(), "info")
+	log.Warn(context.Background(), "warn")
+
+	// Test cases
+	if lines := strings.Split(strings.TrimSpace(output.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "level=WARN") {
+		t.Errorf("expected only the warning to be written; got %q", output.String())
+	}
+}
+
+func TestNegativeConfigValidate(t *testing.T) {
+	// prapare data
+	config := logger.Config{Format: "xml"}
+
+	// Test cases
+	if err := config.Validate(); err == nil {
+		t.Errorf("expected invalid log format error; got nil")
+	}
+}
+
+func TestLoggerTraceFields(t *testing.T) {
+	// prapare data
+	output := &bytes.Buffer{}
+	log := logger.New(output, logger.Config{Format: logger.FormatJSON})
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	log.Info(ctx, "request served")
+	record := decode(t, output)
+
+	// Test cases
+	if record["trace_id"] != span.SpanContext().TraceID().String() || record["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("expected the trace and span IDs; got %v", record)
+	}
+}
@@ -1,25 +1,145 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
+	"github.com/FaresAbuIram/COVID19-Statistics/config"
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/lifecycle"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
+	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
 	"github.com/FaresAbuIram/COVID19-Statistics/routes"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
 )
 
-const defaultPort = "8080"
-
 func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(cfg, os.Args[2:])
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
+	// the standard logger, used by the lifecycle package, writes through it as
+	// well
+	appLogger := logger.New(os.Stderr, cfg.Log)
+	slog.SetDefault(appLogger.Slog())
+
+	// cancelled on SIGINT or SIGTERM to shut the app down, a second signal
+	// kills it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// stopped in the reverse order: the server drains the in-flight requests,
+	// then the refresher stops, the database is closed and the last spans are
+	// exported
+	manager := lifecycle.NewManager(cfg.ShutdownTimeout)
+	stopTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+	manager.Append(lifecycle.Hook{Name: "tracing", Stop: stopTracing})
+
+	m := metrics.New()
+	if cfg.Production() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
 	}
 
-	router := gin.Default()
+	router.Use(gin.Recovery(), middleware.Tracing(), middleware.RequestLogger(appLogger), middleware.Metrics(m), cors.Default())
+	routes.Setup(ctx, router, cfg, manager, appLogger, m)
 
-	router.Use(cors.Default())
-	routes.Setup(router)
-	router.Run(":" + port)
+	server := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Port), Handler: router}
+	manager.Append(lifecycle.Server("server", server, manager.Fail))
+
+	if err := manager.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("stopped")
+}
+
+// migrate runs the migrate subcommand:
+//
+//	migrate up          apply every pending migration
+//	migrate down [n]    revert the last n migrations, 1 by default
+//	migrate status      list the migrations and when they were applied
+//
+// Only the database settings are validated, the subcommand doesn't serve the
+// API.
+func migrate(cfg *config.Config, args []string) {
+	if err := cfg.Database.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
+	db, err := database.Connect(context.Background(), cfg.Database, logger.New(os.Stderr, cfg.Log))
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		count, err := database.MigrateUp(db, cfg.Database.Driver)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("applied %d migration(s)\n", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of migrations to revert: %s", args[1])
+			}
+		}
+		count, err := database.MigrateDown(db, cfg.Database.Driver, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("reverted %d migration(s)\n", count)
+	case "status":
+		statuses, err := database.GetMigrationsStatus(db, cfg.Database.Driver)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		log.Fatalf("unknown migrate command %s, expected up, down or status", command)
+	}
 }
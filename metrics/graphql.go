@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// GraphQL returns the gqlgen extension recording the operations. They are
+// labelled with their root fields rather than their names, which are chosen
+// by the clients.
+func (m *Metrics) GraphQL() graphql.HandlerExtension {
+	return graphQLExtension{metrics: m}
+}
+
+type graphQLExtension struct {
+	metrics *Metrics
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = graphQLExtension{}
+
+func (graphQLExtension) ExtensionName() string {
+	return "Metrics"
+}
+
+func (graphQLExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e graphQLExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	start := time.Now()
+	response := next(ctx)
+	if !graphql.HasOperationContext(ctx) {
+		return response
+	}
+
+	operation := graphql.GetOperationContext(ctx).Operation
+	if operation == nil {
+		return response
+	}
+	failed := response != nil && len(response.Errors) != 0
+	for _, selection := range operation.SelectionSet {
+		if field, ok := selection.(*ast.Field); ok {
+			e.metrics.ObserveOperation(string(operation.Operation), field.Name, failed, time.Since(start))
+		}
+	}
+	return response
+}
@@ -0,0 +1,246 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "covid19"
+
+// Results of the refresh of a country.
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+)
+
+// Metrics holds the collectors exposed on /metrics. A nil *Metrics records
+// nothing, so that the components may be used without it.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	graphQLOperations *prometheus.CounterVec
+	graphQLDuration   *prometheus.HistogramVec
+	queryDuration     *prometheus.HistogramVec
+	refreshDuration   prometheus.Histogram
+	refreshCountries  *prometheus.CounterVec
+	upstreamDuration  *prometheus.HistogramVec
+	upstreamResponses *prometheus.CounterVec
+	staleness         *stalenessCollector
+}
+
+// New returns the metrics registered in their own registry along with the
+// Go runtime and process ones.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve the HTTP requests, by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		graphQLOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_operations_total",
+			Help:      "GraphQL operations executed, by type, root field and result.",
+		}, []string{"type", "field", "result"}),
+		graphQLDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_operation_duration_seconds",
+			Help:      "Time taken to execute the GraphQL operations, by type and root field.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type", "field"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by the database queries, by repository method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"query"}),
+		refreshDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "refresher_run_duration_seconds",
+			Help:      "Time taken by the runs of the statistics refresher.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+		refreshCountries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "refresher_countries_total",
+			Help:      "Countries refreshed by the statistics refresher, by result.",
+		}, []string{"result"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Time taken by the requests to the COVID-19 API, by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		upstreamResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_responses_total",
+			Help:      "Responses of the COVID-19 API, by endpoint and status code, error when unreachable.",
+		}, []string{"endpoint", "code"}),
+		staleness: newStalenessCollector(),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.graphQLOperations,
+		m.graphQLDuration,
+		m.queryDuration,
+		m.refreshDuration,
+		m.refreshCountries,
+		m.upstreamDuration,
+		m.upstreamResponses,
+		m.staleness,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry returns the registry the metrics are registered in.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// RegisterDB exposes the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records an HTTP request served on route, the pattern the
+// request matched.
+func (m *Metrics) ObserveRequest(method, route string, code int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveOperation records a GraphQL operation of the given type selecting
+// the root field.
+func (m *Metrics) ObserveOperation(operationType, field string, failed bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	result := ResultOK
+	if failed {
+		result = ResultFailed
+	}
+	m.graphQLOperations.WithLabelValues(operationType, field, result).Inc()
+	m.graphQLDuration.WithLabelValues(operationType, field).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a database query made by the repository method.
+func (m *Metrics) ObserveQuery(query string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.queryDuration.WithLabelValues(query).Observe(duration.Seconds())
+}
+
+// ObserveRefresh records a run of the statistics refresher.
+func (m *Metrics) ObserveRefresh(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.refreshDuration.Observe(duration.Seconds())
+}
+
+// CountryRefreshed records the refresh of a country, ResultOK or
+// ResultFailed. A successful refresh resets the staleness of the country.
+func (m *Metrics) CountryRefreshed(country, result string) {
+	if m == nil {
+		return
+	}
+	m.refreshCountries.WithLabelValues(result).Inc()
+	if result == ResultOK {
+		m.staleness.updated(country, time.Now())
+	}
+}
+
+// CountryUpdated tells when the statistics of the country were last updated,
+// unless they are known to be more recent.
+func (m *Metrics) CountryUpdated(country string, updatedAt time.Time) {
+	if m == nil {
+		return
+	}
+	m.staleness.updated(country, updatedAt)
+}
+
+// ObserveUpstream records a request to the endpoint of the COVID-19 API, code
+// being 0 when it couldn't be reached.
+func (m *Metrics) ObserveUpstream(endpoint string, code int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	status := "error"
+	if code != 0 {
+		status = strconv.Itoa(code)
+	}
+	m.upstreamDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+	m.upstreamResponses.WithLabelValues(endpoint, status).Inc()
+}
+
+// stalenessCollector exposes the age of the statistics of each country,
+// computed when scraped.
+type stalenessCollector struct {
+	mu          sync.Mutex
+	lastUpdated map[string]time.Time
+	desc        *prometheus.Desc
+}
+
+func newStalenessCollector() *stalenessCollector {
+	return &stalenessCollector{
+		lastUpdated: make(map[string]time.Time),
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "country_data_staleness_seconds"),
+			"Time since the statistics of the country were last updated.",
+			[]string{"country"}, nil,
+		),
+	}
+}
+
+func (c *stalenessCollector) updated(country string, updatedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if updatedAt.After(c.lastUpdated[country]) {
+		c.lastUpdated[country] = updatedAt
+	}
+}
+
+func (c *stalenessCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.desc
+}
+
+func (c *stalenessCollector) Collect(metrics chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for country, updatedAt := range c.lastUpdated {
+		metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(updatedAt).Seconds(), country)
+	}
+}
@@ -0,0 +1,120 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveRequest(t *testing.T) {
+	// prapare data
+	m := metrics.New()
+
+	m.ObserveRequest(http.MethodGet, "/ratio/:name", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/ratio/:name", http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/ratio/:name", http.StatusNotFound, 10*time.Millisecond)
+
+	expected := `
+# HELP covid19_http_requests_total HTTP requests served, by method, route and status code.
+# TYPE covid19_http_requests_total counter
+covid19_http_requests_total{code="200",method="GET",route="/ratio/:name"} 2
+covid19_http_requests_total{code="404",method="GET",route="/ratio/:name"} 1
+`
+
+	// Test cases
+	if err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "covid19_http_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	// Test cases
+	if count, err := testutil.GatherAndCount(m.Registry(), "covid19_http_request_duration_seconds"); err != nil || count != 1 {
+		t.Errorf("expected a single latency histogram; got %d and %v", count, err)
+	}
+}
+
+func TestRefresherMetrics(t *testing.T) {
+	// prapare data
+	m := metrics.New()
+
+	m.CountryUpdated("Palestine", time.Now().Add(-48*time.Hour))
+	m.CountryUpdated("Jordan", time.Now().Add(-48*time.Hour))
+	m.CountryRefreshed("Palestine", metrics.ResultOK)
+	m.CountryRefreshed("Jordan", metrics.ResultFailed)
+	m.ObserveUpstream("total/country", http.StatusOK, time.Second)
+	m.ObserveUpstream("total/country", 0, time.Second)
+
+	expected := `
+# HELP covid19_refresher_countries_total Countries refreshed by the statistics refresher, by result.
+# TYPE covid19_refresher_countries_total counter
+covid19_refresher_countries_total{result="failed"} 1
+covid19_refresher_countries_total{result="ok"} 1
+# HELP covid19_upstream_responses_total Responses of the COVID-19 API, by endpoint and status code, error when unreachable.
+# TYPE covid19_upstream_responses_total counter
+covid19_upstream_responses_total{code="200",endpoint="total/country"} 1
+covid19_upstream_responses_total{code="error",endpoint="total/country"} 1
+`
+
+	// Test cases
+	if err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "covid19_refresher_countries_total", "covid19_upstream_responses_total"); err != nil {
+		t.Error(err)
+	}
+
+	// Test cases
+	families, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleness := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "covid19_country_data_staleness_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			staleness[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	if staleness["Palestine"] > 60 || staleness["Jordan"] < 47*3600 {
+		t.Errorf("expected Palestine to be fresh and Jordan two days old; got %v", staleness)
+	}
+}
+
+func TestGraphQLMetrics(t *testing.T) {
+	// prapare data
+	m := metrics.New()
+	h := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	h.Use(m.GraphQL())
+
+	request := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query": "{ __typename }"}`))
+	request.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), request)
+
+	expected := `
+# HELP covid19_graphql_operations_total GraphQL operations executed, by type, root field and result.
+# TYPE covid19_graphql_operations_total counter
+covid19_graphql_operations_total{field="__typename",result="ok",type="query"} 1
+`
+
+	// Test cases
+	if err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "covid19_graphql_operations_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	// prapare data
+	var m *metrics.Metrics
+
+	// Test cases
+	m.ObserveRequest(http.MethodGet, "/", http.StatusOK, time.Second)
+	m.ObserveQuery("UsersCountById", time.Second)
+	m.ObserveRefresh(time.Second)
+	m.CountryRefreshed("Palestine", metrics.ResultOK)
+	m.ObserveUpstream("health", http.StatusOK, time.Second)
+}
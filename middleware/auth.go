@@ -1,60 +1,132 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strconv"
 
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/export"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware accepts the requests holding a JWT token signed with secret.
+func AuthMiddleware(secret string) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		// Get the JWT token from the request header
 		tokenString := context.Request.Header.Get("Authorization")
 		if tokenString == "" {
-			context.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token not provided"})
+			context.JSON(http.StatusUnauthorized, entity.UserResponseFailure{Error: "Authorization token not provided", Code: graph.ErrorCodeUnauthenticated})
 			context.Abort()
 			return
 		}
 
-		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Return the secret key used to sign the token
-			return []byte(os.Getenv("TOKEN_SECRET")), nil
-		})
+		userID, err := ParseToken(secret, tokenString)
 		if err != nil {
-			context.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization token"})
+			context.JSON(http.StatusUnauthorized, entity.UserResponseFailure{Error: err.Error(), Code: graph.ErrorCodeUnauthenticated})
 			context.Abort()
 			return
 		}
 
-		// Check if the token is valid and not expired
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Get the user ID from the token
-			userID, err := strconv.Atoi(fmt.Sprintf("%.0f", claims["user_id"]))
-			if err != nil {
-				context.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in authorization token"})
-				context.Abort()
-				return
-			}
+		// Set the user ID in the request context
+		setUserID(context, userID)
 
-			// Set the user ID in the request context
-			context.Set("user_id", userID)
+		// Call the next middleware/handler in the chain
+		context.Next()
+	}
+}
+
+// QueryAuthMiddleware authenticates the GraphQL requests holding a JWT token
+// signed with secret, and the internal ones of the REST handlers on behalf of
+// their user. The requests without token are served anonymously, the
+// resolvers needing a user reading it with graph.UserIDFromContext.
+func QueryAuthMiddleware(secret string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if isInternal(context) {
+			if userID, err := strconv.Atoi(context.GetHeader(InternalUserHeader)); err == nil && userID > 0 {
+				setUserID(context, userID)
+			}
+			context.Next()
+			return
+		}
 
-			// Call the next middleware/handler in the chain
+		tokenString := context.Request.Header.Get("Authorization")
+		if tokenString == "" {
 			context.Next()
-		} else {
-			context.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization token"})
+			return
+		}
+		userID, err := ParseToken(secret, tokenString)
+		if err != nil {
+			context.JSON(http.StatusUnauthorized, entity.UserResponseFailure{Error: err.Error(), Code: graph.ErrorCodeUnauthenticated})
 			context.Abort()
 			return
 		}
+		setUserID(context, userID)
+		context.Next()
+	}
+}
 
+// ExportAuthMiddleware accepts the requests holding the token of an export
+// link made for their query, else the ones AuthMiddleware accepts.
+func ExportAuthMiddleware(secret string) gin.HandlerFunc {
+	authMiddleware := AuthMiddleware(secret)
+	return func(context *gin.Context) {
+		query := context.Request.URL.Query()
+		tokenString := query.Get(export.TokenParameter)
+		if tokenString == "" {
+			authMiddleware(context)
+			return
+		}
+
+		userID, err := export.ParseToken(secret, tokenString, query)
+		if err != nil {
+			context.JSON(http.StatusUnauthorized, entity.UserResponseFailure{Error: err.Error(), Code: graph.ErrorCodeUnauthenticated})
+			context.Abort()
+			return
+		}
+
+		setUserID(context, userID)
+		context.Next()
 	}
 }
 
+// ParseToken returns the user of the JWT token signed with secret.
+func ParseToken(secret, tokenString string) (int, error) {
+	// Parse and validate the token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Return the secret key used to sign the token
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return 0, errors.New("Invalid authorization token")
+	}
+
+	// Check if the token is valid and not expired
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, errors.New("Invalid authorization token")
+	}
+
+	// Get the user ID from the token
+	userID, err := strconv.Atoi(fmt.Sprintf("%.0f", claims["user_id"]))
+	if err != nil {
+		return 0, errors.New("Invalid user ID in authorization token")
+	}
+	return userID, nil
+}
+
+// setUserID authenticates the request as the user, both for the handlers and
+// for the GraphQL resolvers.
+func setUserID(context *gin.Context, userID int) {
+	context.Set("user_id", userID)
+	ctx := logger.AppendContext(context.Request.Context(), "user_id", userID)
+	context.Request = context.Request.WithContext(graph.ContextWithUserID(ctx, userID))
+}
+
 func GetUserID(context *gin.Context) int {
 	if userID, ok := context.Get("user_id"); ok {
 		return userID.(int)
@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+func query(headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/query", middleware.QueryAuthMiddleware("secret"), func(context *gin.Context) {
+		context.String(http.StatusOK, strconv.Itoa(graph.UserIDFromContext(context.Request.Context())))
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestQueryAuthMiddleware(t *testing.T) {
+	// prapare data
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 7}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	for name, test := range map[string]struct {
+		headers map[string]string
+		code    int
+		body    string
+	}{
+		"anonymous":     {nil, http.StatusOK, "0"},
+		"token":         {map[string]string{"Authorization": token}, http.StatusOK, "7"},
+		"invalid token": {map[string]string{"Authorization": "invalid"}, http.StatusUnauthorized, ""},
+		"internal":      {map[string]string{middleware.InternalRequestHeader: middleware.InternalToken(), middleware.InternalUserHeader: "9"}, http.StatusOK, "9"},
+		"forged":        {map[string]string{middleware.InternalRequestHeader: "forged", middleware.InternalUserHeader: "9"}, http.StatusOK, "0"},
+	} {
+		recorder := query(test.headers)
+		if recorder.Code != test.code || (test.body != "" && recorder.Body.String() != test.body) {
+			t.Errorf("%s: expected %d with user %q; got %d and %q", name, test.code, test.body, recorder.Code, recorder.Body.String())
+		}
+	}
+}
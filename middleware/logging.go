@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader holds the ID correlating the logs of a request. It is
+// taken from the request when given and set on the response.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogger adds the request ID and the route to the fields logged while
+// serving the request, then logs the request once served.
+func RequestLogger(log logger.Logger) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+		requestID := context.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = newRequestID()
+		}
+		context.Header(RequestIDHeader, requestID)
+		ctx := logger.AppendContext(context.Request.Context(), "request_id", requestID, "route", context.FullPath())
+		context.Request = context.Request.WithContext(ctx)
+
+		context.Next()
+
+		// the request context also holds the fields added by the handlers,
+		// such as the user ID
+		log.Info(context.Request.Context(), "request served",
+			"method", context.Request.Method,
+			"path", context.Request.URL.Path,
+			"status", context.Writer.Status(),
+			"duration", time.Since(start),
+		)
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
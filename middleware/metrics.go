@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels the requests matching no route, so that scanned
+// paths don't each get their own series.
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and the latency of the requests per route.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+
+		context.Next()
+
+		route := context.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(context.Request.Method, route, context.Writer.Status(), time.Since(start))
+	}
+}
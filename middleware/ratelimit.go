@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader holds the key of a client given its own limits.
+	APIKeyHeader = "X-API-Key"
+	// InternalRequestHeader marks the GraphQL queries the REST handlers send
+	// to the app itself, which were limited on their own route.
+	InternalRequestHeader = "X-Internal-Request"
+	// InternalUserHeader holds the user an internal request is sent on behalf
+	// of, it's only trusted along with InternalRequestHeader.
+	InternalUserHeader = "X-Internal-User-ID"
+)
+
+// internalToken authenticates the internal requests, it only lives as long
+// as the process.
+var internalToken = newInternalToken()
+
+func newInternalToken() string {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		panic(fmt.Sprintf("failed to generate the internal request token: %v", err))
+	}
+	return hex.EncodeToString(token)
+}
+
+// InternalToken returns the value of InternalRequestHeader of the internal
+// requests.
+func InternalToken() string {
+	return internalToken
+}
+
+// RateLimiter limits the requests of each client: the authenticated user,
+// else the known API key, else the client IP.
+type RateLimiter struct {
+	Store  ratelimit.Store
+	Logger logger.Logger
+	// apiKeys are the hashes of the known API keys, the unknown ones are
+	// ignored so that a client can't get a fresh bucket by making a key up.
+	apiKeys map[string]bool
+}
+
+func NewRateLimiter(store ratelimit.Store, apiKeys []string, logger logger.Logger) *RateLimiter {
+	rl := &RateLimiter{
+		Store:   store,
+		Logger:  logger,
+		apiKeys: make(map[string]bool, len(apiKeys)),
+	}
+	for _, apiKey := range apiKeys {
+		rl.apiKeys[hashKey(apiKey)] = true
+	}
+	return rl
+}
+
+// Limit returns the middleware applying limit to the routes of the policy,
+// each policy having its own buckets. It must come after AuthMiddleware for
+// the users to be told apart. The store failing lets the requests through.
+func (rl *RateLimiter) Limit(policy string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if limit.Disabled() || isInternal(context) {
+			context.Next()
+			return
+		}
+
+		result, err := rl.Store.Take(context.Request.Context(), policy+":"+rl.key(context), limit)
+		if err != nil {
+			rl.Logger.Error(context.Request.Context(), "rate limit failed", "policy", policy, "error", err)
+			context.Next()
+			return
+		}
+
+		context.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		context.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		context.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+		context.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Burst, ceilSeconds(limit.Period)))
+		if !result.Allowed {
+			rl.Logger.Warn(context.Request.Context(), "rate limited", "policy", policy)
+			context.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			context.JSON(http.StatusTooManyRequests, entity.UserResponseFailure{Error: "rate limit exceeded", Code: graph.ErrorCodeRateLimited})
+			context.Abort()
+			return
+		}
+		context.Next()
+	}
+}
+
+func (rl *RateLimiter) key(context *gin.Context) string {
+	if userID := GetUserID(context); userID != 0 {
+		return "user:" + strconv.Itoa(userID)
+	}
+	if apiKey := context.GetHeader(APIKeyHeader); apiKey != "" {
+		if hash := hashKey(apiKey); rl.apiKeys[hash] {
+			return "key:" + hash
+		}
+	}
+	return "ip:" + context.ClientIP()
+}
+
+func isInternal(context *gin.Context) bool {
+	token := context.GetHeader(InternalRequestHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(internalToken)) == 1
+}
+
+func hashKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func ceilSeconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
+	"github.com/FaresAbuIram/COVID19-Statistics/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(apiKeys []string, limit ratelimit.Limit) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	rateLimiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore(), apiKeys, logger.Discard())
+	router := gin.New()
+	router.POST("/login", rateLimiter.Limit("auth", limit), func(context *gin.Context) {
+		context.Status(http.StatusOK)
+	})
+	return router
+}
+
+func login(router *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/login", nil)
+	request.RemoteAddr = "10.0.0.1:4000"
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimiter(t *testing.T) {
+	// prapare data
+	router := newRouter(nil, ratelimit.Limit{Burst: 1, Period: time.Minute})
+
+	first := login(router, nil)
+	second := login(router, nil)
+
+	// Test cases
+	if first.Code != http.StatusOK || first.Header().Get("RateLimit-Limit") != "1" || first.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("expected the first request with its RateLimit headers; got %v and %v", first.Code, first.Header())
+	}
+
+	// Test cases
+	if second.Code != http.StatusTooManyRequests || second.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 429 with Retry-After 60; got %v and %v", second.Code, second.Header())
+	}
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	// prapare data
+	router := newRouter([]string{"partner-key"}, ratelimit.Limit{Burst: 1, Period: time.Minute})
+
+	login(router, nil)
+	known := login(router, map[string]string{middleware.APIKeyHeader: "partner-key"})
+	unknown := login(router, map[string]string{middleware.APIKeyHeader: "made-up-key"})
+	internal := login(router, map[string]string{middleware.InternalRequestHeader: middleware.InternalToken()})
+	forged := login(router, map[string]string{middleware.InternalRequestHeader: "forged"})
+
+	// Test cases
+	if known.Code != http.StatusOK {
+		t.Errorf("expected the known API key to have its own bucket; got %v", known.Code)
+	}
+
+	// Test cases
+	if unknown.Code != http.StatusTooManyRequests || forged.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the unknown key and the forged token to share the IP bucket; got %v and %v", unknown.Code, forged.Code)
+	}
+
+	// Test cases
+	if internal.Code != http.StatusOK || internal.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("expected the internal request not to be limited; got %v", internal.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ChatChannel posts the digests to Slack or Microsoft Teams incoming
+// webhooks, which both take a {"text": ...} JSON body.
+type ChatChannel struct {
+	// Client posts to the URLs given by the users, such as the one of
+	// webhooks.NewClient that only reaches public addresses.
+	Client *http.Client
+}
+
+func NewChatChannel(client *http.Client) *ChatChannel {
+	return &ChatChannel{Client: client}
+}
+
+// Send posts the digest to the destination URL of the incoming webhook.
+func (c *ChatChannel) Send(ctx context.Context, destination string, digest Digest) error {
+	text, err := Render("chat", digest)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, destination, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(request)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			// without the URL, whose path is the secret of the webhook
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("chat webhook answered %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
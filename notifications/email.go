@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig tells how the emails are sent, no Addr disabling them.
+type SMTPConfig struct {
+	// Addr is the host and port of the server, such as smtp.example.com:587.
+	// STARTTLS is used when the server offers it.
+	Addr string
+	// Username and Password authenticate with PLAIN when Username is set.
+	Username string
+	Password string
+	// From is the sender of the emails.
+	From string
+}
+
+// EmailChannel sends the digests as plain text emails.
+type EmailChannel struct {
+	config   SMTPConfig
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmailChannel(config SMTPConfig) *EmailChannel {
+	return &EmailChannel{config: config, sendMail: smtp.SendMail}
+}
+
+// Send emails the digest to the destination address. The SMTP exchange can't
+// be cancelled, ctx is only checked before it starts.
+func (e *EmailChannel) Send(ctx context.Context, destination string, digest Digest) error {
+	to, err := mail.ParseAddress(destination)
+	if err != nil {
+		return fmt.Errorf("invalid email address %s: %w", destination, err)
+	}
+	subject, err := Render("subject", digest)
+	if err != nil {
+		return err
+	}
+	body, err := Render("email", digest)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var message strings.Builder
+	message.WriteString("From: " + e.config.From + "\r\n")
+	message.WriteString("To: " + to.String() + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	message.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n") + "\r\n")
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		host, _, _ := net.SplitHostPort(e.config.Addr)
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, host)
+	}
+	return e.sendMail(e.config.Addr, auth, e.config.From, []string{to.Address}, []byte(message.String()))
+}
@@ -0,0 +1,131 @@
+package notifications
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Frequencies of the digests.
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+)
+
+// Digest summarizes the countries followed by a user over a period.
+type Digest struct {
+	// Frequency is FrequencyDaily or FrequencyWeekly.
+	Frequency string
+	From      time.Time
+	To        time.Time
+	Countries []CountryDigest
+	// TopMovers are the countries whose confirmed cases grew the most, the
+	// fastest first.
+	TopMovers []CountryDigest
+}
+
+// CountryDigest holds the totals of a country and how they changed over the
+// period, the changes being nil without enough history.
+type CountryDigest struct {
+	Country      string
+	Confirmed    int
+	Deaths       int
+	Recovered    int
+	NewConfirmed *int
+	NewDeaths    *int
+	// Growth is NewConfirmed in percent of the confirmed cases at the start
+	// of the period.
+	Growth *float64
+}
+
+// Channel sends the digests to a destination, such as an email address or a
+// URL, in its own format.
+type Channel interface {
+	Send(ctx context.Context, destination string, digest Digest) error
+}
+
+// Config tells when the digests are sent and through which SMTP server.
+type Config struct {
+	// Hour is the UTC hour from which the digests of the day are sent, the
+	// weekly ones going out on Mondays.
+	Hour int
+	// CheckInterval is how often the due digests are looked up.
+	CheckInterval time.Duration
+	SMTP          SMTPConfig
+}
+
+// Validate checks the settings.
+func (c Config) Validate() error {
+	if c.Hour < 0 || c.Hour > 23 {
+		return fmt.Errorf("invalid DIGEST_HOUR %d, expected a number between 0 and 23", c.Hour)
+	}
+	if c.CheckInterval <= 0 {
+		return fmt.Errorf("invalid DIGEST_CHECK_INTERVAL %s, expected a positive duration", c.CheckInterval)
+	}
+	if c.SMTP.Addr == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.SMTP.Addr); err != nil {
+		return fmt.Errorf("invalid SMTP_ADDR %s, expected a host and a port such as smtp.example.com:587", c.SMTP.Addr)
+	}
+	if c.SMTP.From == "" {
+		return fmt.Errorf("SMTP_FROM is required along with SMTP_ADDR")
+	}
+	return nil
+}
+
+//go:embed templates/*.tmpl
+var files embed.FS
+
+var templates = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date":    func(t time.Time) string { return t.UTC().Format("2006-01-02") },
+	"number":  formatNumber,
+	"delta":   formatDelta,
+	"percent": formatPercent,
+	"inc":     func(i int) int { return i + 1 },
+}).ParseFS(files, "templates/*.tmpl"))
+
+// Render executes the template of the digest named name, subject, email or
+// chat.
+func Render(name string, digest Digest) (string, error) {
+	var text strings.Builder
+	if err := templates.ExecuteTemplate(&text, name, digest); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// formatNumber separates the thousands, 1,234,567.
+func formatNumber(number int) string {
+	digits := strconv.Itoa(number)
+	sign := ""
+	if number < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + digits
+}
+
+func formatDelta(delta *int) string {
+	if delta == nil {
+		return "n/a"
+	}
+	if *delta < 0 {
+		return formatNumber(*delta)
+	}
+	return "+" + formatNumber(*delta)
+}
+
+func formatPercent(percent *float64) string {
+	if percent == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", *percent)
+}
@@ -0,0 +1,118 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDigest() Digest {
+	newConfirmed, newDeaths, growth := 1234, 0, 12.5
+	palestine := CountryDigest{Country: "Palestine", Confirmed: 703228, Deaths: 5708, Recovered: 0, NewConfirmed: &newConfirmed, NewDeaths: &newDeaths, Growth: &growth}
+	return Digest{
+		Frequency: FrequencyWeekly,
+		From:      time.Date(2023, 4, 24, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		Countries: []CountryDigest{palestine, {Country: "Jordan", Confirmed: 10}},
+		TopMovers: []CountryDigest{palestine},
+	}
+}
+
+func TestRender(t *testing.T) {
+	// prapare data
+	digest := testDigest()
+
+	subject, err := Render("subject", digest)
+
+	// Test cases
+	if err != nil || subject != "Your weekly COVID-19 digest, 2023-05-01" {
+		t.Errorf("expected the subject; got %q and %v", subject, err)
+	}
+
+	email, err := Render("email", digest)
+
+	// Test cases
+	if err != nil || !strings.Contains(email, "Confirmed: 703,228 (+1,234)") || !strings.Contains(email, "Confirmed: 10 (n/a)") ||
+		!strings.Contains(email, "1. Palestine: +12.5% confirmed cases (+1,234)") {
+		t.Errorf("expected the totals, the deltas and the top movers; got %q and %v", email, err)
+	}
+
+	chat, err := Render("chat", Digest{Frequency: FrequencyDaily})
+
+	// Test cases
+	if err != nil || strings.Contains(chat, "Top movers") {
+		t.Errorf("expected no top movers without countries; got %q and %v", chat, err)
+	}
+}
+
+func TestEmailChannel(t *testing.T) {
+	// prapare data
+	channel := NewEmailChannel(SMTPConfig{Addr: "smtp.example.com:587", Username: "user", Password: "pass", From: "digest@example.com"})
+	var sentTo []string
+	var sent string
+	var auth smtp.Auth
+	channel.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentTo, sent, auth = to, string(msg), a
+		return nil
+	}
+
+	err := channel.Send(context.Background(), "Test <test@test.com>", testDigest())
+
+	// Test cases
+	if err != nil || len(sentTo) != 1 || sentTo[0] != "test@test.com" || auth == nil {
+		t.Errorf("expected an authenticated email to test@test.com; got %v, %v and %v", sentTo, auth, err)
+	}
+
+	// Test cases
+	if !strings.Contains(sent, "Subject: Your weekly COVID-19 digest, 2023-05-01\r\n") || !strings.Contains(sent, "\r\n\r\nHello,\r\n") {
+		t.Errorf("expected the headers then the body; got %q", sent)
+	}
+
+	err = channel.Send(context.Background(), "test@test.com\r\nBcc: other@test.com", testDigest())
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected invalid email address error; got nil")
+	}
+}
+
+func TestChatChannel(t *testing.T) {
+	// prapare data
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		json.Unmarshal(content, &body)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	channel := NewChatChannel(server.Client())
+
+	err := channel.Send(context.Background(), server.URL+"/hook", testDigest())
+
+	// Test cases
+	if err != nil || !strings.HasPrefix(body["text"], "*Your weekly COVID-19 digest* (2023-04-24 to 2023-05-01)") || !strings.Contains(body["text"], "*Top movers:* Palestine +12.5%") {
+		t.Errorf("expected the digest as text; got %v and %v", body, err)
+	}
+
+	// Test cases
+	if err := channel.Send(context.Background(), server.URL+"/broken", testDigest()); err == nil || err.Error() != "chat webhook answered 404 Not Found" {
+		t.Errorf("expected the status to be reported; got %v", err)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	// Test cases
+	for number, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 703228: "703,228", -1234567: "-1,234,567"} {
+		if got := formatNumber(number); got != want {
+			t.Errorf("expected %s; got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit lets Burst requests through at once, then Burst requests per Period.
+// The zero Limit lets everything through.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Disabled tells whether the limit lets everything through.
+func (l Limit) Disabled() bool {
+	return l.Burst <= 0 || l.Period <= 0
+}
+
+// String formats the limit as ParseLimit reads it.
+func (l Limit) String() string {
+	if l.Disabled() {
+		return "off"
+	}
+	switch l.Period {
+	case time.Second:
+		return fmt.Sprintf("%d/s", l.Burst)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", l.Burst)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", l.Burst)
+	}
+	return fmt.Sprintf("%d/%s", l.Burst, l.Period)
+}
+
+// rate returns the tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// ParseLimit reads a limit such as 10/m, a number of requests per s, m, h or
+// per duration such as 30s. off disables the limit.
+func ParseLimit(value string) (Limit, error) {
+	if strings.EqualFold(value, "off") {
+		return Limit{}, nil
+	}
+	count, period, ok := strings.Cut(value, "/")
+	burst, err := strconv.Atoi(count)
+	if !ok || err != nil || burst < 1 {
+		return Limit{}, fmt.Errorf("invalid rate limit %s, expected a number of requests such as 10/m or off", value)
+	}
+	var duration time.Duration
+	switch period {
+	case "s":
+		duration = time.Second
+	case "m":
+		duration = time.Minute
+	case "h":
+		duration = time.Hour
+	default:
+		duration, err = time.ParseDuration(period)
+		if err != nil || duration <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %s, expected a period such as s, m, h or 30s", value)
+		}
+	}
+	return Limit{Burst: burst, Period: duration}, nil
+}
+
+// Result tells whether a request was let through and how the bucket it took
+// from stands.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until a request would be let through, zero when
+	// allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps a token bucket per key. A shared implementation lets several
+// instances of the app enforce the limits together.
+type Store interface {
+	// Take takes a token from the bucket of key, created full if missing.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// MemoryStore is the Store of a single instance, the buckets left untouched
+// long enough to be full again are dropped.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// sweepInterval is how often the full buckets are dropped.
+const sweepInterval = time.Minute
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Disabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+
+	rate := limit.rate()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	result := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((float64(limit.Burst) - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is moved forward by the tests.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	// prapare data
+	clock := &clock{now: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	limit := Limit{Burst: 2, Period: time.Minute}
+
+	first, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+	second, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+	third, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+	other, _ := store.Take(context.Background(), "ip:10.0.0.2", limit)
+
+	// Test cases
+	if !first.Allowed || first.Remaining != 1 || !second.Allowed || second.Remaining != 0 {
+		t.Errorf("expected the burst to be let through; got %+v and %+v", first, second)
+	}
+
+	// Test cases
+	if third.Allowed || third.RetryAfter != 30*time.Second || third.Reset != time.Minute {
+		t.Errorf("expected to retry after 30s; got %+v", third)
+	}
+
+	// Test cases
+	if !other.Allowed {
+		t.Errorf("expected the other key to have its own bucket; got %+v", other)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	fourth, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+
+	// Test cases
+	if !fourth.Allowed || fourth.Remaining != 0 {
+		t.Errorf("expected a token to be refilled after 30s; got %+v", fourth)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	// prapare data
+	clock := &clock{now: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	limit := Limit{Burst: 10, Period: time.Minute}
+
+	store.Take(context.Background(), "ip:10.0.0.1", limit)
+	clock.now = clock.now.Add(2 * time.Minute)
+	store.Take(context.Background(), "ip:10.0.0.2", limit)
+
+	// Test cases
+	if _, ok := store.buckets["ip:10.0.0.1"]; ok || len(store.buckets) != 1 {
+		t.Errorf("expected the full bucket to be dropped; got %v", store.buckets)
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	// Test cases
+	for value, expected := range map[string]Limit{
+		"10/m":   {Burst: 10, Period: time.Minute},
+		"5/s":    {Burst: 5, Period: time.Second},
+		"100/h":  {Burst: 100, Period: time.Hour},
+		"20/30s": {Burst: 20, Period: 30 * time.Second},
+		"off":    {},
+	} {
+		limit, err := ParseLimit(value)
+		if err != nil || limit != expected {
+			t.Errorf("expected %v for %s; got %v and %v", expected, value, limit, err)
+		}
+	}
+}
+
+func TestNegativeParseLimit(t *testing.T) {
+	// Test cases
+	for _, value := range []string{"10", "ten/m", "0/m", "10/day", "10/-1s"} {
+		if _, err := ParseLimit(value); err == nil {
+			t.Errorf("expected invalid rate limit error for %s; got nil", value)
+		}
+	}
+}
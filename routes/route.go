@@ -1,53 +1,160 @@
 package routes
 
 import (
+	"context"
+	"errors"
 	"log"
 
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/FaresAbuIram/COVID19-Statistics/config"
 	"github.com/FaresAbuIram/COVID19-Statistics/controllers"
 	"github.com/FaresAbuIram/COVID19-Statistics/database"
 	docs "github.com/FaresAbuIram/COVID19-Statistics/docs"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/lifecycle"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
 	"github.com/FaresAbuIram/COVID19-Statistics/middleware"
+	"github.com/FaresAbuIram/COVID19-Statistics/notifications"
+	"github.com/FaresAbuIram/COVID19-Statistics/ratelimit"
 	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func Setup(router *gin.Engine) {
+// Setup connects to the database and registers the routes. Closing the
+// database and the background refresher, webhook deliverer and digest sender
+// are left to the manager, ctx bounds the connection and ends the event
+// streams once done.
+func Setup(ctx context.Context, router *gin.Engine, config *config.Config, manager *lifecycle.Manager, logger logger.Logger, metrics *metrics.Metrics) {
 	docs.SwaggerInfo.Title = "Swagger Example API"
 	docs.SwaggerInfo.Description = "This is a sample server Petstore server."
 	docs.SwaggerInfo.Version = "2.0"
-	docs.SwaggerInfo.Host = "localhost:8080"
+	docs.SwaggerInfo.Host = config.SwaggerHost
 	docs.SwaggerInfo.Schemes = []string{"http"}
 
-	db, err := database.Connect()
+	db, err := database.Connect(ctx, config.Database, logger)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 		return
 	}
+	metrics.RegisterDB(db, config.Database.Driver)
+	manager.Append(lifecycle.Hook{
+		Name: "database",
+		Stop: func(context.Context) error { return db.Close() },
+	})
+	if config.AutoMigrate {
+		count, err := database.MigrateUp(db, config.Database.Driver)
+		if err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+			return
+		}
+		logger.Info(ctx, "database migrated", "migrations", count)
+	}
 	sqlRepository := database.NewSQLRepository(db)
-	logger := logger.NewLoggerCollection()
-	userService := services.NewUserService(sqlRepository, *logger)
-	covid19Service := services.NewCovid19Service(sqlRepository, *logger)
+	sqlRepository.QueryTimeout = config.Database.QueryTimeout
+	sqlRepository.Metrics = metrics
+	userService := services.NewUserService(sqlRepository, logger)
+	userService.TokenSecret = config.TokenSecret
+	covid19Service := services.NewCovid19Service(sqlRepository, logger)
+	covid19Service.Metrics = metrics
+	covid19Service.Events = events.NewBus(events.DefaultReplay)
+	covid19Service.Webhooks = config.Webhooks
+	covid19Service.Digests = config.Digests
+	covid19Service.Exports = config.Exports
+	covid19Service.TokenSecret = config.TokenSecret
+	covid19Service.AdminUserIds = config.AdminUserIds
+	covid19Service.DigestChannels = map[model.DigestChannel]notifications.Channel{
+		model.DigestChannelChat: notifications.NewChatChannel(webhooks.NewClient(config.Webhooks.Timeout)),
+	}
+	if config.Digests.SMTP.Addr != "" {
+		covid19Service.DigestChannels[model.DigestChannelEmail] = notifications.NewEmailChannel(config.Digests.SMTP)
+	}
 	resolver := &graph.Resolver{UserService: userService, Covid19Service: covid19Service}
-	userController := controllers.NewUserController(resolver, *logger)
-	covid19Controller := controllers.NewCovid19Controller(resolver, *logger)
+	config.GraphQL.WebsocketInit = websocketAuth(config.TokenSecret)
+	config.GraphQL.Logger = logger
+	server := graph.NewServer(resolver, config.GraphQL, metrics.GraphQL(), tracing.GraphQL())
+	userController := controllers.NewUserController(resolver, server, logger, config.GraphQLURL)
+	covid19Controller := controllers.NewCovid19Controller(resolver, logger, config.GraphQLURL)
+	authMiddleware := middleware.AuthMiddleware(config.TokenSecret)
+	queryAuth := middleware.QueryAuthMiddleware(config.TokenSecret)
+	rateLimiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore(), config.RateLimit.APIKeys, logger)
+	authLimit := rateLimiter.Limit("auth", config.RateLimit.Auth)
+	queryLimit := rateLimiter.Limit("query", config.RateLimit.Query)
+	apiLimit := rateLimiter.Limit("api", config.RateLimit.API)
+	healthService := services.NewHealthService(db, services.Covid19APIURL, logger)
+	healthService.Metrics = metrics
+	healthController := controllers.NewHealthController(healthService, logger)
+	eventsController := controllers.NewEventsController(covid19Service, logger)
+	eventsController.Done = ctx.Done()
 
-	go covid19Service.GetDailyTotals()
+	manager.Append(lifecycle.Worker("statistics refresher", covid19Service.GetDailyTotals))
+	manager.Append(lifecycle.Worker("webhook deliverer", covid19Service.DeliverWebhooks))
+	manager.Append(lifecycle.Worker("digest sender", covid19Service.SendDigests))
 	router.Use(static.Serve("/", static.LocalFile("./website/dist", true)))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	router.POST("/query", userController.Query)
-	router.GET("/", gin.WrapH(playground.Handler("GraphQL playground", "/query")))
-	router.POST("/register", userController.Register)
-	router.POST("/login", userController.Login)
-	router.POST("/country", middleware.AuthMiddleware(), covid19Controller.AddNewCountry)
-	router.GET("/all-countries", middleware.AuthMiddleware(), covid19Controller.GetCountries)
-	router.GET("/percentage-of-death-to-confirmed/:name", middleware.AuthMiddleware(), covid19Controller.PercentageOfDeathToConfirmed)
-	router.GET("/top-three-countries/:type", middleware.AuthMiddleware(), covid19Controller.GetTopThreeCountries)
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.POST("/query", queryAuth, queryLimit, userController.Query)
+	router.GET("/query", queryAuth, queryLimit, userController.Query)
+	if !config.Production() {
+		router.GET("/", gin.WrapH(playground.Handler("GraphQL playground", "/query")))
+	}
+	router.POST("/register", authLimit, userController.Register)
+	router.POST("/login", authLimit, userController.Login)
+	router.POST("/country", authMiddleware, apiLimit, covid19Controller.AddNewCountry)
+	router.GET("/all-countries", authMiddleware, apiLimit, covid19Controller.GetCountries)
+	router.GET("/percentage-of-death-to-confirmed/:name", authMiddleware, apiLimit, covid19Controller.PercentageOfDeathToConfirmed)
+	router.GET("/ratio/:name", authMiddleware, apiLimit, covid19Controller.GetRatio)
+	router.GET("/top-three-countries/:type", authMiddleware, apiLimit, covid19Controller.GetTopThreeCountries)
+	router.GET("/indicators/:name", authMiddleware, apiLimit, covid19Controller.GetIndicators)
+	router.GET("/regions", authMiddleware, apiLimit, covid19Controller.GetRegions)
+	router.POST("/regions", authMiddleware, apiLimit, covid19Controller.CreateRegion)
+	router.POST("/regions/:region/countries", authMiddleware, apiLimit, covid19Controller.AddCountryToRegion)
+	router.GET("/regions/:region/aggregate", authMiddleware, apiLimit, covid19Controller.GetRegionAggregate)
+	router.GET("/compare", authMiddleware, apiLimit, covid19Controller.Compare)
+	router.POST("/subdivision", authMiddleware, apiLimit, covid19Controller.AddSubdivision)
+	router.GET("/all-subdivisions", authMiddleware, apiLimit, covid19Controller.GetSubdivisions)
+	router.GET("/subdivisions/:name", authMiddleware, apiLimit, covid19Controller.GetCountrySubdivisions)
+	router.PUT("/subdivisions/:name/rollup", authMiddleware, apiLimit, covid19Controller.SetSubdivisionRollup)
+	router.GET("/alert-rules", authMiddleware, apiLimit, covid19Controller.GetAlertRules)
+	router.POST("/alert-rules", authMiddleware, apiLimit, covid19Controller.CreateAlertRule)
+	router.DELETE("/alert-rules/:id", authMiddleware, apiLimit, covid19Controller.DeleteAlertRule)
+	router.GET("/alerts", authMiddleware, apiLimit, covid19Controller.GetAlerts)
+	router.POST("/alerts/:id/acknowledge", authMiddleware, apiLimit, covid19Controller.AcknowledgeAlert)
+	router.GET("/webhooks", authMiddleware, apiLimit, covid19Controller.GetWebhooks)
+	router.POST("/webhooks", authMiddleware, apiLimit, covid19Controller.CreateWebhook)
+	router.DELETE("/webhooks/:id", authMiddleware, apiLimit, covid19Controller.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", authMiddleware, apiLimit, covid19Controller.GetWebhookDeliveries)
+	router.POST("/webhook-deliveries/:id/redeliver", authMiddleware, apiLimit, covid19Controller.RedeliverWebhook)
+	router.GET("/digest-preferences", authMiddleware, apiLimit, covid19Controller.GetDigestPreferences)
+	router.PUT("/digest-preferences/:channel", authMiddleware, apiLimit, covid19Controller.SetDigestPreference)
+	router.GET("/export", middleware.ExportAuthMiddleware(config.TokenSecret), apiLimit, covid19Controller.Export)
+	router.GET("/events", authMiddleware, apiLimit, eventsController.Stream)
 
 }
+
+// websocketAuth accepts the websocket clients sending the JWT token in the
+// Authorization field of their connection_init payload.
+func websocketAuth(secret string) transport.WebsocketInitFunc {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, error) {
+		tokenString := initPayload.Authorization()
+		if tokenString == "" {
+			return nil, errors.New("Authorization token not provided")
+		}
+		userID, err := middleware.ParseToken(secret, tokenString)
+		if err != nil {
+			return nil, err
+		}
+		return graph.ContextWithUserID(logger.AppendContext(ctx, "user_id", userID), userID), nil
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+// CreateAlertRule adds a rule on a country the user is subscribed to. The
+// rule raises an alert each time its condition starts to hold after a
+// refresh, not while it keeps holding.
+func (c *Covid19Service) CreateAlertRule(ctx context.Context, userId int, countryName string, metric model.AlertMetric, operator model.AlertOperator, threshold float64) (*model.AlertRule, error) {
+	c.Logger.Debug(ctx, "CreateAlertRule called")
+
+	if !metric.IsValid() || !operator.IsValid() {
+		c.Logger.Warn(ctx, "invalid alert rule", "metric", metric, "operator", operator)
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid alert rule %s %s", metric, operator)}
+	}
+
+	countryId, err := c.SQLRepository.GetCountryIdByName(ctx, countryName)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "country", Name: countryName}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "CreateAlertRule failed", "error", err)
+		return nil, err
+	}
+
+	count, err := c.SQLRepository.UsersCountriesCount(ctx, userId, countryId)
+	if err != nil {
+		c.Logger.Error(ctx, "CreateAlertRule failed", "error", err)
+		return nil, err
+	}
+
+	if count == 0 {
+		err = &ForbiddenError{Message: fmt.Sprintf("user with id %d isn't subscribed to %s", userId, countryName)}
+		c.Logger.Error(ctx, "CreateAlertRule failed", "error", err)
+		return nil, err
+	}
+
+	rule := entity.AlertRule{UserId: userId, CountryId: countryId, Country: countryName, Metric: string(metric), Operator: string(operator), Threshold: threshold}
+	rule.ID, err = c.SQLRepository.InsertAlertRule(ctx, rule)
+	if errors.Is(err, database.ErrConflict) {
+		err = &ConflictError{Message: fmt.Sprintf("user with id %d already has the rule %s %s %v on %s", userId, metric, operator, threshold, countryName)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "CreateAlertRule failed", "error", err)
+		return nil, err
+	}
+
+	return alertRuleModel(rule), nil
+}
+
+func (c *Covid19Service) GetAlertRules(ctx context.Context, userId int) ([]*model.AlertRule, error) {
+	c.Logger.Debug(ctx, "GetAlertRules called")
+
+	rules, err := c.SQLRepository.GetAlertRulesByUserId(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "GetAlertRules failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]*model.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, alertRuleModel(rule))
+	}
+	return result, nil
+}
+
+// DeleteAlertRule deletes the rule of the user along with its alerts.
+func (c *Covid19Service) DeleteAlertRule(ctx context.Context, userId, ruleId int) (bool, error) {
+	c.Logger.Debug(ctx, "DeleteAlertRule called")
+
+	count, err := c.SQLRepository.DeleteAlertRule(ctx, userId, ruleId)
+	if err == nil && count == 0 {
+		err = &NotFoundError{Resource: "alert rule with id", Name: strconv.Itoa(ruleId)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "DeleteAlertRule failed", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// GetAlerts returns the alert history of the user, the latest first.
+func (c *Covid19Service) GetAlerts(ctx context.Context, userId int, unacknowledgedOnly bool) ([]*model.Alert, error) {
+	c.Logger.Debug(ctx, "GetAlerts called")
+
+	alerts, err := c.SQLRepository.GetAlertsByUserId(ctx, userId, unacknowledgedOnly)
+	if err != nil {
+		c.Logger.Error(ctx, "GetAlerts failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]*model.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		result = append(result, alertModel(alert))
+	}
+	return result, nil
+}
+
+// AcknowledgeAlert marks the alert of the user as seen, acknowledging it
+// again keeps the first time.
+func (c *Covid19Service) AcknowledgeAlert(ctx context.Context, userId, alertId int) (bool, error) {
+	c.Logger.Debug(ctx, "AcknowledgeAlert called")
+
+	count, err := c.SQLRepository.AcknowledgeAlert(ctx, userId, alertId, time.Now())
+	if err == nil && count == 0 {
+		err = &NotFoundError{Resource: "alert with id", Name: strconv.Itoa(alertId)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "AcknowledgeAlert failed", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// EvaluateAlertRules checks every rule against the latest daily snapshots,
+// the refresher runs it once the statistics are stored. A rule whose
+// condition starts to hold raises an alert, which is kept and published; the
+// ones lacking the history their metric needs are skipped.
+func (c *Covid19Service) EvaluateAlertRules(ctx context.Context) {
+	rules, err := c.SQLRepository.GetAllAlertRules(ctx)
+	if err != nil {
+		c.Logger.Error(ctx, "EvaluateAlertRules failed", "error", err)
+		return
+	}
+
+	now := time.Now()
+	values := make(map[int]map[model.AlertMetric]*float64)
+	for _, rule := range rules {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, ok := values[rule.CountryId]; !ok {
+			values[rule.CountryId] = c.alertValues(ctx, rule.Country)
+		}
+		value := values[rule.CountryId][model.AlertMetric(rule.Metric)]
+		if value == nil {
+			continue
+		}
+
+		holds := *value > rule.Threshold
+		if model.AlertOperator(rule.Operator) == model.AlertOperatorBelow {
+			holds = *value < rule.Threshold
+		}
+		if holds == rule.Triggered {
+			continue
+		}
+		if err := c.SQLRepository.UpdateAlertRuleTriggered(ctx, rule.ID, holds); err != nil {
+			c.Logger.Error(ctx, "EvaluateAlertRules failed", "error", err)
+			continue
+		}
+		if !holds {
+			continue
+		}
+
+		alert := entity.Alert{RuleId: rule.ID, Value: *value, TriggeredAt: now}
+		alert.ID, err = c.SQLRepository.InsertAlert(ctx, alert)
+		if err != nil {
+			c.Logger.Error(ctx, "EvaluateAlertRules failed", "error", err)
+			continue
+		}
+		c.Logger.Info(ctx, "alert raised", "alert_id", alert.ID, "rule_id", rule.ID, "user_id", rule.UserId, "value", *value)
+		if dropped := c.Events.Publish(events.Event{
+			Type:    events.TypeThresholdAlert,
+			Country: rule.Country,
+			UserID:  rule.UserId,
+			Data: events.ThresholdAlert{
+				AlertID:     alert.ID,
+				RuleID:      rule.ID,
+				Country:     rule.Country,
+				Metric:      rule.Metric,
+				Operator:    rule.Operator,
+				Threshold:   rule.Threshold,
+				Value:       *value,
+				TriggeredAt: now,
+			},
+		}); dropped > 0 {
+			c.Logger.Warn(ctx, "slow subscribers dropped", "dropped", dropped)
+		}
+	}
+}
+
+// alertValues computes the metrics of the rules from the latest daily
+// snapshots of the country, a metric is nil when there isn't enough history.
+func (c *Covid19Service) alertValues(ctx context.Context, countryName string) map[model.AlertMetric]*float64 {
+	values := make(map[model.AlertMetric]*float64)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	statistics, err := c.SQLRepository.GetDailyStatisticsByCountryName(ctx, countryName, today.AddDate(0, 0, -2*indicatorsWindow), today)
+	if err != nil {
+		c.Logger.Error(ctx, "alertValues failed", "error", err)
+		return values
+	}
+	if len(statistics) == 0 {
+		return values
+	}
+
+	indicators := computeIndicators(statistics)
+	latest := indicators[len(indicators)-1]
+	if len(indicators) > 1 {
+		values[model.AlertMetricNewCases] = float64Ptr(float64(latest.NewCases))
+	}
+	values[model.AlertMetricWeeklyGrowth] = latest.WeekOverWeekGrowth
+	if latest.Confirmed > 0 {
+		values[model.AlertMetricCaseFatalityRate] = float64Ptr(float64(latest.Deaths) / float64(latest.Confirmed) * 100)
+	}
+	return values
+}
+
+func alertRuleModel(rule entity.AlertRule) *model.AlertRule {
+	return &model.AlertRule{
+		ID:        rule.ID,
+		Country:   rule.Country,
+		Metric:    model.AlertMetric(rule.Metric),
+		Operator:  model.AlertOperator(rule.Operator),
+		Threshold: rule.Threshold,
+		Triggered: rule.Triggered,
+	}
+}
+
+func alertModel(alert entity.Alert) *model.Alert {
+	result := &model.Alert{
+		ID:          alert.ID,
+		RuleID:      alert.RuleId,
+		Country:     alert.Country,
+		Metric:      model.AlertMetric(alert.Metric),
+		Operator:    model.AlertOperator(alert.Operator),
+		Threshold:   alert.Threshold,
+		Value:       alert.Value,
+		TriggeredAt: alert.TriggeredAt.UTC().Format(time.RFC3339),
+	}
+	if alert.AcknowledgedAt != nil {
+		acknowledgedAt := alert.AcknowledgedAt.UTC().Format(time.RFC3339)
+		result.AcknowledgedAt = &acknowledgedAt
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+)
+
+func TestEvaluateAlertRules(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, memoryRepository, userId := newMemoryTestService(t, "Palestine")
+	covid19Service.Events = events.NewBus(events.DefaultReplay)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: 1, Date: today.AddDate(0, 0, -1), Confirmed: 100, Deaths: 10})
+	memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: 1, Date: today, Confirmed: 200, Deaths: 30})
+
+	newCasesRule, err := covid19Service.CreateAlertRule(ctx, userId, "Palestine", model.AlertMetricNewCases, model.AlertOperatorAbove, 50)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if _, err := covid19Service.CreateAlertRule(ctx, userId, "Palestine", model.AlertMetricCaseFatalityRate, model.AlertOperatorBelow, 10); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	if _, err := covid19Service.CreateAlertRule(ctx, userId, "Palestine", model.AlertMetricWeeklyGrowth, model.AlertOperatorAbove, 0); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	published, err := covid19Service.SubscribeEvents(ctx, userId, nil)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	covid19Service.EvaluateAlertRules(ctx)
+	covid19Service.EvaluateAlertRules(ctx)
+
+	alerts, err := covid19Service.GetAlerts(ctx, userId, false)
+
+	// Test cases
+	if err != nil || len(alerts) != 1 || alerts[0].RuleID != newCasesRule.ID || alerts[0].Value != 100 {
+		t.Errorf("expected a single alert for the 100 new cases; got %v and %v", alerts, err)
+	}
+
+	// Test cases
+	if event := <-published; event.Type != events.TypeThresholdAlert || event.Data.(events.ThresholdAlert).Value != 100 {
+		t.Errorf("expected the alert to be published; got %+v", event)
+	}
+
+	rules, err := covid19Service.GetAlertRules(ctx, userId)
+
+	// Test cases
+	if err != nil || len(rules) != 3 || !rules[0].Triggered || rules[1].Triggered || rules[2].Triggered {
+		t.Errorf("expected the new cases rule only to be triggered; got %v and %v", rules, err)
+	}
+
+	memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: 1, Date: today, Confirmed: 120, Deaths: 30})
+	covid19Service.EvaluateAlertRules(ctx)
+	memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: 1, Date: today, Confirmed: 300, Deaths: 30})
+	covid19Service.EvaluateAlertRules(ctx)
+
+	ok, err := covid19Service.AcknowledgeAlert(ctx, userId, alerts[0].ID)
+	alerts, _ = covid19Service.GetAlerts(ctx, userId, true)
+
+	// Test cases
+	if err != nil || !ok || len(alerts) != 1 || alerts[0].Value != 200 {
+		t.Errorf("expected the alert raised once the rule held again to be left; got %v and %v", alerts, err)
+	}
+}
+
+func TestNegativeCreateAlertRule(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Jordan").Return(2, nil)
+	sqlRepositoryInterface.On("UsersCountriesCount", ctx, userId, 2).Return(0, nil)
+
+	_, err := covid19Service.CreateAlertRule(ctx, userId, "Jordan", model.AlertMetricNewCases, model.AlertOperatorAbove, 10)
+
+	// Test cases
+	var forbiddenError *services.ForbiddenError
+	if !errors.As(err, &forbiddenError) {
+		t.Errorf("expected a forbidden error; got %v", err)
+	}
+
+	_, err = covid19Service.CreateAlertRule(ctx, userId, "Jordan", model.AlertMetric("DEATHS"), model.AlertOperatorAbove, 10)
+
+	// Test cases
+	var validationError *services.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("expected a validation error; got %v", err)
+	}
+}
+
+func TestNegativeAcknowledgeAlert(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	memoryRepository := database.NewMemoryRepository()
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(memoryRepository, logger)
+
+	_, err := covid19Service.AcknowledgeAlert(ctx, 1, 42)
+
+	// Test cases
+	var notFoundError *services.NotFoundError
+	if !errors.As(err, &notFoundError) {
+		t.Errorf("expected a not found error; got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+const (
+	minComparedCountries = 2
+	maxComparedCountries = 5
+)
+
+// Compare returns, for every country and metric, the daily series between
+// from and to along with a summary of how much each series moved. When
+// alignAfterCases is set, each series starts on the day the country reached
+// that number of confirmed cases and the points are numbered from that day,
+// so that countries hit at different times can be compared. That day is looked
+// up over the whole history, it may come before from.
+func (c *Covid19Service) Compare(ctx context.Context, countries []string, metrics []model.Metric, from, to *string, alignAfterCases *int) (*model.Comparison, error) {
+	c.Logger.Debug(ctx, "Compare called")
+
+	if len(countries) < minComparedCountries || len(countries) > maxComparedCountries {
+		c.Logger.Warn(ctx, "invalid number of countries", "countries", len(countries))
+		return nil, &ValidationError{Message: fmt.Sprintf("between %d and %d countries can be compared, got %d", minComparedCountries, maxComparedCountries, len(countries))}
+	}
+	if len(metrics) == 0 {
+		c.Logger.Warn(ctx, "no metric to compare")
+		return nil, &ValidationError{Message: "at least one metric is required"}
+	}
+
+	fromDate, toDate, err := parseDateRange(from, to, defaultIndicatorsRange)
+	if err != nil {
+		c.Logger.Error(ctx, "Compare failed", "error", err)
+		return nil, err
+	}
+
+	comparison := &model.Comparison{
+		Series:  make([]*model.CountrySeries, 0, len(countries)*len(metrics)),
+		Summary: make([]*model.ComparisonSummary, 0, len(countries)*len(metrics)),
+	}
+	// last value of each metric for the first country, the reference of the deltas
+	reference := make(map[model.Metric]float64)
+	for index, country := range countries {
+		_, err := c.SQLRepository.GetCountryIdByName(ctx, country)
+		if errors.Is(err, database.ErrNotFound) {
+			err = &NotFoundError{Resource: "country", Name: country}
+		}
+		if err != nil {
+			c.Logger.Error(ctx, "Compare failed", "error", err)
+			return nil, err
+		}
+
+		// the day before from is needed to compute the new cases of the first
+		// day, and the whole history to find the day the threshold was reached
+		historyStart := fromDate.AddDate(0, 0, -1)
+		if alignAfterCases != nil {
+			historyStart = time.Time{}
+		}
+		statistics, err := c.SQLRepository.GetDailyStatisticsByCountryName(ctx, country, historyStart, toDate)
+		if err != nil {
+			c.Logger.Error(ctx, "Compare failed", "error", err)
+			return nil, err
+		}
+
+		for _, metric := range metrics {
+			points := metricSeries(statistics, metric, fromDate.Format(DateLayout), alignAfterCases)
+			comparison.Series = append(comparison.Series, &model.CountrySeries{
+				Country: country,
+				Metric:  metric,
+				Points:  points,
+			})
+
+			summary := &model.ComparisonSummary{Country: country, Metric: metric}
+			if len(points) != 0 {
+				summary.First = points[0].Value
+				summary.Last = points[len(points)-1].Value
+				summary.Change = summary.Last - summary.First
+			}
+			if index == 0 {
+				reference[metric] = summary.Last
+			}
+			summary.DifferenceFromFirstCountry = summary.Last - reference[metric]
+			comparison.Summary = append(comparison.Summary, summary)
+		}
+	}
+
+	return comparison, nil
+}
+
+// metricSeries returns the value of the metric for every snapshot on or
+// after from. Points are numbered with the days elapsed since the first one,
+// or since the first snapshot reaching the alignment threshold if any, the
+// days before it being skipped. Like the indicators, the new cases are taken
+// from the day before, filled in by dailySeries when it has no snapshot.
+func metricSeries(statistics []entity.DailyStatistic, metric model.Metric, from string, alignAfterCases *int) []*model.SeriesPoint {
+	series, stored := dailySeries(statistics)
+	points := make([]*model.SeriesPoint, 0, len(statistics))
+	var firstDay time.Time
+	aligned := alignAfterCases == nil
+	for i, statistic := range series {
+		if !stored[i] {
+			continue
+		}
+		if !aligned {
+			// the threshold is reached once for all, a later correction
+			// taking the cases below it doesn't hide the following days
+			if statistic.Confirmed < *alignAfterCases {
+				continue
+			}
+			aligned = true
+			firstDay = statistic.Date
+		}
+
+		date := statistic.Date.Format(DateLayout)
+		if date < from {
+			continue
+		}
+		if len(points) == 0 && alignAfterCases == nil {
+			firstDay = statistic.Date
+		}
+
+		var previous *entity.DailyStatistic
+		if i > 0 {
+			previous = &series[i-1]
+		}
+		points = append(points, &model.SeriesPoint{
+			Date:  date,
+			Day:   int(statistic.Date.Sub(firstDay).Hours() / 24),
+			Value: metricValue(statistic, previous, metric),
+		})
+	}
+	return points
+}
+
+func metricValue(statistic entity.DailyStatistic, previous *entity.DailyStatistic, metric model.Metric) float64 {
+	switch metric {
+	case model.MetricConfirmed:
+		return float64(statistic.Confirmed)
+	case model.MetricDeaths:
+		return float64(statistic.Deaths)
+	case model.MetricRecovered:
+		return float64(statistic.Recovered)
+	case model.MetricActive:
+		return float64(statistic.Confirmed - statistic.Deaths - statistic.Recovered)
+	case model.MetricNewCases:
+		if previous == nil {
+			return 0
+		}
+		return float64(dailyIncrease(previous.Confirmed, statistic.Confirmed))
+	case model.MetricNewDeaths:
+		if previous == nil {
+			return 0
+		}
+		return float64(dailyIncrease(previous.Deaths, statistic.Deaths))
+	}
+	return 0
+}
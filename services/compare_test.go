@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCompare(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	firstDay := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	from := "2023-03-01"
+	to := "2023-03-03"
+	alignAfterCases := 100
+
+	jordan := []entity.DailyStatistic{
+		{Date: firstDay.AddDate(0, 0, -1), Confirmed: 90},
+		{Date: firstDay, Confirmed: 100},
+		{Date: firstDay.AddDate(0, 0, 1), Confirmed: 130},
+		{Date: firstDay.AddDate(0, 0, 2), Confirmed: 150},
+	}
+	palestine := []entity.DailyStatistic{
+		{Date: firstDay.AddDate(0, 0, -1), Confirmed: 20},
+		{Date: firstDay, Confirmed: 50},
+		{Date: firstDay.AddDate(0, 0, 1), Confirmed: 110},
+		{Date: firstDay.AddDate(0, 0, 2), Confirmed: 120},
+	}
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Jordan").Return(1, nil)
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Palestine").Return(2, nil)
+	sqlRepositoryInterface.On("GetDailyStatisticsByCountryName", ctx, "Jordan", time.Time{}, firstDay.AddDate(0, 0, 2)).Return(jordan, nil)
+	sqlRepositoryInterface.On("GetDailyStatisticsByCountryName", ctx, "Palestine", time.Time{}, firstDay.AddDate(0, 0, 2)).Return(palestine, nil)
+
+	comparison, err := covid19Service.Compare(ctx, []string{"Jordan", "Palestine"}, []model.Metric{model.MetricConfirmed, model.MetricNewCases}, &from, &to, &alignAfterCases)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if len(comparison.Series) != 4 {
+		t.Fatalf("expected 4 series; got %v", len(comparison.Series))
+	}
+
+	// Test cases
+	palestineConfirmed := comparison.Series[2]
+	if len(palestineConfirmed.Points) != 2 || palestineConfirmed.Points[0].Date != "2023-03-02" || palestineConfirmed.Points[0].Day != 0 {
+		t.Errorf("expected Palestine series to start on 2023-03-02 at day 0; got %v", palestineConfirmed.Points)
+	}
+
+	// Test cases
+	jordanNewCases := comparison.Series[1]
+	if jordanNewCases.Points[0].Value != 10 || jordanNewCases.Points[2].Value != 20 {
+		t.Errorf("expected 10 then 20 new cases; got %v", jordanNewCases.Points)
+	}
+
+	// Test cases
+	summary := comparison.Summary[2]
+	if summary.Change != 10 || summary.DifferenceFromFirstCountry != -30 {
+		t.Errorf("expected change of 10 and difference of -30; got %v and %v", summary.Change, summary.DifferenceFromFirstCountry)
+	}
+
+	from = "2023-03-02"
+	comparison, err = covid19Service.Compare(ctx, []string{"Jordan", "Palestine"}, []model.Metric{model.MetricConfirmed}, &from, &to, &alignAfterCases)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	jordanConfirmed := comparison.Series[0]
+	if len(jordanConfirmed.Points) != 2 || jordanConfirmed.Points[0].Date != "2023-03-02" || jordanConfirmed.Points[0].Day != 1 {
+		t.Errorf("expected Jordan series to start on 2023-03-02 at day 1 after reaching the threshold before from; got %v", jordanConfirmed.Points)
+	}
+}
+
+func TestCompareWithMissingDays(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	firstDay := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	from := "2023-03-01"
+	to := "2023-03-03"
+	// the snapshot of 2023-03-02 is missing
+	jordan := []entity.DailyStatistic{
+		{Date: firstDay.AddDate(0, 0, -1), Confirmed: 90},
+		{Date: firstDay, Confirmed: 100},
+		{Date: firstDay.AddDate(0, 0, 2), Confirmed: 160},
+	}
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, mock.Anything).Return(1, nil)
+	sqlRepositoryInterface.On("GetDailyStatisticsByCountryName", ctx, mock.Anything, firstDay.AddDate(0, 0, -1), firstDay.AddDate(0, 0, 2)).Return(jordan, nil)
+
+	comparison, err := covid19Service.Compare(ctx, []string{"Jordan", "Palestine"}, []model.Metric{model.MetricNewCases}, &from, &to, nil)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	points := comparison.Series[0].Points
+	if len(points) != 2 || points[1].Date != "2023-03-03" || points[1].Day != 2 || points[1].Value != 30 {
+		t.Errorf("expected 30 new cases on 2023-03-03, the missing day being filled in; got %v", points)
+	}
+}
+
+func TestNegativeCompare(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	comparison, err := covid19Service.Compare(ctx, []string{"Jordan"}, []model.Metric{model.MetricConfirmed}, nil, nil, nil)
+
+	// Test cases
+	if comparison != nil {
+		t.Errorf("expected nil comparison; got %v", comparison)
+	}
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected between 2 and 5 countries error; got %v", err)
+	}
+}
+
+func TestNegativeCompareUnknownCountry(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Jordan").Return(1, nil)
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Atlantis").Return(0, database.ErrNotFound)
+	sqlRepositoryInterface.On("GetDailyStatisticsByCountryName", ctx, "Jordan", mock.Anything, mock.Anything).Return([]entity.DailyStatistic{}, nil)
+
+	comparison, err := covid19Service.Compare(ctx, []string{"Jordan", "Atlantis"}, []model.Metric{model.MetricConfirmed}, nil, nil, nil)
+	var notFoundErr *services.NotFoundError
+
+	// Test cases
+	if comparison != nil {
+		t.Errorf("expected nil comparison; got %v", comparison)
+	}
+
+	// Test cases
+	if !errors.As(err, &notFoundErr) || err.Error() != "country Atlantis not found" {
+		t.Errorf("expected country Atlantis not found error; got %v", err)
+	}
+}
@@ -1,137 +1,251 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
 	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/export"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
+	"github.com/FaresAbuIram/COVID19-Statistics/notifications"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Covid19APIURL is the upstream API the statistics are fetched from.
+const Covid19APIURL = "https://api.covid19api.com"
+
 type Covid19Service struct {
-	SQLRepository    SQLRepository
-	LoggerCollection logger.LoggerCollection
+	SQLRepository SQLRepository
+	Logger        logger.Logger
+	// Metrics records the refreshes and the requests to the COVID-19 API, nil
+	// records nothing.
+	Metrics *metrics.Metrics
+	// Events receives the statistics stored by the refresher, nil publishes
+	// nothing.
+	Events *events.Bus
+	// Webhooks tells how DeliverWebhooks sends and retries the deliveries,
+	// posted with WebhookClient.
+	Webhooks      webhooks.Config
+	WebhookClient *http.Client
+	// Digests tells when SendDigests sends the digests, through the
+	// DigestChannels the users can choose from.
+	Digests        notifications.Config
+	DigestChannels map[model.DigestChannel]notifications.Channel
+	// Exports tells how the exports are written and linked to, the links
+	// being signed with a key derived from TokenSecret.
+	Exports     export.Config
+	TokenSecret string
+	// AdminUserIds are the users allowed to change the settings shared by
+	// everyone, such as the subdivision rollup of a country.
+	AdminUserIds []int
 }
 
-func NewCovid19Service(sqlRepository SQLRepository, loggerCollection logger.LoggerCollection) *Covid19Service {
+func NewCovid19Service(sqlRepository SQLRepository, logger logger.Logger) *Covid19Service {
 	return &Covid19Service{
-		SQLRepository:    sqlRepository,
-		LoggerCollection: loggerCollection,
+		SQLRepository: sqlRepository,
+		Logger:        logger,
+		WebhookClient: webhooks.NewClient(0),
 	}
 }
 
-func (c *Covid19Service) AddCountry(name string, userId int) (bool, error) {
-	c.LoggerCollection.AddInfoLogger("services," + "covid19.go," + "AddCountry Func")
+func (c *Covid19Service) AddCountry(ctx context.Context, name string, userId int) (bool, error) {
+	c.Logger.Debug(ctx, "AddCountry called")
 
-	count, err := c.SQLRepository.UsersCountById(userId)
+	count, err := c.SQLRepository.UsersCountById(ctx, userId)
 	if err != nil {
-		c.LoggerCollection.AddErrorLogger(err.Error())
+		c.Logger.Error(ctx, "AddCountry failed", "error", err)
 		return false, err
 	}
 
 	if count == 0 {
-		c.LoggerCollection.AddErrorLogger(fmt.Sprintf("user with id %d doesn't exist", userId))
-		return false, fmt.Errorf("user with id %d doesn't exist", userId)
+		err := &NotFoundError{Resource: "user with id", Name: strconv.Itoa(userId)}
+		c.Logger.Error(ctx, "AddCountry failed", "error", err)
+		return false, err
 	}
 
-	count, err = c.SQLRepository.CountriesCountByname(name)
+	countryId, err := c.getOrInsertCountry(ctx, name)
 	if err != nil {
-		c.LoggerCollection.AddErrorLogger(err.Error())
 		return false, err
 	}
-	var countryId int
-	if count == 0 {
-		countryId, err = c.SQLRepository.InsertCountry(name)
-		if err != nil {
-			c.LoggerCollection.AddErrorLogger(err.Error())
-			return false, err
-		}
-		err = c.SQLRepository.InsertStatistic(countryId)
-		if err != nil {
-			c.LoggerCollection.AddErrorLogger(err.Error())
-			return false, err
-		}
-	} else {
-		countryId, err = c.SQLRepository.GetCountryIdByName(name)
-		if err != nil {
-			c.LoggerCollection.AddErrorLogger(err.Error())
-			return false, err
-		}
-	}
 
-	err = c.SQLRepository.InsertIntoUsersCountries(userId, countryId)
+	err = c.SQLRepository.InsertIntoUsersCountries(ctx, userId, countryId)
+	if errors.Is(err, database.ErrConflict) {
+		err = &ConflictError{Message: fmt.Sprintf("user with id %d is already subscribed to %s", userId, name)}
+	}
 	if err != nil {
-		c.LoggerCollection.AddErrorLogger(err.Error())
+		c.Logger.Error(ctx, "AddCountry failed", "error", err)
 		return false, err
 	}
 
 	return true, nil
 }
 
-func (c *Covid19Service) GetCountries(userId int) ([]*model.Country, error) {
-	countries, err := c.SQLRepository.GetAllCountriesByUserId(userId)
+// getOrInsertCountry returns the id of the country, creating it with empty
+// statistics the first time it is used.
+func (c *Covid19Service) getOrInsertCountry(ctx context.Context, name string) (int, error) {
+	count, err := c.SQLRepository.CountriesCountByname(ctx, name)
+	if err != nil {
+		c.Logger.Error(ctx, "getOrInsertCountry failed", "error", err)
+		return 0, err
+	}
+
+	var countryId int
+	if count != 0 {
+		countryId, err = c.SQLRepository.GetCountryIdByName(ctx, name)
+	} else {
+		countryId, err = c.SQLRepository.InsertCountry(ctx, name)
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "getOrInsertCountry failed", "error", err)
+		return 0, err
+	}
+	// the countries of the shared regions are seeded without statistics, they
+	// are only refreshed once used
+	err = c.SQLRepository.InsertStatistic(ctx, countryId)
+	if err != nil {
+		c.Logger.Error(ctx, "getOrInsertCountry failed", "error", err)
+		return 0, err
+	}
+
+	return countryId, nil
+}
+
+func (c *Covid19Service) GetCountries(ctx context.Context, userId int) ([]*model.Country, error) {
+	countries, err := c.SQLRepository.GetAllCountriesByUserId(ctx, userId)
 	if err != nil {
-		c.LoggerCollection.AddErrorLogger(err.Error())
+		c.Logger.Error(ctx, "GetCountries failed", "error", err)
 		return nil, err
 	}
 	return countries, nil
 }
 
-func (c *Covid19Service) PercentageOfDeathToConfirmed(userId int, countryName string) (float64, error) {
-	percentage, err := c.SQLRepository.GetPercentageOfDeathToConfirmedByCountryName(userId, countryName)
+// PercentageOfDeathToConfirmed returns 0 when the country has no confirmed
+// cases, use Ratio to tell it apart from a country without deaths.
+func (c *Covid19Service) PercentageOfDeathToConfirmed(ctx context.Context, userId int, countryName string) (float64, error) {
+	ratio, err := c.Ratio(ctx, userId, countryName, model.RatioMetricDeaths, model.RatioMetricConfirmed, nil)
 	if err != nil {
-		c.LoggerCollection.AddErrorLogger(err.Error())
 		return 0.0, err
 	}
-	return percentage, nil
+	if ratio.Percentage == nil {
+		return 0.0, nil
+	}
+	return *ratio.Percentage, nil
 }
 
-func (c *Covid19Service) GetTopThreeCountries(userId int, status string) ([]*model.Country, error) {
-	countries, err := c.SQLRepository.GetTopThreeCountriesByUserIdAndType(userId, status)
+// GetTopThreeCountries ranks the countries of the user by their confirmed
+// cases or deaths, status being either "confirmed" or "death".
+func (c *Covid19Service) GetTopThreeCountries(ctx context.Context, userId int, status string) ([]*model.Country, error) {
+	if status != "confirmed" && status != "death" {
+		err := &ValidationError{Message: fmt.Sprintf("invalid type %s, expected confirmed or death", status)}
+		c.Logger.Error(ctx, "GetTopThreeCountries failed", "error", err)
+		return nil, err
+	}
+
+	countries, err := c.SQLRepository.GetTopThreeCountriesByUserIdAndType(ctx, userId, status)
 	if err != nil {
-		c.LoggerCollection.AddErrorLogger(err.Error())
+		c.Logger.Error(ctx, "GetTopThreeCountries failed", "error", err)
 		return nil, err
 	}
 	return countries, nil
 }
 
-func (c *Covid19Service) GetDailyTotals() {
+// GetDailyTotals refreshes the statistics once a day until the context is
+// cancelled, which also aborts a refresh in progress.
+func (c *Covid19Service) GetDailyTotals(ctx context.Context) {
 	ticker := time.NewTicker(24 * time.Hour)
 	defer ticker.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			c.fetchAndUpdateData()
+			c.fetchAndUpdateData(ctx)
 		}
 	}
 }
 
-func (c *Covid19Service) fetchAndUpdateData() error {
-	countries, err := c.SQLRepository.GetAllCountries()
+func (c *Covid19Service) fetchAndUpdateData(ctx context.Context) error {
+	start := time.Now()
+	defer func() { c.Metrics.ObserveRefresh(time.Since(start)) }()
+	ctx, span := tracing.Tracer().Start(ctx, "refresh statistics")
+	defer span.End()
+
+	countries, err := c.SQLRepository.GetAllCountries(ctx)
 	if err != nil {
 		return err
 	}
-	statistics, err := c.SQLRepository.GetAllStatistics()
+	statistics, err := c.SQLRepository.GetAllStatistics(ctx)
 	if err != nil {
 		return err
 	}
-	newStatistics := c.fetchDataFromAPI(countries, statistics)
-	c.SQLRepository.UpdateArrayOfStatistics(newStatistics)
+	for _, statistic := range statistics {
+		if statistic.LastUpdated != nil {
+			c.Metrics.CountryUpdated(countries[statistic.CountryId], *statistic.LastUpdated)
+		}
+	}
+	// the statistics are updated in place, the previous totals are kept to
+	// only publish the countries whose totals changed
+	previous := make(map[int]entity.Statistics, len(statistics))
+	for _, statistic := range statistics {
+		previous[statistic.CountryId] = statistic
+	}
+	newStatistics := c.fetchDataFromAPI(ctx, countries, statistics)
+	newStatistics = c.rollUpSubdivisions(ctx, countries, newStatistics)
+	c.SQLRepository.UpdateArrayOfStatistics(ctx, newStatistics)
+	c.saveDailySnapshots(ctx, newStatistics)
+	c.publishStatistics(ctx, countries, changedStatistics(previous, newStatistics))
+	c.EvaluateAlertRules(ctx)
 
 	return nil
 }
 
-func (c *Covid19Service) fetchDataFromAPI(countries map[int]string, statistics []entity.Statistics) []entity.Statistics {
+// saveDailySnapshots keeps today's totals of every country, so that the
+// derived indicators can be computed from the history.
+func (c *Covid19Service) saveDailySnapshots(ctx context.Context, statistics []entity.Statistics) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, statistic := range statistics {
+		err := c.SQLRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{
+			CountryId: statistic.CountryId,
+			Date:      today,
+			Confirmed: statistic.Confirmed,
+			Deaths:    statistic.Deaths,
+			Recovered: statistic.Recovered,
+		})
+		if err != nil {
+			c.Logger.Error(ctx, "saveDailySnapshots failed", "error", err)
+		}
+	}
+}
+
+func (c *Covid19Service) fetchDataFromAPI(ctx context.Context, countries map[int]string, statistics []entity.Statistics) []entity.Statistics {
 	for index, statistic := range statistics {
+		if ctx.Err() != nil {
+			break
+		}
 		fromDate := statistic.LastUpdated.AddDate(-3, 0, 0)
 		toDate := time.Date(fromDate.Year(), fromDate.Month(), fromDate.Day(), 23, 59, 59, 0, fromDate.Location())
 
-		resp, err := http.Get(fmt.Sprintf("https://api.covid19api.com/total/country/%s?from=%s&to=%s", countries[statistic.CountryId], fromDate.UTC().Format("2006-01-02T00:00:00Z"), toDate.UTC().Format("2006-01-02T15:04:05Z")))
+		country := countries[statistic.CountryId]
+		resp, err := c.get(ctx, "total/country", fmt.Sprintf("%s/total/country/%s?from=%s&to=%s", Covid19APIURL, country, fromDate.UTC().Format("2006-01-02T00:00:00Z"), toDate.UTC().Format("2006-01-02T15:04:05Z")))
 		if err != nil {
-			c.LoggerCollection.AddErrorLogger(err.Error())
+			c.Logger.Error(ctx, "fetchDataFromAPI failed", "error", err)
+			c.Metrics.CountryRefreshed(country, metrics.ResultFailed)
 			continue
 		}
 		defer resp.Body.Close()
@@ -139,10 +253,11 @@ func (c *Covid19Service) fetchDataFromAPI(countries map[int]string, statistics [
 		var covidDataArray []entity.CovidData
 		err = json.NewDecoder(resp.Body).Decode(&covidDataArray)
 		if err != nil {
-			fmt.Println(err)
-			c.LoggerCollection.AddErrorLogger(err.Error())
+			c.Logger.Error(ctx, "fetchDataFromAPI failed", "error", err)
+			c.Metrics.CountryRefreshed(country, metrics.ResultFailed)
 			continue
 		}
+		c.Metrics.CountryRefreshed(country, metrics.ResultOK)
 		if len(covidDataArray) == 0 {
 			continue
 		}
@@ -154,3 +269,36 @@ func (c *Covid19Service) fetchDataFromAPI(countries map[int]string, statistics [
 
 	return statistics
 }
+
+// get requests the endpoint of the COVID-19 API, see getWithContext.
+func (c *Covid19Service) get(ctx context.Context, endpoint, url string) (*http.Response, error) {
+	return getWithContext(ctx, c.Metrics, endpoint, url)
+}
+
+// getWithContext sends a GET request cancelled along with the context, the
+// API being unreachable is returned as an *UpstreamError. Its latency and
+// status are recorded under endpoint, and it is traced in its own span.
+func getWithContext(ctx context.Context, m *metrics.Metrics, endpoint, url string) (*http.Response, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "GET "+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.HTTPMethod(http.MethodGet), semconv.HTTPURL(url)))
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		m.ObserveUpstream(endpoint, 0, time.Since(start))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, &UpstreamError{Err: err}
+	}
+	m.ObserveUpstream(endpoint, resp.StatusCode, time.Since(start))
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
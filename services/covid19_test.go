@@ -1,30 +1,38 @@
 package services_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
 	"github.com/FaresAbuIram/COVID19-Statistics/services"
 	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAddCountry(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
 
 	countryName := "Palestine"
 	countryId := 1
 	userId := 1
 
-	sqlRepositoryInterface.On("UsersCountById", userId).Return(1, nil)
-	sqlRepositoryInterface.On("CountriesCountByname",countryName).Return(1, nil)
-	sqlRepositoryInterface.On("GetCountryIdByName",countryName).Return(1, nil)
-	sqlRepositoryInterface.On("InsertIntoUsersCountries", userId, countryId).Return(nil)
+	sqlRepositoryInterface.On("UsersCountById", ctx, userId).Return(1, nil)
+	sqlRepositoryInterface.On("CountriesCountByname", ctx, countryName).Return(1, nil)
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, countryName).Return(1, nil)
+	sqlRepositoryInterface.On("InsertStatistic", ctx, countryId).Return(nil)
+	sqlRepositoryInterface.On("InsertIntoUsersCountries", ctx, userId, countryId).Return(nil)
 
-	addCountry, err := covid19Service.AddCountry(countryName, userId)
+	addCountry, err := covid19Service.AddCountry(ctx, countryName, userId)
 
 	// Test cases
 	if addCountry != true {
@@ -39,20 +47,21 @@ func TestAddCountry(t *testing.T) {
 
 func TestNegativeAddCountry(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
 
 	countryName := "Palestine"
 	countryId := 1
 	userId := 1
 
-	sqlRepositoryInterface.On("UsersCountById", userId).Return(0, nil)
-	sqlRepositoryInterface.On("CountriesCountByname",countryName).Return(1, nil)
-	sqlRepositoryInterface.On("GetCountryIdByName",countryName).Return(1, nil)
-	sqlRepositoryInterface.On("InsertIntoUsersCountries", userId, countryId).Return(nil)
+	sqlRepositoryInterface.On("UsersCountById", ctx, userId).Return(0, nil)
+	sqlRepositoryInterface.On("CountriesCountByname", ctx, countryName).Return(1, nil)
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, countryName).Return(1, nil)
+	sqlRepositoryInterface.On("InsertIntoUsersCountries", ctx, userId, countryId).Return(nil)
 
-	addCountry, err := covid19Service.AddCountry(countryName, userId)
+	addCountry, err := covid19Service.AddCountry(ctx, countryName, userId)
 
 	// Test cases
 	if addCountry != false {
@@ -67,9 +76,10 @@ func TestNegativeAddCountry(t *testing.T) {
 
 func TestGetCountries(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
 
 	userId := 1
 	
@@ -77,9 +87,9 @@ func TestGetCountries(t *testing.T) {
 	countries = append(countries, &model.Country{Name: "Palestine"})
 	countries = append(countries, &model.Country{Name: "Jordan"})
 
-	sqlRepositoryInterface.On("GetAllCountriesByUserId", userId).Return(countries, nil)
+	sqlRepositoryInterface.On("GetAllCountriesByUserId", ctx, userId).Return(countries, nil)
 	
-	allCountries, err := covid19Service.GetCountries(userId)
+	allCountries, err := covid19Service.GetCountries(ctx, userId)
 
 	// Test cases
 	if allCountries == nil {
@@ -105,16 +115,20 @@ func TestGetCountries(t *testing.T) {
 
 func TestPercentageOfDeathToConfirmed(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
 
 	userId := 1
 	companyName := "Palestine"
+	countryId := 1
 
-	sqlRepositoryInterface.On("GetPercentageOfDeathToConfirmedByCountryName", userId, companyName).Return(float64(10), nil)
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, companyName).Return(countryId, nil)
+	sqlRepositoryInterface.On("UsersCountriesCount", ctx, userId, countryId).Return(1, nil)
+	sqlRepositoryInterface.On("GetStatisticByCountryId", ctx, countryId).Return(entity.Statistics{CountryId: countryId, Confirmed: 200, Deaths: 20}, nil)
 	
-	percentage, err := covid19Service.PercentageOfDeathToConfirmed(userId, companyName)
+	percentage, err := covid19Service.PercentageOfDeathToConfirmed(ctx, userId, companyName)
 
 	// Test cases
 	if percentage != float64(10) {
@@ -129,9 +143,10 @@ func TestPercentageOfDeathToConfirmed(t *testing.T) {
 
 func TestGetTopThreeCountries(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
 
 	userId := 1
 	status := "death"
@@ -141,9 +156,9 @@ func TestGetTopThreeCountries(t *testing.T) {
 	countries = append(countries, &model.Country{Name: "Jordan"})
 	countries = append(countries, &model.Country{Name: "Syria"})
 
-	sqlRepositoryInterface.On("GetTopThreeCountriesByUserIdAndType", userId, status).Return(countries, nil)
+	sqlRepositoryInterface.On("GetTopThreeCountriesByUserIdAndType", ctx, userId, status).Return(countries, nil)
 	
-	topThreeCountries, err := covid19Service.GetTopThreeCountries(userId, status)
+	topThreeCountries, err := covid19Service.GetTopThreeCountries(ctx, userId, status)
 
 	// Test cases
 	if topThreeCountries == nil {
@@ -167,3 +182,94 @@ func TestGetTopThreeCountries(t *testing.T) {
 	}
 }
 
+func TestSubscriptionsWithMemoryRepository(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	memoryRepository := database.NewMemoryRepository()
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(memoryRepository, logger)
+
+	if err := memoryRepository.InsertNewUser(ctx, "test@test.com", []byte("hashed")); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	userId, _, _ := memoryRepository.FindUserByEmail(ctx, "test@test.com")
+
+	for _, countryName := range []string{"Palestine", "Jordan"} {
+		if _, err := covid19Service.AddCountry(ctx, countryName, userId); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+	memoryRepository.UpdateArrayOfStatistics(ctx, []entity.Statistics{{CountryId: 1, Confirmed: 200, Deaths: 20}, {CountryId: 2, Confirmed: 100, Deaths: 30}})
+
+	_, err := covid19Service.AddCountry(ctx, "Palestine", userId)
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected already subscribed error; got nil")
+	}
+
+	countries, err := covid19Service.GetCountries(ctx, userId)
+
+	// Test cases
+	if err != nil || len(countries) != 2 {
+		t.Errorf("expected 2 countries; got %v and %v", countries, err)
+	}
+
+	percentage, err := covid19Service.PercentageOfDeathToConfirmed(ctx, userId, "Palestine")
+
+	// Test cases
+	if err != nil || percentage != float64(10) {
+		t.Errorf("expected 10; got %v and %v", percentage, err)
+	}
+
+	topThreeCountries, err := covid19Service.GetTopThreeCountries(ctx, userId, "death")
+
+	// Test cases
+	if err != nil || len(topThreeCountries) != 2 || topThreeCountries[0].Name != "Jordan" {
+		t.Errorf("expected Jordan first; got %v and %v", topThreeCountries, err)
+	}
+}
+
+func TestGetDailyTotalsStopsOnCancel(t *testing.T) {
+	// prapare data
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(database.NewMemoryRepository(), logger)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		covid19Service.GetDailyTotals(ctx)
+		close(done)
+	}()
+	cancel()
+
+	// Test cases
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("expected the refresher to stop once the context is cancelled")
+	}
+}
+
+func TestNegativeGetTopThreeCountries(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	topThreeCountries, err := covid19Service.GetTopThreeCountries(ctx, 1, "recovered")
+
+	// Test cases
+	if topThreeCountries != nil {
+		t.Errorf("expected nil countries; got %v", topThreeCountries)
+	}
+
+	// Test cases
+	var validationError *services.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("expected ValidationError; got %v", err)
+	}
+
+	sqlRepositoryInterface.AssertNotCalled(t, "GetTopThreeCountriesByUserIdAndType", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/notifications"
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
+)
+
+const (
+	// digestTopMovers is the number of countries listed as top movers.
+	digestTopMovers = 3
+
+	// digestLookback is how many days before a period a snapshot is looked
+	// up to fill in the first day of the period.
+	digestLookback = 7
+)
+
+// SetDigestPreference sets how often the user receives digests through the
+// channel, OFF opting out. The destination of EMAIL defaults to the email
+// address of the user and the one of CHAT is the incoming webhook URL, an
+// omitted destination keeping the current one.
+func (c *Covid19Service) SetDigestPreference(ctx context.Context, userId int, channel model.DigestChannel, destination *string, frequency model.DigestFrequency) (*model.DigestPreference, error) {
+	c.Logger.Debug(ctx, "SetDigestPreference called")
+
+	if !channel.IsValid() || c.DigestChannels[channel] == nil {
+		c.Logger.Warn(ctx, "invalid digest preference", "channel", channel)
+		return nil, &ValidationError{Message: fmt.Sprintf("digest channel %s isn't available", channel)}
+	}
+	if !frequency.IsValid() {
+		c.Logger.Warn(ctx, "invalid digest preference", "frequency", frequency)
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid digest frequency %s", frequency)}
+	}
+
+	preference := entity.DigestPreference{UserId: userId, Channel: string(channel), Frequency: string(frequency)}
+	if destination != nil {
+		preference.Destination = strings.TrimSpace(*destination)
+	} else {
+		current, err := c.SQLRepository.GetDigestPreferencesByUserId(ctx, userId)
+		if err != nil {
+			c.Logger.Error(ctx, "SetDigestPreference failed", "error", err)
+			return nil, err
+		}
+		for _, existing := range current {
+			if existing.Channel == preference.Channel {
+				preference.Destination = existing.Destination
+			}
+		}
+	}
+	switch {
+	case channel == model.DigestChannelEmail && preference.Destination != "":
+		address, err := mail.ParseAddress(preference.Destination)
+		if err != nil {
+			c.Logger.Warn(ctx, "invalid digest preference", "error", err)
+			return nil, &ValidationError{Message: fmt.Sprintf("invalid email address %s", preference.Destination)}
+		}
+		preference.Destination = address.Address
+	case channel == model.DigestChannelChat:
+		if err := webhooks.ValidateURL(preference.Destination); err != nil {
+			c.Logger.Warn(ctx, "invalid digest preference", "error", err)
+			return nil, &ValidationError{Message: err.Error()}
+		}
+	}
+
+	err := c.SQLRepository.UpsertDigestPreference(ctx, preference)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "user with id", Name: strconv.Itoa(userId)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "SetDigestPreference failed", "error", err)
+		return nil, err
+	}
+
+	preferences, err := c.GetDigestPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range preferences {
+		if result.Channel == channel {
+			return result, nil
+		}
+	}
+	return nil, &NotFoundError{Resource: "digest preference of channel", Name: string(channel)}
+}
+
+func (c *Covid19Service) GetDigestPreferences(ctx context.Context, userId int) ([]*model.DigestPreference, error) {
+	c.Logger.Debug(ctx, "GetDigestPreferences called")
+
+	preferences, err := c.SQLRepository.GetDigestPreferencesByUserId(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "GetDigestPreferences failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]*model.DigestPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		result = append(result, &model.DigestPreference{
+			Channel:     model.DigestChannel(preference.Channel),
+			Destination: preference.Destination,
+			Frequency:   model.DigestFrequency(preference.Frequency),
+			LastSentAt:  formatTime(preference.LastSentAt),
+		})
+	}
+	return result, nil
+}
+
+// SendDigests sends the due digests every Digests.CheckInterval, until ctx
+// is done.
+func (c *Covid19Service) SendDigests(ctx context.Context) {
+	ticker := time.NewTicker(c.Digests.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tick := <-ticker.C:
+			c.SendDueDigests(ctx, tick)
+		}
+	}
+}
+
+// SendDueDigests sends the digests whose last schedule, at Digests.Hour UTC
+// every day or every Monday, passed since the last one was sent. A digest
+// that failed to be sent is tried again at the next check.
+func (c *Covid19Service) SendDueDigests(ctx context.Context, now time.Time) {
+	preferences, err := c.SQLRepository.GetAllDigestPreferences(ctx)
+	if err != nil {
+		c.Logger.Error(ctx, "SendDueDigests failed", "error", err)
+		return
+	}
+
+	var totals map[string]entity.CountryStatistics
+	deltas := make(map[string]notifications.CountryDigest)
+	for _, preference := range preferences {
+		if ctx.Err() != nil {
+			return
+		}
+		frequency := model.DigestFrequency(preference.Frequency)
+		if frequency == model.DigestFrequencyOff {
+			continue
+		}
+		channel := c.DigestChannels[model.DigestChannel(preference.Channel)]
+		if channel == nil {
+			c.Logger.Warn(ctx, "digest channel not available", "user_id", preference.UserId, "channel", preference.Channel)
+			continue
+		}
+		scheduled := digestSchedule(frequency, c.Digests.Hour, now)
+		if preference.LastSentAt != nil && !preference.LastSentAt.Before(scheduled) {
+			continue
+		}
+
+		if totals == nil {
+			statistics, err := c.SQLRepository.GetAllCountriesStatistics(ctx)
+			if err != nil {
+				c.Logger.Error(ctx, "SendDueDigests failed", "error", err)
+				return
+			}
+			totals = make(map[string]entity.CountryStatistics, len(statistics))
+			for _, statistic := range statistics {
+				totals[statistic.Name] = statistic
+			}
+		}
+		digest, err := c.buildDigest(ctx, preference.UserId, frequency, scheduled, totals, deltas)
+		if err != nil {
+			c.Logger.Error(ctx, "SendDueDigests failed", "user_id", preference.UserId, "error", err)
+			continue
+		}
+
+		if err := channel.Send(ctx, preference.Destination, digest); err != nil {
+			c.Logger.Warn(ctx, "digest not sent", "user_id", preference.UserId, "channel", preference.Channel, "error", err)
+			continue
+		}
+		if err := c.SQLRepository.UpdateDigestSentAt(ctx, preference.UserId, preference.Channel, now); err != nil {
+			c.Logger.Error(ctx, "SendDueDigests failed", "user_id", preference.UserId, "error", err)
+			continue
+		}
+		c.Logger.Info(ctx, "digest sent", "user_id", preference.UserId, "channel", preference.Channel, "frequency", preference.Frequency)
+	}
+}
+
+// buildDigest summarizes the countries followed by the user over the day or
+// the week before to. deltas caches the changes of the countries across the
+// users, by frequency and country.
+func (c *Covid19Service) buildDigest(ctx context.Context, userId int, frequency model.DigestFrequency, to time.Time, totals map[string]entity.CountryStatistics, deltas map[string]notifications.CountryDigest) (notifications.Digest, error) {
+	days := 1
+	if frequency == model.DigestFrequencyWeekly {
+		days = 7
+	}
+	digest := notifications.Digest{
+		Frequency: strings.ToLower(string(frequency)),
+		From:      to.AddDate(0, 0, -days),
+		To:        to,
+		Countries: make([]notifications.CountryDigest, 0),
+	}
+
+	countries, err := c.SQLRepository.GetAllCountriesByUserId(ctx, userId)
+	if err != nil {
+		return digest, err
+	}
+	for _, country := range countries {
+		key := string(frequency) + "/" + country.Name
+		changes, ok := deltas[key]
+		if !ok {
+			if changes, err = c.digestChanges(ctx, country.Name, digest.From, digest.To); err != nil {
+				return digest, err
+			}
+			deltas[key] = changes
+		}
+
+		total := totals[country.Name]
+		changes.Country = country.Name
+		changes.Confirmed, changes.Deaths, changes.Recovered = total.Confirmed, total.Deaths, total.Recovered
+		digest.Countries = append(digest.Countries, changes)
+	}
+	sort.SliceStable(digest.Countries, func(i, j int) bool { return digest.Countries[i].Country < digest.Countries[j].Country })
+
+	for _, country := range digest.Countries {
+		if country.Growth != nil {
+			digest.TopMovers = append(digest.TopMovers, country)
+		}
+	}
+	sort.SliceStable(digest.TopMovers, func(i, j int) bool { return *digest.TopMovers[i].Growth > *digest.TopMovers[j].Growth })
+	if len(digest.TopMovers) > digestTopMovers {
+		digest.TopMovers = digest.TopMovers[:digestTopMovers]
+	}
+	return digest, nil
+}
+
+// digestChanges computes how the country changed between the first and the
+// last day of the period, the days without a snapshot being filled in from the
+// ones around them. The changes are nil unless the snapshots cover the whole
+// period, a snapshot up to digestLookback days before it filling in its first
+// day.
+func (c *Covid19Service) digestChanges(ctx context.Context, countryName string, from, to time.Time) (notifications.CountryDigest, error) {
+	var changes notifications.CountryDigest
+	fromDay, toDay := truncateDay(from), truncateDay(to)
+	statistics, err := c.SQLRepository.GetDailyStatisticsByCountryName(ctx, countryName, fromDay.AddDate(0, 0, -digestLookback), toDay)
+	if err != nil {
+		return changes, err
+	}
+
+	series, _ := dailySeries(statistics)
+	if len(series) == 0 || series[0].Date.After(fromDay) || !series[len(series)-1].Date.Equal(toDay) {
+		return changes, nil
+	}
+	first, last := series[int(fromDay.Sub(series[0].Date).Hours()/24)], series[len(series)-1]
+	newConfirmed, newDeaths := last.Confirmed-first.Confirmed, last.Deaths-first.Deaths
+	changes.NewConfirmed, changes.NewDeaths = &newConfirmed, &newDeaths
+	if first.Confirmed > 0 {
+		changes.Growth = float64Ptr(float64(newConfirmed) / float64(first.Confirmed) * 100)
+	}
+	return changes, nil
+}
+
+// digestSchedule returns the last time the digests of the frequency were due
+// at now: today at hour UTC, or the Monday of this week for WEEKLY, going
+// back a period when it's still to come.
+func digestSchedule(frequency model.DigestFrequency, hour int, now time.Time) time.Time {
+	now = now.UTC()
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	days := 1
+	if frequency == model.DigestFrequencyWeekly {
+		days = 7
+		scheduled = scheduled.AddDate(0, 0, -(int(scheduled.Weekday())+6)%7)
+	}
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -days)
+	}
+	return scheduled
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,151 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/notifications"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+)
+
+// digestChannel keeps the digests it sent, failing while err is set.
+type digestChannel struct {
+	destinations []string
+	digests      []notifications.Digest
+	err          error
+}
+
+func (d *digestChannel) Send(ctx context.Context, destination string, digest notifications.Digest) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.destinations = append(d.destinations, destination)
+	d.digests = append(d.digests, digest)
+	return nil
+}
+
+func TestSendDueDigests(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, memoryRepository, userId := newMemoryTestService(t, "Palestine", "Jordan", "Egypt")
+	email, chat := &digestChannel{}, &digestChannel{}
+	covid19Service.Digests = notifications.Config{Hour: 7, CheckInterval: time.Hour}
+	covid19Service.DigestChannels = map[model.DigestChannel]notifications.Channel{model.DigestChannelEmail: email, model.DigestChannelChat: chat}
+
+	palestineId, _ := memoryRepository.GetCountryIdByName(ctx, "Palestine")
+	jordanId, _ := memoryRepository.GetCountryIdByName(ctx, "Jordan")
+	memoryRepository.UpdateArrayOfStatistics(ctx, []entity.Statistics{
+		{CountryId: palestineId, Confirmed: 1200, Deaths: 12},
+		{CountryId: jordanId, Confirmed: 550, Deaths: 5},
+	})
+	// Monday 2023-05-01
+	monday := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	for index, day := range []int{-7, -1, 0} {
+		memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: palestineId, Date: monday.AddDate(0, 0, day), Confirmed: 1000 + 100*index, Deaths: 10 + index})
+	}
+	// the first day of the week is filled in from the day before
+	memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: jordanId, Date: monday.AddDate(0, 0, -8), Confirmed: 500, Deaths: 5})
+	memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: jordanId, Date: monday, Confirmed: 550, Deaths: 5})
+
+	if _, err := covid19Service.SetDigestPreference(ctx, userId, model.DigestChannelEmail, nil, model.DigestFrequencyDaily); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	hook := "https://hooks.example.com/services/1"
+	if _, err := covid19Service.SetDigestPreference(ctx, userId, model.DigestChannelChat, &hook, model.DigestFrequencyWeekly); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	covid19Service.SendDueDigests(ctx, monday.Add(8*time.Hour))
+
+	// Test cases
+	if len(email.digests) != 1 || email.destinations[0] != "test@test.com" || email.digests[0].Frequency != notifications.FrequencyDaily {
+		t.Fatalf("expected a daily digest emailed to the user; got %v", email.destinations)
+	}
+
+	// Test cases
+	daily := email.digests[0]
+	if len(daily.Countries) != 3 || daily.Countries[0].Country != "Egypt" || daily.Countries[0].NewConfirmed != nil ||
+		daily.Countries[2].Country != "Palestine" || daily.Countries[2].Confirmed != 1200 || *daily.Countries[2].NewConfirmed != 100 {
+		t.Errorf("expected the followed countries with their daily changes; got %+v", daily.Countries)
+	}
+
+	// Test cases
+	if len(chat.digests) != 1 || chat.destinations[0] != hook {
+		t.Fatalf("expected a weekly digest posted to the chat; got %v", chat.destinations)
+	}
+
+	// Test cases
+	weekly := chat.digests[0]
+	if len(weekly.TopMovers) != 2 || weekly.TopMovers[0].Country != "Palestine" || *weekly.TopMovers[0].Growth != 20 ||
+		weekly.TopMovers[1].Country != "Jordan" || *weekly.TopMovers[1].NewConfirmed != 44 {
+		t.Errorf("expected Palestine then Jordan as top movers; got %+v", weekly.TopMovers)
+	}
+
+	covid19Service.SendDueDigests(ctx, monday.Add(20*time.Hour))
+
+	// Test cases
+	if len(email.digests) != 1 || len(chat.digests) != 1 {
+		t.Errorf("expected no digest sent twice the same day; got %d and %d", len(email.digests), len(chat.digests))
+	}
+
+	email.err = errors.New("connection refused")
+	covid19Service.SendDueDigests(ctx, monday.Add(32*time.Hour))
+	email.err = nil
+	covid19Service.SendDueDigests(ctx, monday.Add(33*time.Hour))
+
+	// Test cases
+	if len(email.digests) != 2 || len(chat.digests) != 1 {
+		t.Errorf("expected the failed daily digest to be sent again, and no weekly one; got %d and %d", len(email.digests), len(chat.digests))
+	}
+
+	if _, err := covid19Service.SetDigestPreference(ctx, userId, model.DigestChannelEmail, nil, model.DigestFrequencyOff); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	preference, err := covid19Service.SetDigestPreference(ctx, userId, model.DigestChannelChat, nil, model.DigestFrequencyDaily)
+
+	// Test cases
+	if err != nil || preference.Destination != hook || preference.Frequency != model.DigestFrequencyDaily || preference.LastSentAt == nil {
+		t.Errorf("expected the chat destination and last sent time to be kept; got %+v and %v", preference, err)
+	}
+
+	covid19Service.SendDueDigests(ctx, monday.Add(56*time.Hour))
+
+	// Test cases
+	if len(email.digests) != 2 {
+		t.Errorf("expected no digest once opted out; got %d", len(email.digests))
+	}
+}
+
+func TestNegativeSetDigestPreference(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, _, userId := newMemoryTestService(t)
+	covid19Service.DigestChannels = map[model.DigestChannel]notifications.Channel{model.DigestChannelChat: &digestChannel{}}
+	invalid, ftp := "not a url", "ftp://hooks.example.com"
+
+	// Test cases
+	for _, test := range []struct {
+		name        string
+		channel     model.DigestChannel
+		destination *string
+		frequency   model.DigestFrequency
+		want        string
+	}{
+		{"channel not configured", model.DigestChannelEmail, nil, model.DigestFrequencyDaily, "digest channel EMAIL isn't available"},
+		{"invalid frequency", model.DigestChannelChat, &ftp, "HOURLY", "invalid digest frequency HOURLY"},
+		{"missing URL", model.DigestChannelChat, nil, model.DigestFrequencyDaily, "webhook"},
+		{"invalid URL", model.DigestChannelChat, &invalid, model.DigestFrequencyDaily, "webhook"},
+		{"invalid scheme", model.DigestChannelChat, &ftp, model.DigestFrequencyDaily, "webhook"},
+	} {
+		_, err := covid19Service.SetDigestPreference(ctx, userId, test.channel, test.destination, test.frequency)
+		var validationErr *services.ValidationError
+		if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected validation error containing %q; got %v", test.name, test.want, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import "fmt"
+
+// NotFoundError is returned when the requested resource doesn't exist.
+type NotFoundError struct {
+	Resource string
+	Name     string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Resource, e.Name)
+}
+
+// ConflictError is returned when the resource to create already exists.
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError is returned when the user isn't allowed to access an
+// existing resource.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// ValidationError is returned when the input of the user is invalid.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// UnauthenticatedError is returned when the credentials of the user are
+// wrong.
+type UnauthenticatedError struct {
+	Message string
+}
+
+func (e *UnauthenticatedError) Error() string {
+	return e.Message
+}
+
+// UpstreamError is returned when the COVID-19 API can't be reached or
+// answers with a server error.
+type UpstreamError struct {
+	Err error
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("COVID-19 API unavailable: %v", e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/export"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+// Export is a validated export of the statistics of countries followed by a
+// user, streamed by Write.
+type Export struct {
+	Dataset   model.ExportDataset
+	Format    model.ExportFormat
+	Countries []string
+	// From and To bound the DAILY exports, both inclusive.
+	From time.Time
+	To   time.Time
+
+	service *Covid19Service
+}
+
+// NewExport checks the export asked by the user. Its countries must be
+// followed by the user, none meaning all of them, and the dates are parsed
+// like the ones of GetIndicators.
+func (c *Covid19Service) NewExport(ctx context.Context, userId int, input model.ExportInput) (*Export, error) {
+	c.Logger.Debug(ctx, "NewExport called")
+
+	if !input.Dataset.IsValid() {
+		c.Logger.Warn(ctx, "invalid export", "dataset", input.Dataset)
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid export dataset %s", input.Dataset)}
+	}
+	if !input.Format.IsValid() {
+		c.Logger.Warn(ctx, "invalid export", "format", input.Format)
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid export format %s", input.Format)}
+	}
+	wanted, err := c.wantedCountries(ctx, userId, input.Countries)
+	if err != nil {
+		c.Logger.Error(ctx, "NewExport failed", "error", err)
+		return nil, err
+	}
+
+	result := &Export{Dataset: input.Dataset, Format: input.Format, Countries: make([]string, 0, len(wanted)), service: c}
+	for country, ok := range wanted {
+		if ok {
+			result.Countries = append(result.Countries, country)
+		}
+	}
+	sort.Strings(result.Countries)
+	if input.Dataset == model.ExportDatasetDaily {
+		if result.From, result.To, err = parseDateRange(input.From, input.To, defaultIndicatorsRange); err != nil {
+			c.Logger.Error(ctx, "NewExport failed", "error", err)
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetExportLink returns a URL the export can be downloaded from without
+// authorization header, such as by pandas, until Exports.LinkExpiry.
+func (c *Covid19Service) GetExportLink(ctx context.Context, userId int, input model.ExportInput) (*model.ExportLink, error) {
+	exported, err := c.NewExport(ctx, userId, input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := exported.Query()
+	expiresAt := time.Now().Add(c.Exports.LinkExpiry)
+	token, err := export.NewToken(c.TokenSecret, userId, query, expiresAt)
+	if err != nil {
+		c.Logger.Error(ctx, "GetExportLink failed", "error", err)
+		return nil, err
+	}
+	query.Set(export.TokenParameter, token)
+
+	return &model.ExportLink{
+		URL:       strings.TrimSuffix(c.Exports.BaseURL, "/") + "/export?" + query.Encode(),
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Query returns the parameters of the /export route asking for the export.
+func (e *Export) Query() url.Values {
+	query := url.Values{
+		"dataset":   {strings.ToLower(string(e.Dataset))},
+		"format":    {strings.ToLower(string(e.Format))},
+		"countries": {strings.Join(e.Countries, ",")},
+	}
+	if e.Dataset == model.ExportDatasetDaily {
+		query.Set("from", e.From.Format(DateLayout))
+		query.Set("to", e.To.Format(DateLayout))
+	}
+	return query
+}
+
+// Filename returns the name the export is downloaded as, such as
+// covid19-daily-2023-01-01-2023-03-31.csv.
+func (e *Export) Filename() string {
+	name := "covid19-" + strings.ToLower(string(e.Dataset))
+	if e.Dataset == model.ExportDatasetDaily {
+		name += "-" + e.From.Format(DateLayout) + "-" + e.To.Format(DateLayout)
+	}
+	return name + "." + strings.ToLower(string(e.Format))
+}
+
+// ContentType returns the media type of the export.
+func (e *Export) ContentType() string {
+	return export.ContentType(strings.ToLower(string(e.Format)))
+}
+
+// Write streams the export to w country by country, so that only the daily
+// snapshots of one country are held at once.
+func (e *Export) Write(ctx context.Context, w io.Writer) error {
+	writer, err := export.NewWriter(w, strings.ToLower(string(e.Format)), e.service.Exports.RowGroupSize)
+	if err != nil {
+		return err
+	}
+
+	if e.Dataset == model.ExportDatasetTotals {
+		err = e.writeTotals(ctx, writer)
+	} else {
+		err = e.writeDaily(ctx, writer)
+	}
+	if err != nil {
+		e.service.Logger.Error(ctx, "Export failed", "error", err)
+		return err
+	}
+	return writer.Close()
+}
+
+// writeDaily writes the snapshots of each country between From and To, with
+// their increase since the snapshot before, if any.
+func (e *Export) writeDaily(ctx context.Context, writer export.Writer) error {
+	for _, country := range e.Countries {
+		statistics, err := e.service.SQLRepository.GetDailyStatisticsByCountryName(ctx, country, e.From.AddDate(0, 0, -1), e.To)
+		if err != nil {
+			return err
+		}
+		for i, statistic := range statistics {
+			if statistic.Date.Before(e.From) {
+				continue
+			}
+			date := statistic.Date
+			row := export.Row{Country: country, Date: &date, Confirmed: statistic.Confirmed, Deaths: statistic.Deaths, Recovered: statistic.Recovered}
+			if i > 0 {
+				newConfirmed := dailyIncrease(statistics[i-1].Confirmed, statistic.Confirmed)
+				newDeaths := dailyIncrease(statistics[i-1].Deaths, statistic.Deaths)
+				row.NewConfirmed, row.NewDeaths = &newConfirmed, &newDeaths
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTotals writes the latest totals of each country, dated by their last
+// refresh.
+func (e *Export) writeTotals(ctx context.Context, writer export.Writer) error {
+	countries, err := e.service.SQLRepository.GetAllCountries(ctx)
+	if err != nil {
+		return err
+	}
+	statistics, err := e.service.SQLRepository.GetAllStatistics(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make(map[string]export.Row, len(statistics))
+	for _, statistic := range statistics {
+		row := export.Row{Country: countries[statistic.CountryId], Confirmed: statistic.Confirmed, Deaths: statistic.Deaths, Recovered: statistic.Recovered}
+		if statistic.LastUpdated != nil {
+			date := truncateDay(*statistic.LastUpdated)
+			row.Date = &date
+		}
+		rows[row.Country] = row
+	}
+	for _, country := range e.Countries {
+		row, ok := rows[country]
+		if !ok {
+			row = export.Row{Country: country}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
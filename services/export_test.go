@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/export"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+)
+
+func newExportTestService(t *testing.T) (*services.Covid19Service, int) {
+	ctx := context.Background()
+	covid19Service, memoryRepository, userId := newMemoryTestService(t, "Palestine", "Jordan")
+	covid19Service.Exports = export.Config{BaseURL: "http://localhost:8080/", LinkExpiry: time.Minute, RowGroupSize: 100}
+	covid19Service.TokenSecret = "secret"
+
+	palestineId, _ := memoryRepository.GetCountryIdByName(ctx, "Palestine")
+	memoryRepository.UpdateArrayOfStatistics(ctx, []entity.Statistics{{CountryId: palestineId, Confirmed: 1200, Deaths: 12, Recovered: 3}})
+	first := time.Date(2023, 4, 29, 0, 0, 0, 0, time.UTC)
+	for day, confirmed := range []int{1000, 1100, 1050, 1200} {
+		memoryRepository.UpsertDailyStatistic(ctx, entity.DailyStatistic{CountryId: palestineId, Date: first.AddDate(0, 0, day), Confirmed: confirmed, Deaths: 10 + day})
+	}
+	return covid19Service, userId
+}
+
+func TestExport(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, userId := newExportTestService(t)
+	from, to := "2023-04-30", "2023-05-02"
+
+	exported, err := covid19Service.NewExport(ctx, userId, model.ExportInput{Dataset: model.ExportDatasetDaily, Format: model.ExportFormatCSV, From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	var output bytes.Buffer
+	err = exported.Write(ctx, &output)
+
+	// Test cases
+	want := "country,date,confirmed,deaths,recovered,new_confirmed,new_deaths\n" +
+		"Palestine,2023-04-30,1100,11,0,100,1\n" +
+		"Palestine,2023-05-01,1050,12,0,0,1\n" +
+		"Palestine,2023-05-02,1200,13,0,150,1\n"
+	if err != nil || output.String() != want {
+		t.Errorf("expected the daily snapshots from the 30th with their increases; got %q and %v", output.String(), err)
+	}
+
+	// Test cases
+	if filename := exported.Filename(); filename != "covid19-daily-2023-04-30-2023-05-02.csv" {
+		t.Errorf("expected covid19-daily-2023-04-30-2023-05-02.csv; got %s", filename)
+	}
+
+	exported, err = covid19Service.NewExport(ctx, userId, model.ExportInput{Dataset: model.ExportDatasetTotals, Format: model.ExportFormatNdjson})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	output.Reset()
+	err = exported.Write(ctx, &output)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	today := time.Now().UTC().Format(services.DateLayout)
+
+	// Test cases
+	if err != nil || len(lines) != 2 || !strings.HasPrefix(lines[0], `{"country":"Jordan","date":"`+today+`","confirmed":0,`) ||
+		lines[1] != `{"country":"Palestine","date":"`+today+`","confirmed":1200,"deaths":12,"recovered":3,"new_confirmed":null,"new_deaths":null}` {
+		t.Errorf("expected the totals of Jordan and Palestine; got %v and %v", lines, err)
+	}
+}
+
+func TestGetExportLink(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, userId := newExportTestService(t)
+	from := "2023-04-30"
+
+	link, err := covid19Service.GetExportLink(ctx, userId, model.ExportInput{Dataset: model.ExportDatasetDaily, Format: model.ExportFormatParquet, Countries: []string{"Palestine"}, From: &from})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	parsed, err := url.Parse(link.URL)
+
+	// Test cases
+	if err != nil || !strings.HasPrefix(link.URL, "http://localhost:8080/export?") || parsed.Query().Get("countries") != "Palestine" || parsed.Query().Get("format") != "parquet" {
+		t.Fatalf("expected a link to the parquet export of Palestine; got %s", link.URL)
+	}
+
+	linkUserId, err := export.ParseToken("secret", parsed.Query().Get(export.TokenParameter), parsed.Query())
+
+	// Test cases
+	if err != nil || linkUserId != userId {
+		t.Errorf("expected the token of the user; got %d and %v", linkUserId, err)
+	}
+}
+
+func TestNegativeNewExport(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, userId := newExportTestService(t)
+	invalid, later := "2023/05/01", "2023-06-01"
+	earlier := "2023-05-01"
+
+	// Test cases
+	for _, test := range []struct {
+		name  string
+		input model.ExportInput
+	}{
+		{"invalid dataset", model.ExportInput{Dataset: "WEEKLY", Format: model.ExportFormatCSV}},
+		{"invalid format", model.ExportInput{Dataset: model.ExportDatasetDaily, Format: "XLSX"}},
+		{"invalid date", model.ExportInput{Dataset: model.ExportDatasetDaily, Format: model.ExportFormatCSV, From: &invalid}},
+		{"from after to", model.ExportInput{Dataset: model.ExportDatasetDaily, Format: model.ExportFormatCSV, From: &later, To: &earlier}},
+	} {
+		_, err := covid19Service.NewExport(ctx, userId, test.input)
+		var validationErr *services.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected validation error; got %v", test.name, err)
+		}
+	}
+
+	_, err := covid19Service.NewExport(ctx, userId, model.ExportInput{Dataset: model.ExportDatasetTotals, Format: model.ExportFormatCSV, Countries: []string{"Egypt"}})
+	var forbiddenErr *services.ForbiddenError
+
+	// Test cases
+	if !errors.As(err, &forbiddenErr) {
+		t.Errorf("expected forbidden error for a country not followed; got %v", err)
+	}
+}
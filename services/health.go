@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/metrics"
+)
+
+// healthCheckTimeout bounds each readiness check.
+const healthCheckTimeout = 2 * time.Second
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type HealthService struct {
+	DB          Pinger
+	UpstreamURL string
+	Logger      logger.Logger
+	// Metrics records the pings of the COVID-19 API, nil records nothing.
+	Metrics *metrics.Metrics
+}
+
+func NewHealthService(db Pinger, upstreamURL string, logger logger.Logger) *HealthService {
+	return &HealthService{
+		DB:          db,
+		UpstreamURL: upstreamURL,
+		Logger:      logger,
+	}
+}
+
+// Readiness checks the database and the upstream API, it returns whether both
+// are reachable along with the status of each, "ok" or "unavailable". The
+// errors are only logged, they could tell the callers about the internals.
+func (h *HealthService) Readiness(ctx context.Context) (bool, map[string]string) {
+	checks := map[string]string{
+		"database": h.check(ctx, "database", h.pingDatabase),
+		"upstream": h.check(ctx, "upstream", h.pingUpstream),
+	}
+	for _, status := range checks {
+		if status != "ok" {
+			return false, checks
+		}
+	}
+	return true, checks
+}
+
+func (h *HealthService) check(ctx context.Context, name string, ping func(ctx context.Context) error) string {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := ping(ctx); err != nil {
+		h.Logger.Error(ctx, "check failed", "check", name, "error", err)
+		return "unavailable"
+	}
+	return "ok"
+}
+
+func (h *HealthService) pingDatabase(ctx context.Context) error {
+	return h.DB.PingContext(ctx)
+}
+
+// pingUpstream succeeds on any response below 500, the API answers its root
+// path with a listing of its routes.
+func (h *HealthService) pingUpstream(ctx context.Context) error {
+	resp, err := getWithContext(ctx, h.Metrics, "health", h.UpstreamURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("upstream answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (f fakePinger) PingContext(ctx context.Context) error {
+	return f.err
+}
+
+func TestReadiness(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+	logger := logger.Discard()
+	healthService := services.NewHealthService(fakePinger{}, upstream.URL, logger)
+
+	ready, checks := healthService.Readiness(ctx)
+
+	// Test cases
+	if !ready || checks["database"] != "ok" || checks["upstream"] != "ok" {
+		t.Errorf("expected ready with every check ok; got %v and %v", ready, checks)
+	}
+}
+
+func TestNegativeReadiness(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+	logger := logger.Discard()
+	healthService := services.NewHealthService(fakePinger{err: errors.New("connection refused")}, upstream.URL, logger)
+
+	ready, checks := healthService.Readiness(ctx)
+
+	// Test cases
+	if ready {
+		t.Errorf("expected not ready; got ready")
+	}
+
+	// Test cases
+	if checks["database"] != "unavailable" || checks["upstream"] != "unavailable" {
+		t.Errorf("expected the database and upstream unavailable without their errors; got %v", checks)
+	}
+}
@@ -0,0 +1,31 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+)
+
+// newMemoryTestService returns a service backed by a memory repository along
+// with the repository and the id of its test@test.com user, who follows the
+// countries.
+func newMemoryTestService(t *testing.T, countries ...string) (*services.Covid19Service, *database.MemoryRepository, int) {
+	t.Helper()
+	ctx := context.Background()
+	memoryRepository := database.NewMemoryRepository()
+	covid19Service := services.NewCovid19Service(memoryRepository, logger.Discard())
+
+	if err := memoryRepository.InsertNewUser(ctx, "test@test.com", []byte("hashed")); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	userId, _, _ := memoryRepository.FindUserByEmail(ctx, "test@test.com")
+	for _, country := range countries {
+		if _, err := covid19Service.AddCountry(ctx, country, userId); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+	return covid19Service, memoryRepository, userId
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+const (
+	// DateLayout is the format of every date accepted or returned by the API.
+	DateLayout = "2006-01-02"
+
+	// indicatorsWindow is the number of days used for rolling averages and
+	// week-over-week comparisons.
+	indicatorsWindow = 7
+
+	// serialInterval is the assumed mean number of days between successive
+	// infections, used to turn the daily growth rate into an Rt estimate.
+	serialInterval = 5.0
+
+	defaultIndicatorsRange = 90
+)
+
+// GetIndicators returns the derived epidemiological indicators of a country
+// for every stored daily snapshot between from and to (both inclusive and
+// formatted as DateLayout). When omitted, to defaults to today and from to
+// 90 days earlier.
+func (c *Covid19Service) GetIndicators(ctx context.Context, countryName string, from, to *string) ([]*model.DailyIndicator, error) {
+	c.Logger.Debug(ctx, "GetIndicators called")
+
+	fromDate, toDate, err := parseDateRange(from, to, defaultIndicatorsRange)
+	if err != nil {
+		c.Logger.Error(ctx, "GetIndicators failed", "error", err)
+		return nil, err
+	}
+
+	// two extra windows are needed before the first returned day: one to
+	// compute its rolling average and one to compare it week over week
+	statistics, err := c.SQLRepository.GetDailyStatisticsByCountryName(ctx, countryName, fromDate.AddDate(0, 0, -2*indicatorsWindow), toDate)
+	if err != nil {
+		c.Logger.Error(ctx, "GetIndicators failed", "error", err)
+		return nil, err
+	}
+
+	indicators := make([]*model.DailyIndicator, 0, len(statistics))
+	for _, indicator := range computeIndicators(statistics) {
+		date, _ := time.Parse(DateLayout, indicator.Date)
+		if date.Before(fromDate) {
+			continue
+		}
+		indicators = append(indicators, indicator)
+	}
+
+	return indicators, nil
+}
+
+// computeIndicators derives the indicators from cumulative daily snapshots
+// sorted by date, one per snapshot. The days without a snapshot are filled in
+// by dailySeries so that the windows span calendar days, and an indicator is
+// left nil whenever there is not enough data to compute it.
+func computeIndicators(statistics []entity.DailyStatistic) []*model.DailyIndicator {
+	series, stored := dailySeries(statistics)
+	newCases := make([]int, len(series))
+	newDeaths := make([]int, len(series))
+	for i := 1; i < len(series); i++ {
+		newCases[i] = dailyIncrease(series[i-1].Confirmed, series[i].Confirmed)
+		newDeaths[i] = dailyIncrease(series[i-1].Deaths, series[i].Deaths)
+	}
+
+	indicators := make([]*model.DailyIndicator, 0, len(statistics))
+	for i, statistic := range series {
+		if !stored[i] {
+			continue
+		}
+		indicator := &model.DailyIndicator{
+			Date:      statistic.Date.Format(DateLayout),
+			Confirmed: statistic.Confirmed,
+			Deaths:    statistic.Deaths,
+			NewCases:  newCases[i],
+			NewDeaths: newDeaths[i],
+		}
+
+		// the first day has no previous day to take the difference with, so
+		// a full window of new cases is only available from the eighth one
+		if i < indicatorsWindow {
+			indicators = append(indicators, indicator)
+			continue
+		}
+
+		currentCases := sum(newCases[i-indicatorsWindow+1 : i+1])
+		indicator.NewCasesAverage = float64Ptr(float64(currentCases) / indicatorsWindow)
+		indicator.NewDeathsAverage = float64Ptr(float64(sum(newDeaths[i-indicatorsWindow+1:i+1])) / indicatorsWindow)
+
+		if i >= 2*indicatorsWindow {
+			previousCases := sum(newCases[i-2*indicatorsWindow+1 : i-indicatorsWindow+1])
+			indicator.WeekOverWeekGrowth, indicator.DoublingTime, indicator.ReproductionNumber = growthIndicators(currentCases, previousCases)
+		}
+
+		indicators = append(indicators, indicator)
+	}
+
+	return indicators
+}
+
+// dailySeries lays the cumulative snapshots sorted by date out one per
+// calendar day, from the first one to the last one. The days missing in
+// between are filled in by spreading the change between the snapshots around
+// them evenly, and stored tells which days come from an actual snapshot.
+func dailySeries(statistics []entity.DailyStatistic) ([]entity.DailyStatistic, []bool) {
+	series := make([]entity.DailyStatistic, 0, len(statistics))
+	stored := make([]bool, 0, len(statistics))
+	for _, statistic := range statistics {
+		statistic.Date = truncateDay(statistic.Date)
+		if len(series) > 0 {
+			previous := series[len(series)-1]
+			gap := int(statistic.Date.Sub(previous.Date).Hours() / 24)
+			for day := 1; day < gap; day++ {
+				series = append(series, entity.DailyStatistic{
+					CountryId: statistic.CountryId,
+					Date:      previous.Date.AddDate(0, 0, day),
+					Confirmed: previous.Confirmed + (statistic.Confirmed-previous.Confirmed)*day/gap,
+					Deaths:    previous.Deaths + (statistic.Deaths-previous.Deaths)*day/gap,
+					Recovered: previous.Recovered + (statistic.Recovered-previous.Recovered)*day/gap,
+				})
+				stored = append(stored, false)
+			}
+		}
+		series = append(series, statistic)
+		stored = append(stored, true)
+	}
+	return series, stored
+}
+
+// growthIndicators compares the new cases of the current week to the ones of
+// the previous week and returns the week-over-week growth in percent, the
+// doubling time in days and the effective reproduction number estimate.
+func growthIndicators(currentCases, previousCases int) (*float64, *float64, *float64) {
+	if previousCases == 0 {
+		return nil, nil, nil
+	}
+	growth := float64Ptr(float64(currentCases-previousCases) / float64(previousCases) * 100)
+	if currentCases == 0 {
+		return growth, nil, float64Ptr(0)
+	}
+
+	dailyRate := math.Log(float64(currentCases)/float64(previousCases)) / indicatorsWindow
+	reproductionNumber := float64Ptr(math.Exp(dailyRate * serialInterval))
+
+	// a doubling time only makes sense while the epidemic is growing
+	if dailyRate <= 0 {
+		return growth, nil, reproductionNumber
+	}
+	return growth, float64Ptr(math.Ln2 / dailyRate), reproductionNumber
+}
+
+func parseDateRange(from, to *string, defaultDays int) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	toDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if to != nil && *to != "" {
+		date, err := time.Parse(DateLayout, *to)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: fmt.Sprintf("invalid date %s, expected format YYYY-MM-DD", *to)}
+		}
+		toDate = date
+	}
+
+	fromDate := toDate.AddDate(0, 0, -defaultDays)
+	if from != nil && *from != "" {
+		date, err := time.Parse(DateLayout, *from)
+		if err != nil {
+			return time.Time{}, time.Time{}, &ValidationError{Message: fmt.Sprintf("invalid date %s, expected format YYYY-MM-DD", *from)}
+		}
+		fromDate = date
+	}
+
+	if fromDate.After(toDate) {
+		return time.Time{}, time.Time{}, &ValidationError{Message: fmt.Sprintf("from date %s is after to date %s", fromDate.Format(DateLayout), toDate.Format(DateLayout))}
+	}
+
+	return fromDate, toDate, nil
+}
+
+// dailyIncrease never reports a negative number of new cases, corrections can
+// make the cumulative totals go down from one day to the next.
+func dailyIncrease(previous, current int) int {
+	if current < previous {
+		return 0
+	}
+	return current - previous
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,160 @@
+package services_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+// dailyStatistics builds 21 days of snapshots, 10 new cases a day for the
+// first two weeks then 20 a day, and one new death a day.
+func dailyStatistics(from time.Time) []entity.DailyStatistic {
+	statistics := make([]entity.DailyStatistic, 0)
+	confirmed := 100
+	for day := 0; day < 21; day++ {
+		if day > 0 && day <= 14 {
+			confirmed += 10
+		} else if day > 14 {
+			confirmed += 20
+		}
+		statistics = append(statistics, entity.DailyStatistic{
+			CountryId: 1,
+			Date:      from.AddDate(0, 0, day),
+			Confirmed: confirmed,
+			Deaths:    day,
+		})
+	}
+	return statistics
+}
+
+func TestGetIndicators(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	countryName := "Palestine"
+	firstDay := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	from := "2023-03-01"
+	to := "2023-03-21"
+
+	sqlRepositoryInterface.On("GetDailyStatisticsByCountryName", ctx, countryName, firstDay.AddDate(0, 0, -14), firstDay.AddDate(0, 0, 20)).Return(dailyStatistics(firstDay), nil)
+
+	indicators, err := covid19Service.GetIndicators(ctx, countryName, &from, &to)
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if len(indicators) != 21 {
+		t.Fatalf("expected 21 elements; got %v", len(indicators))
+	}
+
+	// Test cases
+	if indicators[6].NewCasesAverage != nil {
+		t.Errorf("expected no rolling average before a full week; got %v", *indicators[6].NewCasesAverage)
+	}
+
+	// Test cases
+	if indicators[7].NewCasesAverage == nil || *indicators[7].NewCasesAverage != 10 {
+		t.Errorf("expected rolling average of 10; got %v", indicators[7].NewCasesAverage)
+	}
+
+	// Test cases
+	if indicators[7].NewDeathsAverage == nil || *indicators[7].NewDeathsAverage != 1 {
+		t.Errorf("expected rolling average of 1 death; got %v", indicators[7].NewDeathsAverage)
+	}
+
+	// Test cases
+	last := indicators[20]
+	if last.WeekOverWeekGrowth == nil || math.Abs(*last.WeekOverWeekGrowth-85.71) > 0.01 {
+		t.Errorf("expected 85.71%% week over week growth; got %v", last.WeekOverWeekGrowth)
+	}
+
+	// Test cases
+	if last.DoublingTime == nil || math.Abs(*last.DoublingTime-7.84) > 0.01 {
+		t.Errorf("expected doubling time of 7.84 days; got %v", last.DoublingTime)
+	}
+
+	// Test cases
+	if last.ReproductionNumber == nil || math.Abs(*last.ReproductionNumber-1.56) > 0.01 {
+		t.Errorf("expected reproduction number of 1.56; got %v", last.ReproductionNumber)
+	}
+}
+
+func TestNegativeGetIndicators(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	from := "2023-03-21"
+	to := "2023-03-01"
+
+	indicators, err := covid19Service.GetIndicators(ctx, "Palestine", &from, &to)
+
+	// Test cases
+	if indicators != nil {
+		t.Errorf("expected nil indicators; got %v", indicators)
+	}
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected from date is after to date error; got %v", err)
+	}
+
+	sqlRepositoryInterface.AssertNotCalled(t, "GetDailyStatisticsByCountryName", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetIndicatorsWithMissingDays(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	countryName := "Palestine"
+	firstDay := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	from := "2023-03-01"
+	to := "2023-03-21"
+	// the snapshots of the 10th to the 12th day are missing
+	statistics := dailyStatistics(firstDay)
+	statistics = append(statistics[:9], statistics[12:]...)
+
+	sqlRepositoryInterface.On("GetDailyStatisticsByCountryName", ctx, countryName, firstDay.AddDate(0, 0, -14), firstDay.AddDate(0, 0, 20)).Return(statistics, nil)
+
+	indicators, err := covid19Service.GetIndicators(ctx, countryName, &from, &to)
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if len(indicators) != 18 {
+		t.Fatalf("expected 18 elements; got %v", len(indicators))
+	}
+
+	// Test cases
+	afterGap := indicators[9]
+	if afterGap.Date != "2023-03-13" || afterGap.NewCases != 10 || afterGap.NewCasesAverage == nil || *afterGap.NewCasesAverage != 10 {
+		t.Errorf("expected 10 new cases a day on 2023-03-13, the missing days being filled in; got %+v", afterGap)
+	}
+
+	// Test cases
+	last := indicators[17]
+	if last.WeekOverWeekGrowth == nil || math.Abs(*last.WeekOverWeekGrowth-85.71) > 0.01 {
+		t.Errorf("expected 85.71%% week over week growth; got %v", last.WeekOverWeekGrowth)
+	}
+}
@@ -3,10 +3,15 @@
 package mocks
 
 import (
+	context "context"
+
 	entity "github.com/FaresAbuIram/COVID19-Statistics/entity"
+
 	mock "github.com/stretchr/testify/mock"
 
 	model "github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+
+	time "time"
 )
 
 // SQLRepositoryInterface is an autogenerated mock type for the SQLRepositoryInterface type
@@ -14,20 +19,62 @@ type SQLRepositoryInterface struct {
 	mock.Mock
 }
 
-// CountriesCountByname provides a mock function with given fields: name
-func (_m *SQLRepositoryInterface) CountriesCountByname(name string) (int, error) {
-	ret := _m.Called(name)
+// AcknowledgeAlert provides a mock function with given fields: ctx, userId, alertId, acknowledgedAt
+func (_m *SQLRepositoryInterface) AcknowledgeAlert(ctx context.Context, userId int, alertId int, acknowledgedAt time.Time) (int, error) {
+	ret := _m.Called(ctx, userId, alertId, acknowledgedAt)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, time.Time) int); ok {
+		r0 = rf(ctx, userId, alertId, acknowledgedAt)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, time.Time) error); ok {
+		r1 = rf(ctx, userId, alertId, acknowledgedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountriesCountByname provides a mock function with given fields: ctx, name
+func (_m *SQLRepositoryInterface) CountriesCountByname(ctx context.Context, name string) (int, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAlertRule provides a mock function with given fields: ctx, userId, ruleId
+func (_m *SQLRepositoryInterface) DeleteAlertRule(ctx context.Context, userId int, ruleId int) (int, error) {
+	ret := _m.Called(ctx, userId, ruleId)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(string) int); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(ctx, userId, ruleId)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, ruleId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,20 +82,41 @@ func (_m *SQLRepositoryInterface) CountriesCountByname(name string) (int, error)
 	return r0, r1
 }
 
-// FindUserByEmail provides a mock function with given fields: email
-func (_m *SQLRepositoryInterface) FindUserByEmail(email string) (int, []byte, error) {
-	ret := _m.Called(email)
+// DeleteWebhook provides a mock function with given fields: ctx, userId, webhookId
+func (_m *SQLRepositoryInterface) DeleteWebhook(ctx context.Context, userId int, webhookId int) (int, error) {
+	ret := _m.Called(ctx, userId, webhookId)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(string) int); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(ctx, userId, webhookId)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, webhookId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindUserByEmail provides a mock function with given fields: ctx, email
+func (_m *SQLRepositoryInterface) FindUserByEmail(ctx context.Context, email string) (int, []byte, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, email)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 []byte
-	if rf, ok := ret.Get(1).(func(string) []byte); ok {
-		r1 = rf(email)
+	if rf, ok := ret.Get(1).(func(context.Context, string) []byte); ok {
+		r1 = rf(ctx, email)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).([]byte)
@@ -56,8 +124,8 @@ func (_m *SQLRepositoryInterface) FindUserByEmail(email string) (int, []byte, er
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(string) error); ok {
-		r2 = rf(email)
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, email)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -65,13 +133,82 @@ func (_m *SQLRepositoryInterface) FindUserByEmail(email string) (int, []byte, er
 	return r0, r1, r2
 }
 
-// GetAllCountries provides a mock function with given fields:
-func (_m *SQLRepositoryInterface) GetAllCountries() (map[int]string, error) {
-	ret := _m.Called()
+// GetAlertRulesByUserId provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) GetAlertRulesByUserId(ctx context.Context, userId int) ([]entity.AlertRule, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []entity.AlertRule
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.AlertRule); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.AlertRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAlertsByUserId provides a mock function with given fields: ctx, userId, unacknowledgedOnly
+func (_m *SQLRepositoryInterface) GetAlertsByUserId(ctx context.Context, userId int, unacknowledgedOnly bool) ([]entity.Alert, error) {
+	ret := _m.Called(ctx, userId, unacknowledgedOnly)
+
+	var r0 []entity.Alert
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) []entity.Alert); ok {
+		r0 = rf(ctx, userId, unacknowledgedOnly)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Alert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, bool) error); ok {
+		r1 = rf(ctx, userId, unacknowledgedOnly)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllAlertRules provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetAllAlertRules(ctx context.Context) ([]entity.AlertRule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entity.AlertRule
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.AlertRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.AlertRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllCountries provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetAllCountries(ctx context.Context) (map[int]string, error) {
+	ret := _m.Called(ctx)
 
 	var r0 map[int]string
-	if rf, ok := ret.Get(0).(func() map[int]string); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) map[int]string); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(map[int]string)
@@ -79,8 +216,8 @@ func (_m *SQLRepositoryInterface) GetAllCountries() (map[int]string, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -88,13 +225,13 @@ func (_m *SQLRepositoryInterface) GetAllCountries() (map[int]string, error) {
 	return r0, r1
 }
 
-// GetAllCountriesByUserId provides a mock function with given fields: userId
-func (_m *SQLRepositoryInterface) GetAllCountriesByUserId(userId int) ([]*model.Country, error) {
-	ret := _m.Called(userId)
+// GetAllCountriesByUserId provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) GetAllCountriesByUserId(ctx context.Context, userId int) ([]*model.Country, error) {
+	ret := _m.Called(ctx, userId)
 
 	var r0 []*model.Country
-	if rf, ok := ret.Get(0).(func(int) []*model.Country); ok {
-		r0 = rf(userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*model.Country); ok {
+		r0 = rf(ctx, userId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.Country)
@@ -102,8 +239,8 @@ func (_m *SQLRepositoryInterface) GetAllCountriesByUserId(userId int) ([]*model.
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(userId)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -111,13 +248,59 @@ func (_m *SQLRepositoryInterface) GetAllCountriesByUserId(userId int) ([]*model.
 	return r0, r1
 }
 
-// GetAllStatistics provides a mock function with given fields:
-func (_m *SQLRepositoryInterface) GetAllStatistics() ([]entity.Statistics, error) {
-	ret := _m.Called()
+// GetAllCountriesStatistics provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetAllCountriesStatistics(ctx context.Context) ([]entity.CountryStatistics, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entity.CountryStatistics
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.CountryStatistics); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.CountryStatistics)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllDigestPreferences provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetAllDigestPreferences(ctx context.Context) ([]entity.DigestPreference, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entity.DigestPreference
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.DigestPreference); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DigestPreference)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllStatistics provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetAllStatistics(ctx context.Context) ([]entity.Statistics, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []entity.Statistics
-	if rf, ok := ret.Get(0).(func() []entity.Statistics); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.Statistics); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entity.Statistics)
@@ -125,8 +308,54 @@ func (_m *SQLRepositoryInterface) GetAllStatistics() ([]entity.Statistics, error
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllWebhooks provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetAllWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entity.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.Webhook); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCountriesStatisticsByRegionId provides a mock function with given fields: ctx, regionId
+func (_m *SQLRepositoryInterface) GetCountriesStatisticsByRegionId(ctx context.Context, regionId int) ([]entity.CountryStatistics, error) {
+	ret := _m.Called(ctx, regionId)
+
+	var r0 []entity.CountryStatistics
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.CountryStatistics); ok {
+		r0 = rf(ctx, regionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.CountryStatistics)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, regionId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -134,20 +363,133 @@ func (_m *SQLRepositoryInterface) GetAllStatistics() ([]entity.Statistics, error
 	return r0, r1
 }
 
-// GetCountryIdByName provides a mock function with given fields: name
-func (_m *SQLRepositoryInterface) GetCountryIdByName(name string) (int, error) {
-	ret := _m.Called(name)
+// GetCountryIdByName provides a mock function with given fields: ctx, name
+func (_m *SQLRepositoryInterface) GetCountryIdByName(ctx context.Context, name string) (int, error) {
+	ret := _m.Called(ctx, name)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(string) int); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, name)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCountryIdsWithSubdivisionRollup provides a mock function with given fields: ctx
+func (_m *SQLRepositoryInterface) GetCountryIdsWithSubdivisionRollup(ctx context.Context) ([]int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []int
+	if rf, ok := ret.Get(0).(func(context.Context) []int); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDailyStatisticByCountryIdAndDate provides a mock function with given fields: ctx, countryId, date
+func (_m *SQLRepositoryInterface) GetDailyStatisticByCountryIdAndDate(ctx context.Context, countryId int, date time.Time) (entity.DailyStatistic, error) {
+	ret := _m.Called(ctx, countryId, date)
+
+	var r0 entity.DailyStatistic
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) entity.DailyStatistic); ok {
+		r0 = rf(ctx, countryId, date)
+	} else {
+		r0 = ret.Get(0).(entity.DailyStatistic)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Time) error); ok {
+		r1 = rf(ctx, countryId, date)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDailyStatisticsByCountryName provides a mock function with given fields: ctx, countryName, from, to
+func (_m *SQLRepositoryInterface) GetDailyStatisticsByCountryName(ctx context.Context, countryName string, from time.Time, to time.Time) ([]entity.DailyStatistic, error) {
+	ret := _m.Called(ctx, countryName, from, to)
+
+	var r0 []entity.DailyStatistic
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) []entity.DailyStatistic); ok {
+		r0 = rf(ctx, countryName, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DailyStatistic)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, countryName, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDigestPreferencesByUserId provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) GetDigestPreferencesByUserId(ctx context.Context, userId int) ([]entity.DigestPreference, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []entity.DigestPreference
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.DigestPreference); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DigestPreference)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDueWebhookDeliveries provides a mock function with given fields: ctx, now, limit
+func (_m *SQLRepositoryInterface) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	var r0 []entity.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -155,20 +497,20 @@ func (_m *SQLRepositoryInterface) GetCountryIdByName(name string) (int, error) {
 	return r0, r1
 }
 
-// GetPercentageOfDeathToConfirmedByCountryName provides a mock function with given fields: userId, countryName
-func (_m *SQLRepositoryInterface) GetPercentageOfDeathToConfirmedByCountryName(userId int, countryName string) (float64, error) {
-	ret := _m.Called(userId, countryName)
+// GetRegionByName provides a mock function with given fields: ctx, userId, name
+func (_m *SQLRepositoryInterface) GetRegionByName(ctx context.Context, userId int, name string) (entity.Region, error) {
+	ret := _m.Called(ctx, userId, name)
 
-	var r0 float64
-	if rf, ok := ret.Get(0).(func(int, string) float64); ok {
-		r0 = rf(userId, countryName)
+	var r0 entity.Region
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) entity.Region); ok {
+		r0 = rf(ctx, userId, name)
 	} else {
-		r0 = ret.Get(0).(float64)
+		r0 = ret.Get(0).(entity.Region)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int, string) error); ok {
-		r1 = rf(userId, countryName)
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userId, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -176,13 +518,124 @@ func (_m *SQLRepositoryInterface) GetPercentageOfDeathToConfirmedByCountryName(u
 	return r0, r1
 }
 
-// GetTopThreeCountriesByUserIdAndType provides a mock function with given fields: userId, status
-func (_m *SQLRepositoryInterface) GetTopThreeCountriesByUserIdAndType(userId int, status string) ([]*model.Country, error) {
-	ret := _m.Called(userId, status)
+// GetRegionsByUserId provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) GetRegionsByUserId(ctx context.Context, userId int) ([]entity.Region, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []entity.Region
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.Region); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Region)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatisticByCountryId provides a mock function with given fields: ctx, countryId
+func (_m *SQLRepositoryInterface) GetStatisticByCountryId(ctx context.Context, countryId int) (entity.Statistics, error) {
+	ret := _m.Called(ctx, countryId)
+
+	var r0 entity.Statistics
+	if rf, ok := ret.Get(0).(func(context.Context, int) entity.Statistics); ok {
+		r0 = rf(ctx, countryId)
+	} else {
+		r0 = ret.Get(0).(entity.Statistics)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, countryId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubdivisionIdByName provides a mock function with given fields: ctx, countryName, name
+func (_m *SQLRepositoryInterface) GetSubdivisionIdByName(ctx context.Context, countryName string, name string) (int, error) {
+	ret := _m.Called(ctx, countryName, name)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, countryName, name)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, countryName, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubdivisionsStatisticsByCountryName provides a mock function with given fields: ctx, countryName
+func (_m *SQLRepositoryInterface) GetSubdivisionsStatisticsByCountryName(ctx context.Context, countryName string) ([]entity.SubdivisionStatistics, error) {
+	ret := _m.Called(ctx, countryName)
+
+	var r0 []entity.SubdivisionStatistics
+	if rf, ok := ret.Get(0).(func(context.Context, string) []entity.SubdivisionStatistics); ok {
+		r0 = rf(ctx, countryName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.SubdivisionStatistics)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, countryName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubdivisionsStatisticsByUserId provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) GetSubdivisionsStatisticsByUserId(ctx context.Context, userId int) ([]entity.SubdivisionStatistics, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []entity.SubdivisionStatistics
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.SubdivisionStatistics); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.SubdivisionStatistics)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTopThreeCountriesByUserIdAndType provides a mock function with given fields: ctx, userId, status
+func (_m *SQLRepositoryInterface) GetTopThreeCountriesByUserIdAndType(ctx context.Context, userId int, status string) ([]*model.Country, error) {
+	ret := _m.Called(ctx, userId, status)
 
 	var r0 []*model.Country
-	if rf, ok := ret.Get(0).(func(int, string) []*model.Country); ok {
-		r0 = rf(userId, status)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []*model.Country); ok {
+		r0 = rf(ctx, userId, status)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.Country)
@@ -190,8 +643,117 @@ func (_m *SQLRepositoryInterface) GetTopThreeCountriesByUserIdAndType(userId int
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int, string) error); ok {
-		r1 = rf(userId, status)
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userId, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhookDeliveries provides a mock function with given fields: ctx, userId, webhookId, limit
+func (_m *SQLRepositoryInterface) GetWebhookDeliveries(ctx context.Context, userId int, webhookId int, limit int) ([]entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, userId, webhookId, limit)
+
+	var r0 []entity.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, userId, webhookId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(ctx, userId, webhookId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhookDeliveryById provides a mock function with given fields: ctx, userId, deliveryId
+func (_m *SQLRepositoryInterface) GetWebhookDeliveryById(ctx context.Context, userId int, deliveryId int) (entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, userId, deliveryId)
+
+	var r0 entity.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) entity.WebhookDelivery); ok {
+		r0 = rf(ctx, userId, deliveryId)
+	} else {
+		r0 = ret.Get(0).(entity.WebhookDelivery)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, deliveryId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhooksByUserId provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) GetWebhooksByUserId(ctx context.Context, userId int) ([]entity.Webhook, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []entity.Webhook
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.Webhook); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertAlert provides a mock function with given fields: ctx, alert
+func (_m *SQLRepositoryInterface) InsertAlert(ctx context.Context, alert entity.Alert) (int, error) {
+	ret := _m.Called(ctx, alert)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Alert) int); ok {
+		r0 = rf(ctx, alert)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Alert) error); ok {
+		r1 = rf(ctx, alert)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertAlertRule provides a mock function with given fields: ctx, rule
+func (_m *SQLRepositoryInterface) InsertAlertRule(ctx context.Context, rule entity.AlertRule) (int, error) {
+	ret := _m.Called(ctx, rule)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, entity.AlertRule) int); ok {
+		r0 = rf(ctx, rule)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, entity.AlertRule) error); ok {
+		r1 = rf(ctx, rule)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -199,20 +761,20 @@ func (_m *SQLRepositoryInterface) GetTopThreeCountriesByUserIdAndType(userId int
 	return r0, r1
 }
 
-// InsertCountry provides a mock function with given fields: name
-func (_m *SQLRepositoryInterface) InsertCountry(name string) (int, error) {
-	ret := _m.Called(name)
+// InsertCountry provides a mock function with given fields: ctx, name
+func (_m *SQLRepositoryInterface) InsertCountry(ctx context.Context, name string) (int, error) {
+	ret := _m.Called(ctx, name)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(string) int); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, name)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -220,13 +782,13 @@ func (_m *SQLRepositoryInterface) InsertCountry(name string) (int, error) {
 	return r0, r1
 }
 
-// InsertIntoUsersCountries provides a mock function with given fields: userId, countryId
-func (_m *SQLRepositoryInterface) InsertIntoUsersCountries(userId int, countryId int) error {
-	ret := _m.Called(userId, countryId)
+// InsertIntoRegionsCountries provides a mock function with given fields: ctx, regionId, countryId
+func (_m *SQLRepositoryInterface) InsertIntoRegionsCountries(ctx context.Context, regionId int, countryId int) error {
+	ret := _m.Called(ctx, regionId, countryId)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int, int) error); ok {
-		r0 = rf(userId, countryId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, regionId, countryId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -234,13 +796,13 @@ func (_m *SQLRepositoryInterface) InsertIntoUsersCountries(userId int, countryId
 	return r0
 }
 
-// InsertNewUser provides a mock function with given fields: email, password
-func (_m *SQLRepositoryInterface) InsertNewUser(email string, password []byte) error {
-	ret := _m.Called(email, password)
+// InsertIntoUsersCountries provides a mock function with given fields: ctx, userId, countryId
+func (_m *SQLRepositoryInterface) InsertIntoUsersCountries(ctx context.Context, userId int, countryId int) error {
+	ret := _m.Called(ctx, userId, countryId)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
-		r0 = rf(email, password)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, countryId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -248,13 +810,13 @@ func (_m *SQLRepositoryInterface) InsertNewUser(email string, password []byte) e
 	return r0
 }
 
-// InsertStatistic provides a mock function with given fields: countryId
-func (_m *SQLRepositoryInterface) InsertStatistic(countryId int) error {
-	ret := _m.Called(countryId)
+// InsertIntoUsersSubdivisions provides a mock function with given fields: ctx, userId, subdivisionId
+func (_m *SQLRepositoryInterface) InsertIntoUsersSubdivisions(ctx context.Context, userId int, subdivisionId int) error {
+	ret := _m.Called(ctx, userId, subdivisionId)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int) error); ok {
-		r0 = rf(countryId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, subdivisionId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -262,25 +824,263 @@ func (_m *SQLRepositoryInterface) InsertStatistic(countryId int) error {
 	return r0
 }
 
-// UpdateArrayOfStatistics provides a mock function with given fields: statistics
-func (_m *SQLRepositoryInterface) UpdateArrayOfStatistics(statistics []entity.Statistics) {
-	_m.Called(statistics)
+// InsertNewUser provides a mock function with given fields: ctx, email, password
+func (_m *SQLRepositoryInterface) InsertNewUser(ctx context.Context, email string, password []byte) error {
+	ret := _m.Called(ctx, email, password)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) error); ok {
+		r0 = rf(ctx, email, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertRegion provides a mock function with given fields: ctx, name, regionType, userId, countryIds
+func (_m *SQLRepositoryInterface) InsertRegion(ctx context.Context, name string, regionType string, userId int, countryIds []int) (int, error) {
+	ret := _m.Called(ctx, name, regionType, userId, countryIds)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, []int) int); ok {
+		r0 = rf(ctx, name, regionType, userId, countryIds)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, []int) error); ok {
+		r1 = rf(ctx, name, regionType, userId, countryIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertStatistic provides a mock function with given fields: ctx, countryId
+func (_m *SQLRepositoryInterface) InsertStatistic(ctx context.Context, countryId int) error {
+	ret := _m.Called(ctx, countryId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, countryId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertWebhook provides a mock function with given fields: ctx, webhook
+func (_m *SQLRepositoryInterface) InsertWebhook(ctx context.Context, webhook entity.Webhook) (int, error) {
+	ret := _m.Called(ctx, webhook)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Webhook) int); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Webhook) error); ok {
+		r1 = rf(ctx, webhook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertWebhookDelivery provides a mock function with given fields: ctx, delivery
+func (_m *SQLRepositoryInterface) InsertWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (int, error) {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, entity.WebhookDelivery) int); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, entity.WebhookDelivery) error); ok {
+		r1 = rf(ctx, delivery)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAlertRuleTriggered provides a mock function with given fields: ctx, ruleId, triggered
+func (_m *SQLRepositoryInterface) UpdateAlertRuleTriggered(ctx context.Context, ruleId int, triggered bool) error {
+	ret := _m.Called(ctx, ruleId, triggered)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(ctx, ruleId, triggered)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateArrayOfStatistics provides a mock function with given fields: ctx, statistics
+func (_m *SQLRepositoryInterface) UpdateArrayOfStatistics(ctx context.Context, statistics []entity.Statistics) {
+	_m.Called(ctx, statistics)
+}
+
+// UpdateDigestSentAt provides a mock function with given fields: ctx, userId, channel, sentAt
+func (_m *SQLRepositoryInterface) UpdateDigestSentAt(ctx context.Context, userId int, channel string, sentAt time.Time) error {
+	ret := _m.Called(ctx, userId, channel, sentAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, time.Time) error); ok {
+		r0 = rf(ctx, userId, channel, sentAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSubdivisionRollup provides a mock function with given fields: ctx, countryName, enabled
+func (_m *SQLRepositoryInterface) UpdateSubdivisionRollup(ctx context.Context, countryName string, enabled bool) (int, error) {
+	ret := _m.Called(ctx, countryName, enabled)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) int); ok {
+		r0 = rf(ctx, countryName, enabled)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, countryName, enabled)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateWebhookDelivery provides a mock function with given fields: ctx, delivery
+func (_m *SQLRepositoryInterface) UpdateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertDailyStatistic provides a mock function with given fields: ctx, statistic
+func (_m *SQLRepositoryInterface) UpsertDailyStatistic(ctx context.Context, statistic entity.DailyStatistic) error {
+	ret := _m.Called(ctx, statistic)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.DailyStatistic) error); ok {
+		r0 = rf(ctx, statistic)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertDigestPreference provides a mock function with given fields: ctx, preference
+func (_m *SQLRepositoryInterface) UpsertDigestPreference(ctx context.Context, preference entity.DigestPreference) error {
+	ret := _m.Called(ctx, preference)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.DigestPreference) error); ok {
+		r0 = rf(ctx, preference)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertSubdivision provides a mock function with given fields: ctx, countryId, name
+func (_m *SQLRepositoryInterface) UpsertSubdivision(ctx context.Context, countryId int, name string) (int, error) {
+	ret := _m.Called(ctx, countryId, name)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) int); ok {
+		r0 = rf(ctx, countryId, name)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, countryId, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpsertSubdivisionStatistic provides a mock function with given fields: ctx, statistic
+func (_m *SQLRepositoryInterface) UpsertSubdivisionStatistic(ctx context.Context, statistic entity.SubdivisionStatistics) error {
+	ret := _m.Called(ctx, statistic)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.SubdivisionStatistics) error); ok {
+		r0 = rf(ctx, statistic)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UsersCountByEmail provides a mock function with given fields: ctx, email
+func (_m *SQLRepositoryInterface) UsersCountByEmail(ctx context.Context, email string) (int, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// UsersCountByEmail provides a mock function with given fields: email
-func (_m *SQLRepositoryInterface) UsersCountByEmail(email string) (int, error) {
-	ret := _m.Called(email)
+// UsersCountById provides a mock function with given fields: ctx, userId
+func (_m *SQLRepositoryInterface) UsersCountById(ctx context.Context, userId int) (int, error) {
+	ret := _m.Called(ctx, userId)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(string) int); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, userId)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -288,20 +1088,20 @@ func (_m *SQLRepositoryInterface) UsersCountByEmail(email string) (int, error) {
 	return r0, r1
 }
 
-// UsersCountById provides a mock function with given fields: userId
-func (_m *SQLRepositoryInterface) UsersCountById(userId int) (int, error) {
-	ret := _m.Called(userId)
+// UsersCountriesCount provides a mock function with given fields: ctx, userId, countryId
+func (_m *SQLRepositoryInterface) UsersCountriesCount(ctx context.Context, userId int, countryId int) (int, error) {
+	ret := _m.Called(ctx, userId, countryId)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(int) int); ok {
-		r0 = rf(userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(ctx, userId, countryId)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(userId)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, countryId)
 	} else {
 		r1 = ret.Error(1)
 	}
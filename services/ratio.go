@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+// Ratio divides two statistics of a country the user is subscribed to, using
+// the latest statistics or the daily snapshot of date (formatted as
+// DateLayout) when given. The value and the percentage are left nil when the
+// denominator is 0.
+func (c *Covid19Service) Ratio(ctx context.Context, userId int, countryName string, numerator, denominator model.RatioMetric, date *string) (*model.Ratio, error) {
+	c.Logger.Debug(ctx, "Ratio called")
+
+	if !numerator.IsValid() || !denominator.IsValid() {
+		c.Logger.Warn(ctx, "invalid ratio", "numerator", numerator, "denominator", denominator)
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid ratio %s/%s", numerator, denominator)}
+	}
+
+	countryId, err := c.SQLRepository.GetCountryIdByName(ctx, countryName)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "country", Name: countryName}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "Ratio failed", "error", err)
+		return nil, err
+	}
+
+	count, err := c.SQLRepository.UsersCountriesCount(ctx, userId, countryId)
+	if err != nil {
+		c.Logger.Error(ctx, "Ratio failed", "error", err)
+		return nil, err
+	}
+
+	if count == 0 {
+		err = &ForbiddenError{Message: fmt.Sprintf("user with id %d isn't subscribed to %s", userId, countryName)}
+		c.Logger.Error(ctx, "Ratio failed", "error", err)
+		return nil, err
+	}
+
+	var confirmed, deaths, recovered int
+	if date != nil && *date == "" {
+		date = nil
+	}
+	if date == nil {
+		statistic, err := c.SQLRepository.GetStatisticByCountryId(ctx, countryId)
+		if err != nil {
+			c.Logger.Error(ctx, "Ratio failed", "error", err)
+			return nil, err
+		}
+		confirmed, deaths, recovered = statistic.Confirmed, statistic.Deaths, statistic.Recovered
+	} else {
+		day, err := time.Parse(DateLayout, *date)
+		if err != nil {
+			c.Logger.Error(ctx, "Ratio failed", "error", err)
+			return nil, &ValidationError{Message: fmt.Sprintf("invalid date %s, expected format YYYY-MM-DD", *date)}
+		}
+
+		statistic, err := c.SQLRepository.GetDailyStatisticByCountryIdAndDate(ctx, countryId, day)
+		if errors.Is(err, database.ErrNotFound) {
+			err = &NotFoundError{Resource: "statistics", Name: fmt.Sprintf("of %s on %s", countryName, *date)}
+		}
+		if err != nil {
+			c.Logger.Error(ctx, "Ratio failed", "error", err)
+			return nil, err
+		}
+		confirmed, deaths, recovered = statistic.Confirmed, statistic.Deaths, statistic.Recovered
+	}
+
+	values := map[model.RatioMetric]int{
+		model.RatioMetricConfirmed: confirmed,
+		model.RatioMetricDeaths:    deaths,
+		model.RatioMetricRecovered: recovered,
+		model.RatioMetricActive:    confirmed - deaths - recovered,
+	}
+
+	ratio := &model.Ratio{
+		Country:          countryName,
+		Date:             date,
+		Numerator:        numerator,
+		Denominator:      denominator,
+		NumeratorValue:   values[numerator],
+		DenominatorValue: values[denominator],
+	}
+	if ratio.DenominatorValue != 0 {
+		value := float64(ratio.NumeratorValue) / float64(ratio.DenominatorValue)
+		ratio.Value = &value
+		ratio.Percentage = float64Ptr(value * 100)
+	}
+
+	return ratio, nil
+}
@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+)
+
+func TestRatio(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+	countryId := 2
+	date := "2023-03-01"
+	statistic := entity.DailyStatistic{CountryId: countryId, Confirmed: 200, Deaths: 10, Recovered: 150}
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Jordan").Return(countryId, nil)
+	sqlRepositoryInterface.On("UsersCountriesCount", ctx, userId, countryId).Return(1, nil)
+	sqlRepositoryInterface.On("GetDailyStatisticByCountryIdAndDate", ctx, countryId, time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)).Return(statistic, nil)
+
+	ratio, err := covid19Service.Ratio(ctx, userId, "Jordan", model.RatioMetricActive, model.RatioMetricConfirmed, &date)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if ratio.NumeratorValue != 40 || ratio.DenominatorValue != 200 {
+		t.Errorf("expected 40/200; got %v/%v", ratio.NumeratorValue, ratio.DenominatorValue)
+	}
+
+	// Test cases
+	if ratio.Value == nil || *ratio.Value != 0.2 || *ratio.Percentage != 20 {
+		t.Errorf("expected 0.2 (20%%); got %v", ratio.Value)
+	}
+}
+
+func TestRatioWithZeroDenominator(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+	countryId := 2
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Jordan").Return(countryId, nil)
+	sqlRepositoryInterface.On("UsersCountriesCount", ctx, userId, countryId).Return(1, nil)
+	sqlRepositoryInterface.On("GetStatisticByCountryId", ctx, countryId).Return(entity.Statistics{CountryId: countryId}, nil)
+
+	ratio, err := covid19Service.Ratio(ctx, userId, "Jordan", model.RatioMetricDeaths, model.RatioMetricConfirmed, nil)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if ratio.Value != nil || ratio.Percentage != nil {
+		t.Errorf("expected nil value; got %v", ratio.Value)
+	}
+
+	percentage, err := covid19Service.PercentageOfDeathToConfirmed(ctx, userId, "Jordan")
+
+	// Test cases
+	if percentage != 0 || err != nil {
+		t.Errorf("expected 0 percentage and nil error; got %v and %v", percentage, err)
+	}
+}
+
+func TestNegativeRatio(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Atlantis").Return(0, database.ErrNotFound)
+	sqlRepositoryInterface.On("GetCountryIdByName", ctx, "Jordan").Return(2, nil)
+	sqlRepositoryInterface.On("UsersCountriesCount", ctx, 1, 2).Return(0, nil)
+
+	_, err := covid19Service.Ratio(ctx, 1, "Atlantis", model.RatioMetricDeaths, model.RatioMetricConfirmed, nil)
+
+	// Test cases
+	var notFoundError *services.NotFoundError
+	if !errors.As(err, &notFoundError) {
+		t.Errorf("expected not found error; got %v", err)
+	}
+
+	_, err = covid19Service.Ratio(ctx, 1, "Jordan", model.RatioMetricDeaths, model.RatioMetricConfirmed, nil)
+
+	// Test cases
+	var forbiddenError *services.ForbiddenError
+	if !errors.As(err, &forbiddenError) {
+		t.Errorf("expected forbidden error; got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+const (
+	RegionTypeContinent = "continent"
+	RegionTypeWHO       = "who_region"
+	RegionTypeCustom    = "custom"
+
+	// WorldRegion is the implicit region every country belongs to.
+	WorldRegion = "World"
+)
+
+// CreateRegion creates a custom group of countries owned by the user.
+func (c *Covid19Service) CreateRegion(ctx context.Context, userId int, name string, countries []string) (bool, error) {
+	c.Logger.Debug(ctx, "CreateRegion called")
+
+	if name == "" || strings.EqualFold(name, WorldRegion) {
+		c.Logger.Warn(ctx, "invalid region name", "name", name)
+		return false, &ValidationError{Message: fmt.Sprintf("invalid region name %s", name)}
+	}
+
+	count, err := c.SQLRepository.UsersCountById(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "CreateRegion failed", "error", err)
+		return false, err
+	}
+
+	if count == 0 {
+		err := &NotFoundError{Resource: "user with id", Name: strconv.Itoa(userId)}
+		c.Logger.Error(ctx, "CreateRegion failed", "error", err)
+		return false, err
+	}
+
+	// the countries are shared, so they are kept even if the region can't be
+	// created, unlike the region and its memberships
+	countryIds := make([]int, 0, len(countries))
+	for _, country := range countries {
+		countryId, err := c.getOrInsertCountry(ctx, country)
+		if err != nil {
+			return false, err
+		}
+		countryIds = append(countryIds, countryId)
+	}
+
+	_, err = c.SQLRepository.InsertRegion(ctx, name, RegionTypeCustom, userId, countryIds)
+	if errors.Is(err, database.ErrConflict) {
+		err = &ConflictError{Message: fmt.Sprintf("region %s already exists", name)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "CreateRegion failed", "error", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// AddCountryToRegion adds a country to a custom region owned by the user, the
+// shared regions being read-only.
+func (c *Covid19Service) AddCountryToRegion(ctx context.Context, userId int, regionName, countryName string) (bool, error) {
+	c.Logger.Debug(ctx, "AddCountryToRegion called")
+
+	region, err := c.getRegion(ctx, userId, regionName)
+	if err != nil {
+		return false, err
+	}
+	if region.UserId != userId {
+		err := &ForbiddenError{Message: fmt.Sprintf("region %s is shared and can't be changed", regionName)}
+		c.Logger.Warn(ctx, "AddCountryToRegion refused", "region", regionName)
+		return false, err
+	}
+
+	countryId, err := c.getOrInsertCountry(ctx, countryName)
+	if err != nil {
+		return false, err
+	}
+	if err := c.SQLRepository.InsertIntoRegionsCountries(ctx, region.ID, countryId); err != nil {
+		c.Logger.Error(ctx, "AddCountryToRegion failed", "error", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetRegions returns the shared regions and the custom regions of the user.
+func (c *Covid19Service) GetRegions(ctx context.Context, userId int) ([]*model.Region, error) {
+	regions, err := c.SQLRepository.GetRegionsByUserId(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "GetRegions failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]*model.Region, 0, len(regions))
+	for _, region := range regions {
+		result = append(result, &model.Region{Name: region.Name, Type: region.Type})
+	}
+	return result, nil
+}
+
+// GetRegionAggregate sums the statistics of every country of the region and
+// returns them along with the breakdown per country. The World region
+// aggregates every known country. The countries without statistics yet, which
+// nobody follows, are listed apart instead of being counted as zero.
+func (c *Covid19Service) GetRegionAggregate(ctx context.Context, userId int, regionName string) (*model.RegionAggregate, error) {
+	c.Logger.Debug(ctx, "GetRegionAggregate called")
+
+	var statistics []entity.CountryStatistics
+	var err error
+	if strings.EqualFold(regionName, WorldRegion) {
+		regionName = WorldRegion
+		statistics, err = c.SQLRepository.GetAllCountriesStatistics(ctx)
+	} else {
+		var region entity.Region
+		region, err = c.getRegion(ctx, userId, regionName)
+		if err != nil {
+			return nil, err
+		}
+		statistics, err = c.SQLRepository.GetCountriesStatisticsByRegionId(ctx, region.ID)
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "GetRegionAggregate failed", "error", err)
+		return nil, err
+	}
+
+	aggregate := &model.RegionAggregate{
+		Region:           regionName,
+		Countries:        make([]*model.CountryStatistics, 0, len(statistics)),
+		MissingCountries: make([]string, 0),
+	}
+	for _, statistic := range statistics {
+		if statistic.Missing {
+			aggregate.MissingCountries = append(aggregate.MissingCountries, statistic.Name)
+			continue
+		}
+		country := toCountryStatistics(statistic)
+		aggregate.Confirmed += country.Confirmed
+		aggregate.Deaths += country.Deaths
+		aggregate.Recovered += country.Recovered
+		aggregate.Active += country.Active
+		aggregate.Countries = append(aggregate.Countries, country)
+	}
+
+	return aggregate, nil
+}
+
+func (c *Covid19Service) getRegion(ctx context.Context, userId int, regionName string) (entity.Region, error) {
+	region, err := c.SQLRepository.GetRegionByName(ctx, userId, regionName)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "region", Name: regionName}
+		c.Logger.Error(ctx, "getRegion failed", "error", err)
+		return entity.Region{}, err
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "getRegion failed", "error", err)
+		return entity.Region{}, err
+	}
+	return region, nil
+}
+
+func toCountryStatistics(statistic entity.CountryStatistics) *model.CountryStatistics {
+	return &model.CountryStatistics{
+		Name:      statistic.Name,
+		Confirmed: statistic.Confirmed,
+		Deaths:    statistic.Deaths,
+		Recovered: statistic.Recovered,
+		Active:    statistic.Confirmed - statistic.Deaths - statistic.Recovered,
+	}
+}
@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+)
+
+func TestGetRegionAggregate(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+	region := entity.Region{ID: 3, Name: "Middle East", Type: services.RegionTypeCustom, UserId: userId}
+	statistics := []entity.CountryStatistics{
+		{Name: "Jordan", Confirmed: 100, Deaths: 10, Recovered: 50},
+		{Name: "Palestine", Confirmed: 200, Deaths: 20, Recovered: 100},
+	}
+
+	sqlRepositoryInterface.On("GetRegionByName", ctx, userId, region.Name).Return(region, nil)
+	sqlRepositoryInterface.On("GetCountriesStatisticsByRegionId", ctx, region.ID).Return(statistics, nil)
+
+	aggregate, err := covid19Service.GetRegionAggregate(ctx, userId, region.Name)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if aggregate.Confirmed != 300 || aggregate.Deaths != 30 || aggregate.Recovered != 150 || aggregate.Active != 120 {
+		t.Errorf("expected totals 300, 30, 150, 120; got %v, %v, %v, %v", aggregate.Confirmed, aggregate.Deaths, aggregate.Recovered, aggregate.Active)
+	}
+
+	// Test cases
+	if len(aggregate.Countries) != 2 || aggregate.Countries[1].Active != 80 {
+		t.Errorf("expected 2 countries with Palestine having 80 active cases; got %v", aggregate.Countries)
+	}
+}
+
+func TestGetWorldAggregate(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	statistics := []entity.CountryStatistics{
+		{Name: "Jordan", Confirmed: 100, Deaths: 10, Recovered: 50},
+		{Name: "Syria", Confirmed: 50, Deaths: 5, Recovered: 40},
+	}
+
+	sqlRepositoryInterface.On("GetAllCountriesStatistics", ctx).Return(statistics, nil)
+
+	aggregate, err := covid19Service.GetRegionAggregate(ctx, 1, "world")
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if aggregate.Region != services.WorldRegion {
+		t.Errorf("expected World; got %v", aggregate.Region)
+	}
+
+	// Test cases
+	if aggregate.Confirmed != 150 {
+		t.Errorf("expected 150 confirmed; got %v", aggregate.Confirmed)
+	}
+}
+
+func TestNegativeGetRegionAggregate(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	sqlRepositoryInterface.On("GetRegionByName", ctx, 1, "Atlantis").Return(entity.Region{}, database.ErrNotFound)
+
+	aggregate, err := covid19Service.GetRegionAggregate(ctx, 1, "Atlantis")
+
+	// Test cases
+	if aggregate != nil {
+		t.Errorf("expected nil aggregate; got %v", aggregate)
+	}
+
+	// Test cases
+	if err == nil || err.Error() != "region Atlantis not found" {
+		t.Errorf("expected region Atlantis not found error; got %v", err)
+	}
+}
+
+func TestRegionsWithMemoryRepository(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, memoryRepository, userId := newMemoryTestService(t)
+
+	if _, err := covid19Service.CreateRegion(ctx, userId, "Levant", []string{"Palestine", "Jordan"}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	memoryRepository.UpdateArrayOfStatistics(ctx, []entity.Statistics{{CountryId: 1, Confirmed: 200, Deaths: 20, Recovered: 100}, {CountryId: 2, Confirmed: 100, Deaths: 10, Recovered: 50}})
+
+	_, err := covid19Service.CreateRegion(ctx, userId, "Levant", nil)
+
+	// Test cases
+	if err == nil {
+		t.Errorf("expected duplicate region error; got nil")
+	}
+
+	aggregate, err := covid19Service.GetRegionAggregate(ctx, userId, "Levant")
+
+	// Test cases
+	if err != nil || aggregate.Confirmed != 300 || aggregate.Active != 120 || len(aggregate.Countries) != 2 {
+		t.Errorf("expected 300 confirmed and 120 active cases of 2 countries; got %v and %v", aggregate, err)
+	}
+}
+
+func TestNegativeAddCountryToRegion(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, _, userId := newMemoryTestService(t)
+	if _, err := covid19Service.CreateRegion(ctx, userId, "Levant", []string{"Palestine"}); err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	_, err := covid19Service.AddCountryToRegion(ctx, userId, "Asia", "Jordan")
+	var forbiddenErr *services.ForbiddenError
+
+	// Test cases
+	if !errors.As(err, &forbiddenErr) {
+		t.Errorf("expected forbidden error for a shared region; got %v", err)
+	}
+
+	added, err := covid19Service.AddCountryToRegion(ctx, userId, "Levant", "Jordan")
+
+	// Test cases
+	if err != nil || !added {
+		t.Errorf("expected Jordan to be added to the region of the user; got %v and %v", added, err)
+	}
+}
+
+func TestGetRegionAggregateWithUnfollowedCountry(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, memoryRepository, userId := newMemoryTestService(t, "Jordan")
+
+	jordanId, _ := memoryRepository.GetCountryIdByName(ctx, "Jordan")
+	memoryRepository.UpdateArrayOfStatistics(ctx, []entity.Statistics{{CountryId: jordanId, Confirmed: 100, Deaths: 10, Recovered: 50}})
+	// the countries of the shared regions are seeded without statistics
+	syriaId, _ := memoryRepository.InsertCountry(ctx, "Syria")
+	asia, _ := memoryRepository.GetRegionByName(ctx, userId, "Asia")
+	for _, countryId := range []int{jordanId, syriaId} {
+		if err := memoryRepository.InsertIntoRegionsCountries(ctx, asia.ID, countryId); err != nil {
+			t.Fatalf("expected nil error; got %v", err)
+		}
+	}
+
+	aggregate, err := covid19Service.GetRegionAggregate(ctx, userId, "Asia")
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if aggregate.Confirmed != 100 || len(aggregate.Countries) != 1 || aggregate.Countries[0].Name != "Jordan" {
+		t.Errorf("expected the totals of Jordan only; got %v and %v", aggregate.Confirmed, aggregate.Countries)
+	}
+
+	// Test cases
+	if len(aggregate.MissingCountries) != 1 || aggregate.MissingCountries[0] != "Syria" {
+		t.Errorf("expected Syria to be reported without statistics; got %v", aggregate.MissingCountries)
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+// AddSubdivision subscribes the user to a province or state of a country.
+// Only the subdivisions reported by the API can be subscribed to.
+func (c *Covid19Service) AddSubdivision(ctx context.Context, userId int, countryName, name string) (bool, error) {
+	c.Logger.Debug(ctx, "AddSubdivision called")
+
+	count, err := c.SQLRepository.UsersCountById(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "AddSubdivision failed", "error", err)
+		return false, err
+	}
+
+	if count == 0 {
+		err := &NotFoundError{Resource: "user with id", Name: strconv.Itoa(userId)}
+		c.Logger.Error(ctx, "AddSubdivision failed", "error", err)
+		return false, err
+	}
+
+	subdivisionId, err := c.SQLRepository.GetSubdivisionIdByName(ctx, countryName, name)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "subdivision", Name: fmt.Sprintf("%s of %s", name, countryName)}
+		c.Logger.Error(ctx, "AddSubdivision failed", "error", err)
+		return false, err
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "AddSubdivision failed", "error", err)
+		return false, err
+	}
+
+	err = c.SQLRepository.InsertIntoUsersSubdivisions(ctx, userId, subdivisionId)
+	if errors.Is(err, database.ErrConflict) {
+		err = &ConflictError{Message: fmt.Sprintf("user with id %d is already subscribed to %s of %s", userId, name, countryName)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "AddSubdivision failed", "error", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetSubdivisions returns the statistics of the subdivisions the user is
+// subscribed to.
+func (c *Covid19Service) GetSubdivisions(ctx context.Context, userId int) ([]*model.SubdivisionStatistics, error) {
+	statistics, err := c.SQLRepository.GetSubdivisionsStatisticsByUserId(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "GetSubdivisions failed", "error", err)
+		return nil, err
+	}
+	return toSubdivisionsStatistics(statistics), nil
+}
+
+// GetCountrySubdivisions returns the statistics of every known subdivision of
+// the country.
+func (c *Covid19Service) GetCountrySubdivisions(ctx context.Context, countryName string) ([]*model.SubdivisionStatistics, error) {
+	statistics, err := c.SQLRepository.GetSubdivisionsStatisticsByCountryName(ctx, countryName)
+	if err != nil {
+		c.Logger.Error(ctx, "GetCountrySubdivisions failed", "error", err)
+		return nil, err
+	}
+	return toSubdivisionsStatistics(statistics), nil
+}
+
+// SetSubdivisionRollup chooses whether the totals of the country are taken
+// from the API or computed from the sum of its subdivisions. The totals being
+// shared by every user, only the admins can change it.
+func (c *Covid19Service) SetSubdivisionRollup(ctx context.Context, userId int, countryName string, enabled bool) (bool, error) {
+	c.Logger.Debug(ctx, "SetSubdivisionRollup called")
+
+	if !slices.Contains(c.AdminUserIds, userId) {
+		err := &ForbiddenError{Message: "only the admins can change the subdivision rollup"}
+		c.Logger.Error(ctx, "SetSubdivisionRollup failed", "error", err)
+		return false, err
+	}
+
+	count, err := c.SQLRepository.UpdateSubdivisionRollup(ctx, countryName, enabled)
+	if err != nil {
+		c.Logger.Error(ctx, "SetSubdivisionRollup failed", "error", err)
+		return false, err
+	}
+
+	if count == 0 {
+		err = &NotFoundError{Resource: "country", Name: countryName}
+		c.Logger.Error(ctx, "SetSubdivisionRollup failed", "error", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// rollUpSubdivisions refreshes the subdivisions of every country and replaces
+// the totals of the countries configured to roll up from their subdivisions.
+func (c *Covid19Service) rollUpSubdivisions(ctx context.Context, countries map[int]string, statistics []entity.Statistics) []entity.Statistics {
+	totals := c.fetchSubdivisionsFromAPI(ctx, countries)
+
+	countryIds, err := c.SQLRepository.GetCountryIdsWithSubdivisionRollup(ctx)
+	if err != nil {
+		c.Logger.Error(ctx, "rollUpSubdivisions failed", "error", err)
+		return statistics
+	}
+
+	rollup := make(map[int]bool, len(countryIds))
+	for _, countryId := range countryIds {
+		rollup[countryId] = true
+	}
+
+	for index, statistic := range statistics {
+		total, ok := totals[statistic.CountryId]
+		if !ok || !rollup[statistic.CountryId] {
+			continue
+		}
+		statistics[index].Confirmed = total.Confirmed
+		statistics[index].Deaths = total.Deaths
+		statistics[index].Recovered = total.Recovered
+	}
+
+	return statistics
+}
+
+// fetchSubdivisionsFromAPI stores the latest statistics of every province or
+// state reported for the countries and returns their sum per country id.
+func (c *Covid19Service) fetchSubdivisionsFromAPI(ctx context.Context, countries map[int]string) map[int]entity.CovidData {
+	totals := make(map[int]entity.CovidData)
+	for countryId, countryName := range countries {
+		if ctx.Err() != nil {
+			break
+		}
+		subdivisions, err := c.fetchCountrySubdivisions(ctx, countryName)
+		if err != nil {
+			c.Logger.Error(ctx, "fetchSubdivisionsFromAPI failed", "error", err)
+			continue
+		}
+		if len(subdivisions) == 0 {
+			continue
+		}
+
+		var total entity.CovidData
+		for _, subdivision := range subdivisions {
+			subdivisionId, err := c.SQLRepository.UpsertSubdivision(ctx, countryId, subdivision.Province)
+			if err != nil {
+				c.Logger.Error(ctx, "fetchSubdivisionsFromAPI failed", "error", err)
+				continue
+			}
+			err = c.SQLRepository.UpsertSubdivisionStatistic(ctx, entity.SubdivisionStatistics{
+				SubdivisionId: subdivisionId,
+				Confirmed:     subdivision.Confirmed,
+				Deaths:        subdivision.Deaths,
+				Recovered:     subdivision.Recovered,
+			})
+			if err != nil {
+				c.Logger.Error(ctx, "fetchSubdivisionsFromAPI failed", "error", err)
+			}
+
+			total.Confirmed += subdivision.Confirmed
+			total.Deaths += subdivision.Deaths
+			total.Recovered += subdivision.Recovered
+		}
+		totals[countryId] = total
+	}
+
+	return totals
+}
+
+// fetchCountrySubdivisions returns the latest entry of each province or state
+// of the country, countries reported as a whole have none.
+func (c *Covid19Service) fetchCountrySubdivisions(ctx context.Context, countryName string) ([]entity.CovidData, error) {
+	resp, err := c.get(ctx, "live/country", fmt.Sprintf("%s/live/country/%s", Covid19APIURL, countryName))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &UpstreamError{Err: fmt.Errorf("status %d", resp.StatusCode)}
+	}
+
+	var covidDataArray []entity.CovidData
+	err = json.NewDecoder(resp.Body).Decode(&covidDataArray)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries are sorted by date, keep the last one of each province
+	latest := make(map[string]int)
+	subdivisions := make([]entity.CovidData, 0)
+	for _, covidData := range covidDataArray {
+		if covidData.Province == "" {
+			continue
+		}
+		if index, ok := latest[covidData.Province]; ok {
+			subdivisions[index] = covidData
+			continue
+		}
+		latest[covidData.Province] = len(subdivisions)
+		subdivisions = append(subdivisions, covidData)
+	}
+
+	return subdivisions, nil
+}
+
+func toSubdivisionsStatistics(statistics []entity.SubdivisionStatistics) []*model.SubdivisionStatistics {
+	result := make([]*model.SubdivisionStatistics, 0, len(statistics))
+	for _, statistic := range statistics {
+		result = append(result, &model.SubdivisionStatistics{
+			Country:   statistic.Country,
+			Name:      statistic.Name,
+			Confirmed: statistic.Confirmed,
+			Deaths:    statistic.Deaths,
+			Recovered: statistic.Recovered,
+			Active:    statistic.Confirmed - statistic.Deaths - statistic.Recovered,
+		})
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+)
+
+func TestAddSubdivision(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+	subdivisionId := 4
+
+	sqlRepositoryInterface.On("UsersCountById", ctx, userId).Return(1, nil)
+	sqlRepositoryInterface.On("GetSubdivisionIdByName", ctx, "Canada", "Ontario").Return(subdivisionId, nil)
+	sqlRepositoryInterface.On("InsertIntoUsersSubdivisions", ctx, userId, subdivisionId).Return(nil)
+
+	added, err := covid19Service.AddSubdivision(ctx, userId, "Canada", "Ontario")
+
+	// Test cases
+	if added != true {
+		t.Errorf("expected true adding subdivision; got %v", added)
+	}
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+}
+
+func TestNegativeAddSubdivision(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+
+	sqlRepositoryInterface.On("UsersCountById", ctx, userId).Return(1, nil)
+	sqlRepositoryInterface.On("GetSubdivisionIdByName", ctx, "Canada", "Atlantis").Return(0, database.ErrNotFound)
+
+	added, err := covid19Service.AddSubdivision(ctx, userId, "Canada", "Atlantis")
+
+	// Test cases
+	if added != false {
+		t.Errorf("expected false adding subdivision; got %v", added)
+	}
+
+	// Test cases
+	if err == nil || err.Error() != "subdivision Atlantis of Canada not found" {
+		t.Errorf("expected subdivision Atlantis of Canada not found error; got %v", err)
+	}
+}
+
+func TestGetCountrySubdivisions(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	statistics := []entity.SubdivisionStatistics{
+		{SubdivisionId: 1, Country: "Canada", Name: "Ontario", Confirmed: 100, Deaths: 5, Recovered: 60},
+		{SubdivisionId: 2, Country: "Canada", Name: "Quebec", Confirmed: 80, Deaths: 4, Recovered: 70},
+	}
+
+	sqlRepositoryInterface.On("GetSubdivisionsStatisticsByCountryName", ctx, "Canada").Return(statistics, nil)
+
+	subdivisions, err := covid19Service.GetCountrySubdivisions(ctx, "Canada")
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if len(subdivisions) != 2 || subdivisions[0].Active != 35 {
+		t.Errorf("expected 2 subdivisions with 35 active cases in Ontario; got %v", subdivisions)
+	}
+}
+
+func TestSetSubdivisionRollup(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+	covid19Service.AdminUserIds = []int{1}
+
+	sqlRepositoryInterface.On("UpdateSubdivisionRollup", ctx, "Canada", true).Return(1, nil)
+
+	updated, err := covid19Service.SetSubdivisionRollup(ctx, 1, "Canada", true)
+
+	// Test cases
+	if err != nil || !updated {
+		t.Errorf("expected the admin to update the rollup; got %v and %v", updated, err)
+	}
+
+	updated, err = covid19Service.SetSubdivisionRollup(ctx, 2, "Canada", false)
+	var forbiddenErr *services.ForbiddenError
+
+	// Test cases
+	if updated || !errors.As(err, &forbiddenErr) {
+		t.Errorf("expected forbidden error for a user who isn't an admin; got %v and %v", updated, err)
+	}
+
+	// Test cases
+	sqlRepositoryInterface.AssertNumberOfCalls(t, "UpdateSubdivisionRollup", 1)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+)
+
+// SubscribeStatistics streams the new totals of the given countries once the
+// refresher stored them, countries being empty means every country of the
+// user. The channel is closed once ctx is done or the subscriber fell too
+// far behind.
+func (c *Covid19Service) SubscribeStatistics(ctx context.Context, userId int, countries []string) (<-chan *model.StatisticsUpdate, error) {
+	c.Logger.Debug(ctx, "SubscribeStatistics called")
+
+	wanted, err := c.wantedCountries(ctx, userId, countries)
+	if err != nil {
+		c.Logger.Error(ctx, "SubscribeStatistics failed", "error", err)
+		return nil, err
+	}
+
+	published := filterEvents(ctx, c.Events.Subscribe(ctx, events.DefaultBuffer), userId, wanted)
+	updates := make(chan *model.StatisticsUpdate)
+	go func() {
+		defer close(updates)
+		for event := range published {
+			statistics, ok := event.Data.(events.StatisticsUpdated)
+			if !ok {
+				continue
+			}
+			select {
+			case updates <- &model.StatisticsUpdate{
+				Country:   statistics.Country,
+				Confirmed: statistics.Confirmed,
+				Deaths:    statistics.Deaths,
+				Recovered: statistics.Recovered,
+				UpdatedAt: statistics.UpdatedAt.UTC().Format(time.RFC3339),
+			}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// SubscribeEvents streams the events of every country of the user. With
+// lastEventID set, the kept events published after it come first. The
+// channel is closed once ctx is done or the subscriber fell too far behind.
+func (c *Covid19Service) SubscribeEvents(ctx context.Context, userId int, lastEventID *uint64) (<-chan events.Event, error) {
+	c.Logger.Debug(ctx, "SubscribeEvents called")
+
+	wanted, err := c.wantedCountries(ctx, userId, nil)
+	if err != nil {
+		c.Logger.Error(ctx, "SubscribeEvents failed", "error", err)
+		return nil, err
+	}
+
+	if lastEventID != nil {
+		return filterEvents(ctx, c.Events.Resume(ctx, events.DefaultBuffer, *lastEventID), userId, wanted), nil
+	}
+	return filterEvents(ctx, c.Events.Subscribe(ctx, events.DefaultBuffer), userId, wanted), nil
+}
+
+// wantedCountries returns the set of the given countries, or of every
+// country of the user when none is given. Asking for a country the user
+// isn't subscribed to is forbidden.
+func (c *Covid19Service) wantedCountries(ctx context.Context, userId int, countries []string) (map[string]bool, error) {
+	followed, err := c.SQLRepository.GetAllCountriesByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(followed))
+	for _, country := range followed {
+		wanted[country.Name] = len(countries) == 0
+	}
+	for _, country := range countries {
+		if _, ok := wanted[country]; !ok {
+			return nil, &ForbiddenError{Message: fmt.Sprintf("user with id %d isn't subscribed to %s", userId, country)}
+		}
+		wanted[country] = true
+	}
+	return wanted, nil
+}
+
+// filterEvents forwards the published events of the wanted countries meant
+// for the user until published is closed.
+func filterEvents(ctx context.Context, published <-chan events.Event, userId int, wanted map[string]bool) <-chan events.Event {
+	filtered := make(chan events.Event)
+	go func() {
+		defer close(filtered)
+		for event := range published {
+			if !wanted[event.Country] || (event.UserID != 0 && event.UserID != userId) {
+				continue
+			}
+			select {
+			case filtered <- event:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return filtered
+}
+
+// changedStatistics returns the statistics whose totals differ from the
+// previous ones of their country.
+func changedStatistics(previous map[int]entity.Statistics, statistics []entity.Statistics) []entity.Statistics {
+	changed := make([]entity.Statistics, 0, len(statistics))
+	for _, statistic := range statistics {
+		before, ok := previous[statistic.CountryId]
+		if ok && before.Confirmed == statistic.Confirmed && before.Deaths == statistic.Deaths && before.Recovered == statistic.Recovered {
+			continue
+		}
+		changed = append(changed, statistic)
+	}
+	return changed
+}
+
+// publishStatistics tells the subscribers about the totals just stored.
+func (c *Covid19Service) publishStatistics(ctx context.Context, countries map[int]string, statistics []entity.Statistics) {
+	now := time.Now()
+	dropped := 0
+	for _, statistic := range statistics {
+		country := countries[statistic.CountryId]
+		dropped += c.Events.Publish(events.Event{
+			Type:    events.TypeStatisticsChanged,
+			Country: country,
+			Data: events.StatisticsUpdated{
+				Country:   country,
+				Confirmed: statistic.Confirmed,
+				Deaths:    statistic.Deaths,
+				Recovered: statistic.Recovered,
+				UpdatedAt: now,
+			},
+		})
+	}
+	if dropped > 0 {
+		c.Logger.Warn(ctx, "slow subscribers dropped", "dropped", dropped)
+	}
+}
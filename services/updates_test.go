@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/logger"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
+)
+
+func TestSubscribeStatistics(t *testing.T) {
+	// prapare data
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+	covid19Service.Events = events.NewBus(events.DefaultReplay)
+
+	userId := 1
+	countries := []*model.Country{{Name: "Palestine"}, {Name: "Jordan"}}
+	updatedAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	sqlRepositoryInterface.On("GetAllCountriesByUserId", ctx, userId).Return(countries, nil)
+
+	updates, err := covid19Service.SubscribeStatistics(ctx, userId, []string{"Palestine"})
+	covid19Service.Events.Publish(events.Event{Country: "Jordan", Data: events.StatisticsUpdated{Country: "Jordan", Confirmed: 100, UpdatedAt: updatedAt}})
+	covid19Service.Events.Publish(events.Event{Country: "Palestine", Data: events.StatisticsUpdated{Country: "Palestine", Confirmed: 200, Deaths: 20, UpdatedAt: updatedAt}})
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if update := <-updates; update.Country != "Palestine" || update.Confirmed != 200 || update.UpdatedAt != "2023-05-01T12:00:00Z" {
+		t.Errorf("expected the update of Palestine only; got %+v", update)
+	}
+
+	cancel()
+
+	// Test cases
+	select {
+	case _, open := <-updates:
+		if open {
+			t.Errorf("expected no more updates once the context is done")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected the updates to be closed once the context is done")
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+	covid19Service.Events = events.NewBus(events.DefaultReplay)
+
+	userId := 1
+	lastEventID := uint64(1)
+
+	sqlRepositoryInterface.On("GetAllCountriesByUserId", ctx, userId).Return([]*model.Country{{Name: "Palestine"}}, nil)
+
+	for _, country := range []string{"Palestine", "Jordan", "Palestine"} {
+		covid19Service.Events.Publish(events.Event{Type: events.TypeStatisticsChanged, Country: country})
+	}
+
+	published, err := covid19Service.SubscribeEvents(ctx, userId, &lastEventID)
+
+	// Test cases
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	if event := <-published; event.ID != 3 || event.Country != "Palestine" {
+		t.Errorf("expected the missed event of Palestine to be replayed; got %+v", event)
+	}
+}
+
+func TestNegativeSubscribeStatistics(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	covid19Service := services.NewCovid19Service(sqlRepositoryInterface, logger)
+
+	userId := 1
+
+	sqlRepositoryInterface.On("GetAllCountriesByUserId", ctx, userId).Return([]*model.Country{{Name: "Palestine"}}, nil)
+
+	_, err := covid19Service.SubscribeStatistics(ctx, userId, []string{"Jordan"})
+
+	// Test cases
+	var forbiddenError *services.ForbiddenError
+	if !errors.As(err, &forbiddenError) {
+		t.Errorf("expected a forbidden error; got %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
 	"github.com/FaresAbuIram/COVID19-Statistics/entity"
 	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
@@ -13,74 +15,137 @@ import (
 )
 
 type SQLRepository interface {
-	UsersCountById(userId int) (int, error)
-	CountriesCountByname(name string) (int, error)
-	InsertCountry(name string) (int, error)
-	InsertStatistic(countryId int) error
-	GetCountryIdByName(name string) (int, error)
-	InsertIntoUsersCountries(userId, countryId int) error
-	GetAllCountriesByUserId(userId int) ([]*model.Country, error)
-	GetPercentageOfDeathToConfirmedByCountryName(userId int, countryName string) (float64, error)
-	GetTopThreeCountriesByUserIdAndType(userId int, status string) ([]*model.Country, error)
-	GetAllCountries() (map[int]string, error)
-	GetAllStatistics() ([]entity.Statistics, error)
-	UpdateArrayOfStatistics(statistics []entity.Statistics)
-	UsersCountByEmail(email string) (int, error)
-	InsertNewUser(email string, password []byte) error
-	FindUserByEmail(email string) (int, []byte, error)
+	UsersCountById(ctx context.Context, userId int) (int, error)
+	CountriesCountByname(ctx context.Context, name string) (int, error)
+	InsertCountry(ctx context.Context, name string) (int, error)
+	InsertStatistic(ctx context.Context, countryId int) error
+	GetCountryIdByName(ctx context.Context, name string) (int, error)
+	InsertIntoUsersCountries(ctx context.Context, userId, countryId int) error
+	GetAllCountriesByUserId(ctx context.Context, userId int) ([]*model.Country, error)
+	GetTopThreeCountriesByUserIdAndType(ctx context.Context, userId int, status string) ([]*model.Country, error)
+	GetAllCountries(ctx context.Context) (map[int]string, error)
+	GetAllStatistics(ctx context.Context) ([]entity.Statistics, error)
+	UpdateArrayOfStatistics(ctx context.Context, statistics []entity.Statistics)
+	UsersCountByEmail(ctx context.Context, email string) (int, error)
+	InsertNewUser(ctx context.Context, email string, password []byte) error
+	FindUserByEmail(ctx context.Context, email string) (int, []byte, error)
+	UpsertDailyStatistic(ctx context.Context, statistic entity.DailyStatistic) error
+	GetDailyStatisticsByCountryName(ctx context.Context, countryName string, from, to time.Time) ([]entity.DailyStatistic, error)
+	InsertRegion(ctx context.Context, name, regionType string, userId int, countryIds []int) (int, error)
+	GetRegionByName(ctx context.Context, userId int, name string) (entity.Region, error)
+	GetRegionsByUserId(ctx context.Context, userId int) ([]entity.Region, error)
+	InsertIntoRegionsCountries(ctx context.Context, regionId, countryId int) error
+	GetCountriesStatisticsByRegionId(ctx context.Context, regionId int) ([]entity.CountryStatistics, error)
+	GetAllCountriesStatistics(ctx context.Context) ([]entity.CountryStatistics, error)
+	UpsertSubdivision(ctx context.Context, countryId int, name string) (int, error)
+	UpsertSubdivisionStatistic(ctx context.Context, statistic entity.SubdivisionStatistics) error
+	GetSubdivisionIdByName(ctx context.Context, countryName, name string) (int, error)
+	InsertIntoUsersSubdivisions(ctx context.Context, userId, subdivisionId int) error
+	GetSubdivisionsStatisticsByUserId(ctx context.Context, userId int) ([]entity.SubdivisionStatistics, error)
+	GetSubdivisionsStatisticsByCountryName(ctx context.Context, countryName string) ([]entity.SubdivisionStatistics, error)
+	GetCountryIdsWithSubdivisionRollup(ctx context.Context) ([]int, error)
+	UpdateSubdivisionRollup(ctx context.Context, countryName string, enabled bool) (int, error)
+	UsersCountriesCount(ctx context.Context, userId, countryId int) (int, error)
+	GetStatisticByCountryId(ctx context.Context, countryId int) (entity.Statistics, error)
+	GetDailyStatisticByCountryIdAndDate(ctx context.Context, countryId int, date time.Time) (entity.DailyStatistic, error)
+	InsertAlertRule(ctx context.Context, rule entity.AlertRule) (int, error)
+	GetAlertRulesByUserId(ctx context.Context, userId int) ([]entity.AlertRule, error)
+	GetAllAlertRules(ctx context.Context) ([]entity.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, userId, ruleId int) (int, error)
+	UpdateAlertRuleTriggered(ctx context.Context, ruleId int, triggered bool) error
+	InsertAlert(ctx context.Context, alert entity.Alert) (int, error)
+	GetAlertsByUserId(ctx context.Context, userId int, unacknowledgedOnly bool) ([]entity.Alert, error)
+	AcknowledgeAlert(ctx context.Context, userId, alertId int, acknowledgedAt time.Time) (int, error)
+	InsertWebhook(ctx context.Context, webhook entity.Webhook) (int, error)
+	GetWebhooksByUserId(ctx context.Context, userId int) ([]entity.Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, userId, webhookId int) (int, error)
+	InsertWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (int, error)
+	GetWebhookDeliveries(ctx context.Context, userId, webhookId, limit int) ([]entity.WebhookDelivery, error)
+	GetWebhookDeliveryById(ctx context.Context, userId, deliveryId int) (entity.WebhookDelivery, error)
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) error
+	UpsertDigestPreference(ctx context.Context, preference entity.DigestPreference) error
+	GetDigestPreferencesByUserId(ctx context.Context, userId int) ([]entity.DigestPreference, error)
+	GetAllDigestPreferences(ctx context.Context) ([]entity.DigestPreference, error)
+	UpdateDigestSentAt(ctx context.Context, userId int, channel string, sentAt time.Time) error
 }
 type UserService struct {
-	SQLRepository    SQLRepository
-	LoggerCollection logger.LoggerCollection
+	SQLRepository SQLRepository
+	Logger        logger.Logger
+	// TokenSecret signs the tokens returned by Login.
+	TokenSecret string
 }
 
-func NewUserService(sqlRepository SQLRepository, loggerCollection logger.LoggerCollection) *UserService {
+func NewUserService(sqlRepository SQLRepository, logger logger.Logger) *UserService {
 	return &UserService{
-		SQLRepository:    sqlRepository,
-		LoggerCollection: loggerCollection,
+		SQLRepository: sqlRepository,
+		Logger:        logger,
 	}
 }
 
-func (u *UserService) CreateNewUser(email, password string) (bool, error) {
-	count, err := u.SQLRepository.UsersCountByEmail(email)
+func (u *UserService) CreateNewUser(ctx context.Context, email, password string) (bool, error) {
+	if email == "" || password == "" {
+		err := &ValidationError{Message: "email and password are required"}
+		u.Logger.Error(ctx, "CreateNewUser failed", "error", err)
+		return false, err
+	}
+
+	count, err := u.SQLRepository.UsersCountByEmail(ctx, email)
 	if err != nil {
-		u.LoggerCollection.AddErrorLogger(err.Error())
+		u.Logger.Error(ctx, "CreateNewUser failed", "error", err)
 		return false, err
 	}
 
 	if count > 0 {
-		u.LoggerCollection.AddErrorLogger(fmt.Errorf("user with email %s already exists", email).Error())
-		return false, fmt.Errorf("user with email %s already exists", email)
+		err := &ConflictError{Message: fmt.Sprintf("user with email %s already exists", email)}
+		u.Logger.Error(ctx, "CreateNewUser failed", "error", err)
+		return false, err
 	}
 
 	// Hash the password with bcrypt
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		u.LoggerCollection.AddErrorLogger(err.Error())
+		u.Logger.Error(ctx, "CreateNewUser failed", "error", err)
 		return false, err
 	}
 
 	// Insert the new user into the database
-	err = u.SQLRepository.InsertNewUser(email, hashedPassword)
+	err = u.SQLRepository.InsertNewUser(ctx, email, hashedPassword)
+	if errors.Is(err, database.ErrConflict) {
+		// registered concurrently since the count
+		err = &ConflictError{Message: fmt.Sprintf("user with email %s already exists", email)}
+	}
 	if err != nil {
-		u.LoggerCollection.AddErrorLogger(err.Error())
+		u.Logger.Error(ctx, "CreateNewUser failed", "error", err)
 		return false, err
 	}
 
 	return true, nil
 }
 
-func (u *UserService) Login(email, password string) (string, error) {
-	id, hashedPassword, err := u.SQLRepository.FindUserByEmail(email)
+// unknownUserHash is compared with the passwords given for an unknown email,
+// so that they take as long to be refused as the wrong passwords.
+var unknownUserHash = []byte("$2a$10$JEUwvw/FW8u.JnsW.v2YeOj6rQIN67wbom7cn578ydYLUjnO8RM5m")
+
+// Login returns a token for the user, the same error being returned for an
+// unknown email and a wrong password so that the registered emails can't be
+// told apart.
+func (u *UserService) Login(ctx context.Context, email, password string) (string, error) {
+	id, hashedPassword, err := u.SQLRepository.FindUserByEmail(ctx, email)
+	if errors.Is(err, database.ErrNotFound) {
+		hashedPassword, err = unknownUserHash, nil
+		id = 0
+	}
 	if err != nil {
-		u.LoggerCollection.AddErrorLogger(err.Error())
-		return "", fmt.Errorf("user with email %s not found", email)
+		u.Logger.Error(ctx, "Login failed", "error", err)
+		return "", err
 	}
 
 	// Check if the provided password matches the stored password
-	if err := bcrypt.CompareHashAndPassword(hashedPassword, []byte(password)); err != nil {
-		u.LoggerCollection.AddErrorLogger(err.Error())
-		return "", fmt.Errorf("invalid password")
+	if err := bcrypt.CompareHashAndPassword(hashedPassword, []byte(password)); err != nil || id == 0 {
+		u.Logger.Warn(ctx, "Login failed", "email", email, "known", id != 0)
+		return "", &UnauthenticatedError{Message: "invalid email or password"}
 	}
 
 	// Generate a new JWT token for the user
@@ -90,7 +155,7 @@ func (u *UserService) Login(email, password string) (string, error) {
 	})
 
 	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(os.Getenv("TOKEN_SECRET")))
+	tokenString, err := token.SignedString([]byte(u.TokenSecret))
 	if err != nil {
 		return "", err
 	}
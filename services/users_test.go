@@ -1,8 +1,12 @@
 package services_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
 	"github.com/FaresAbuIram/COVID19-Statistics/logger"
 	"github.com/FaresAbuIram/COVID19-Statistics/services"
 	SQLRepositoryInterface "github.com/FaresAbuIram/COVID19-Statistics/services/mocks"
@@ -11,15 +15,16 @@ import (
 
 func TestCreateNewUser(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	userService := services.NewUserService(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
 
 	fakeEmail := "test@test.com"
-	sqlRepositoryInterface.On("UsersCountByEmail", fakeEmail).Return(0, nil)
-	sqlRepositoryInterface.On("InsertNewUser", fakeEmail, mock.AnythingOfType("[]uint8")).Return(nil)
+	sqlRepositoryInterface.On("UsersCountByEmail", ctx, fakeEmail).Return(0, nil)
+	sqlRepositoryInterface.On("InsertNewUser", ctx, fakeEmail, mock.AnythingOfType("[]uint8")).Return(nil)
 
-	register, err := userService.CreateNewUser(fakeEmail, "test")
+	register, err := userService.CreateNewUser(ctx, fakeEmail, "test")
 
 	// Test cases
 	if register != true {
@@ -34,15 +39,16 @@ func TestCreateNewUser(t *testing.T) {
 
 func TestNegativeCreateNewUser(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	userService := services.NewUserService(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
 
 	fakeEmail := "test@test.com"
-	sqlRepositoryInterface.On("UsersCountByEmail", fakeEmail).Return(1, nil)
-	sqlRepositoryInterface.On("InsertNewUser", fakeEmail, mock.AnythingOfType("[]uint8")).Return(nil)
+	sqlRepositoryInterface.On("UsersCountByEmail", ctx, fakeEmail).Return(1, nil)
+	sqlRepositoryInterface.On("InsertNewUser", ctx, fakeEmail, mock.AnythingOfType("[]uint8")).Return(nil)
 
-	register, err := userService.CreateNewUser(fakeEmail, "test")
+	register, err := userService.CreateNewUser(ctx, fakeEmail, "test")
 
 	// Test cases
 	if register != false {
@@ -57,15 +63,16 @@ func TestNegativeCreateNewUser(t *testing.T) {
 
 func TestNegativeLogin(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	userService := services.NewUserService(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
 
 	fakeEmail := "test@test.com"
 	fakePass := []byte("$2a$10$JEUwvw/FW8u.JnsW.v2YeOj6rQIN67wbom7cn578ydYLUjnO8RM5m")
-	sqlRepositoryInterface.On("FindUserByEmail", fakeEmail).Return(1, fakePass, nil)
+	sqlRepositoryInterface.On("FindUserByEmail", ctx, fakeEmail).Return(1, fakePass, nil)
 
-	token, err := userService.Login(fakeEmail, "test1")
+	token, err := userService.Login(ctx, fakeEmail, "test1")
 
 	// Test cases
 	if token != "" {
@@ -73,22 +80,24 @@ func TestNegativeLogin(t *testing.T) {
 	}
 
 	// Test cases
-	if err == nil {
-		t.Errorf("expected got invalid passwor error; got %v", err)
+	var unauthenticatedError *services.UnauthenticatedError
+	if !errors.As(err, &unauthenticatedError) || err.Error() != "invalid email or password" {
+		t.Errorf("expected got invalid email or password error; got %v", err)
 	}
 }
 
 func TestLogin(t *testing.T) {
 	// prapare data
+	ctx := context.Background()
 	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
-	logger := logger.NewLoggerCollection()
-	userService := services.NewUserService(sqlRepositoryInterface, *logger)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
 
 	fakeEmail := "test@test.com"
 	fakePass := []byte("$2a$10$JEUwvw/FW8u.JnsW.v2YeOj6rQIN67wbom7cn578ydYLUjnO8RM5m")
-	sqlRepositoryInterface.On("FindUserByEmail", fakeEmail).Return(1, fakePass, nil)
+	sqlRepositoryInterface.On("FindUserByEmail", ctx, fakeEmail).Return(1, fakePass, nil)
 
-	token, err := userService.Login(fakeEmail, "test")
+	token, err := userService.Login(ctx, fakeEmail, "test")
 
 	// Test cases
 	if token == "" {
@@ -99,4 +108,83 @@ func TestLogin(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected nil error; got %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestConcurrentCreateNewUser(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
+
+	fakeEmail := "test@test.com"
+	sqlRepositoryInterface.On("UsersCountByEmail", ctx, fakeEmail).Return(0, nil)
+	sqlRepositoryInterface.On("InsertNewUser", ctx, fakeEmail, mock.AnythingOfType("[]uint8")).Return(database.ErrConflict)
+
+	_, err := userService.CreateNewUser(ctx, fakeEmail, "test")
+
+	// Test cases
+	var conflictError *services.ConflictError
+	if !errors.As(err, &conflictError) {
+		t.Errorf("expected ConflictError; got %v", err)
+	}
+}
+
+func TestLoginUnknownUser(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
+
+	fakeEmail := "test@test.com"
+	sqlRepositoryInterface.On("FindUserByEmail", ctx, fakeEmail).Return(0, nil, database.ErrNotFound)
+
+	_, err := userService.Login(ctx, fakeEmail, "test")
+
+	// Test cases
+	var unauthenticatedError *services.UnauthenticatedError
+	if !errors.As(err, &unauthenticatedError) || err.Error() != "invalid email or password" {
+		t.Errorf("expected invalid email or password error; got %v", err)
+	}
+}
+
+func TestLoginDatabaseError(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
+
+	fakeEmail := "test@test.com"
+	sqlRepositoryInterface.On("FindUserByEmail", ctx, fakeEmail).Return(0, nil, sql.ErrConnDone)
+
+	_, err := userService.Login(ctx, fakeEmail, "test")
+
+	// Test cases
+	if !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("expected the database error to be kept; got %v", err)
+	}
+}
+
+func TestNegativeCreateNewUserWithoutPassword(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	sqlRepositoryInterface := new(SQLRepositoryInterface.SQLRepositoryInterface)
+	logger := logger.Discard()
+	userService := services.NewUserService(sqlRepositoryInterface, logger)
+
+	register, err := userService.CreateNewUser(ctx, "test@test.com", "")
+
+	// Test cases
+	if register {
+		t.Errorf("expected false registering; got %v", register)
+	}
+
+	// Test cases
+	var validationError *services.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("expected ValidationError; got %v", err)
+	}
+
+	sqlRepositoryInterface.AssertNotCalled(t, "InsertNewUser", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/database"
+	"github.com/FaresAbuIram/COVID19-Statistics/entity"
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// webhookDeliveriesLimit bounds the deliveries listed at once and the ones
+// sent per poll.
+const webhookDeliveriesLimit = 100
+
+// webhookPayload is the JSON body of the deliveries.
+type webhookPayload struct {
+	ID      uint64 `json:"id"`
+	Type    string `json:"type"`
+	Country string `json:"country"`
+	Data    any    `json:"data"`
+}
+
+// CreateWebhook registers a URL the events of the user are posted to. Empty
+// eventTypes or countries mean every event or every country of the user. The
+// secret signing the deliveries is only returned here.
+func (c *Covid19Service) CreateWebhook(ctx context.Context, userId int, url string, eventTypes []model.WebhookEvent, countries []string) (*model.Webhook, error) {
+	c.Logger.Debug(ctx, "CreateWebhook called")
+
+	if err := webhooks.ValidateURL(url); err != nil {
+		c.Logger.Warn(ctx, "invalid webhook", "error", err)
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	types := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !eventType.IsValid() {
+			c.Logger.Warn(ctx, "invalid webhook", "event", eventType)
+			return nil, &ValidationError{Message: fmt.Sprintf("invalid webhook event %s", eventType)}
+		}
+		types = append(types, webhookEventType(eventType))
+	}
+	if _, err := c.wantedCountries(ctx, userId, countries); err != nil {
+		c.Logger.Error(ctx, "CreateWebhook failed", "error", err)
+		return nil, err
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		c.Logger.Error(ctx, "CreateWebhook failed", "error", err)
+		return nil, err
+	}
+	webhook := entity.Webhook{UserId: userId, URL: url, Secret: secret, Events: types, Countries: countries, CreatedAt: time.Now()}
+	webhook.ID, err = c.SQLRepository.InsertWebhook(ctx, webhook)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "user with id", Name: strconv.Itoa(userId)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "CreateWebhook failed", "error", err)
+		return nil, err
+	}
+
+	result := webhookModel(webhook)
+	result.Secret = &secret
+	return result, nil
+}
+
+func (c *Covid19Service) GetWebhooks(ctx context.Context, userId int) ([]*model.Webhook, error) {
+	c.Logger.Debug(ctx, "GetWebhooks called")
+
+	hooks, err := c.SQLRepository.GetWebhooksByUserId(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "GetWebhooks failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]*model.Webhook, 0, len(hooks))
+	for _, webhook := range hooks {
+		result = append(result, webhookModel(webhook))
+	}
+	return result, nil
+}
+
+// DeleteWebhook deletes the webhook of the user along with its deliveries.
+func (c *Covid19Service) DeleteWebhook(ctx context.Context, userId, webhookId int) (bool, error) {
+	c.Logger.Debug(ctx, "DeleteWebhook called")
+
+	count, err := c.SQLRepository.DeleteWebhook(ctx, userId, webhookId)
+	if err == nil && count == 0 {
+		err = &NotFoundError{Resource: "webhook with id", Name: strconv.Itoa(webhookId)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "DeleteWebhook failed", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// GetWebhookDeliveries returns the last deliveries of the webhook of the
+// user, the latest first.
+func (c *Covid19Service) GetWebhookDeliveries(ctx context.Context, userId, webhookId int) ([]*model.WebhookDelivery, error) {
+	c.Logger.Debug(ctx, "GetWebhookDeliveries called")
+
+	hooks, err := c.SQLRepository.GetWebhooksByUserId(ctx, userId)
+	if err != nil {
+		c.Logger.Error(ctx, "GetWebhookDeliveries failed", "error", err)
+		return nil, err
+	}
+	found := false
+	for _, webhook := range hooks {
+		found = found || webhook.ID == webhookId
+	}
+	if !found {
+		err := &NotFoundError{Resource: "webhook with id", Name: strconv.Itoa(webhookId)}
+		c.Logger.Error(ctx, "GetWebhookDeliveries failed", "error", err)
+		return nil, err
+	}
+
+	deliveries, err := c.SQLRepository.GetWebhookDeliveries(ctx, userId, webhookId, webhookDeliveriesLimit)
+	if err != nil {
+		c.Logger.Error(ctx, "GetWebhookDeliveries failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]*model.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, webhookDeliveryModel(delivery))
+	}
+	return result, nil
+}
+
+// RedeliverWebhook queues the payload of a delivery of the user again, as a
+// new delivery sent at the next poll.
+func (c *Covid19Service) RedeliverWebhook(ctx context.Context, userId, deliveryId int) (*model.WebhookDelivery, error) {
+	c.Logger.Debug(ctx, "RedeliverWebhook called")
+
+	delivery, err := c.SQLRepository.GetWebhookDeliveryById(ctx, userId, deliveryId)
+	if errors.Is(err, database.ErrNotFound) {
+		err = &NotFoundError{Resource: "webhook delivery with id", Name: strconv.Itoa(deliveryId)}
+	}
+	if err != nil {
+		c.Logger.Error(ctx, "RedeliverWebhook failed", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	redelivery := entity.WebhookDelivery{
+		WebhookId:     delivery.WebhookId,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        string(model.WebhookDeliveryStatusPending),
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+	redelivery.ID, err = c.SQLRepository.InsertWebhookDelivery(ctx, redelivery)
+	if err != nil {
+		c.Logger.Error(ctx, "RedeliverWebhook failed", "error", err)
+		return nil, err
+	}
+	return webhookDeliveryModel(redelivery), nil
+}
+
+// DeliverWebhooks queues the published events for the webhooks they match
+// and sends the due deliveries every Webhooks.PollInterval, until ctx is
+// done. Falling behind the bus resumes from the last queued event.
+func (c *Covid19Service) DeliverWebhooks(ctx context.Context) {
+	published := c.Events.Subscribe(ctx, events.DefaultBuffer)
+	var lastID uint64
+	ticker := time.NewTicker(c.Webhooks.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-published:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Logger.Warn(ctx, "webhooks fell behind the events", "last_event_id", lastID)
+				published = c.Events.Resume(ctx, events.DefaultBuffer, lastID)
+				continue
+			}
+			lastID = event.ID
+			c.QueueWebhooks(ctx, event)
+		case <-ticker.C:
+			c.DeliverDueWebhooks(ctx)
+		}
+	}
+}
+
+// QueueWebhooks queues a delivery of the event for each webhook it matches:
+// the event is meant for its user, is of one of its types and is about one
+// of its countries, which the user follows.
+func (c *Covid19Service) QueueWebhooks(ctx context.Context, event events.Event) {
+	hooks, err := c.SQLRepository.GetAllWebhooks(ctx)
+	if err != nil {
+		c.Logger.Error(ctx, "QueueWebhooks failed", "error", err)
+		return
+	}
+
+	var payload []byte
+	followed := make(map[int]map[string]bool)
+	now := time.Now()
+	for _, webhook := range hooks {
+		if (event.UserID != 0 && event.UserID != webhook.UserId) || !matchesFilter(webhook.Events, event.Type) || !matchesFilter(webhook.Countries, event.Country) {
+			continue
+		}
+		if _, ok := followed[webhook.UserId]; !ok {
+			if followed[webhook.UserId], err = c.wantedCountries(ctx, webhook.UserId, nil); err != nil {
+				c.Logger.Error(ctx, "QueueWebhooks failed", "error", err)
+				continue
+			}
+		}
+		if !followed[webhook.UserId][event.Country] {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(webhookPayload{ID: event.ID, Type: event.Type, Country: event.Country, Data: event.Data})
+			if err != nil {
+				c.Logger.Error(ctx, "QueueWebhooks failed", "error", err)
+				return
+			}
+		}
+		_, err := c.SQLRepository.InsertWebhookDelivery(ctx, entity.WebhookDelivery{
+			WebhookId:     webhook.ID,
+			Event:         event.Type,
+			Payload:       string(payload),
+			Status:        string(model.WebhookDeliveryStatusPending),
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			c.Logger.Error(ctx, "QueueWebhooks failed", "error", err)
+		}
+	}
+}
+
+// DeliverDueWebhooks sends the deliveries whose next attempt is due, one at a
+// time. A failed one is retried after Webhooks.Backoff, and given up after
+// Webhooks.MaxAttempts.
+func (c *Covid19Service) DeliverDueWebhooks(ctx context.Context) {
+	deliveries, err := c.SQLRepository.GetDueWebhookDeliveries(ctx, time.Now(), webhookDeliveriesLimit)
+	if err != nil {
+		c.Logger.Error(ctx, "DeliverDueWebhooks failed", "error", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		c.deliverWebhook(ctx, delivery)
+	}
+}
+
+// deliverWebhook makes an attempt of the delivery and stores its outcome. An
+// attempt cut by ctx is left to the next poll.
+func (c *Covid19Service) deliverWebhook(ctx context.Context, delivery entity.WebhookDelivery) {
+	ctx, span := tracing.Tracer().Start(ctx, "POST webhook", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethod(http.MethodPost), attribute.Int("webhook.delivery_id", delivery.ID)))
+	defer span.End()
+
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.Webhooks.Timeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, c.Webhooks.Timeout)
+	}
+	status, err := webhooks.Send(attemptCtx, c.WebhookClient, webhooks.Delivery{
+		ID:     delivery.ID,
+		Event:  delivery.Event,
+		URL:    delivery.URL,
+		Secret: delivery.Secret,
+		Body:   []byte(delivery.Payload),
+	})
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+		span.SetAttributes(semconv.HTTPStatusCode(status))
+	}
+	if err == nil {
+		delivery.Status = string(model.WebhookDeliveryStatusSucceeded)
+		delivery.Error = nil
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		c.Logger.Info(ctx, "webhook delivered", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookId, "attempts", delivery.Attempts)
+	} else {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		message := err.Error()
+		delivery.Error = &message
+		if delivery.Attempts >= c.Webhooks.MaxAttempts {
+			delivery.Status = string(model.WebhookDeliveryStatusFailed)
+			delivery.NextAttemptAt = nil
+			c.Logger.Warn(ctx, "webhook delivery given up", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookId, "attempts", delivery.Attempts, "error", err)
+		} else {
+			next := now.Add(c.Webhooks.Backoff(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+			c.Logger.Warn(ctx, "webhook delivery failed", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookId, "attempts", delivery.Attempts, "retry_at", next, "error", err)
+		}
+	}
+
+	if err := c.SQLRepository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		c.Logger.Error(ctx, "deliverWebhook failed", "error", err)
+	}
+}
+
+// matchesFilter tells whether value is in filter, an empty filter matching
+// every value.
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, wanted := range filter {
+		if wanted == value {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookEventType returns the type of the published events named by the
+// GraphQL enum, statistics-changed for STATISTICS_CHANGED.
+func webhookEventType(event model.WebhookEvent) string {
+	return strings.ToLower(strings.ReplaceAll(string(event), "_", "-"))
+}
+
+func webhookEvent(eventType string) model.WebhookEvent {
+	return model.WebhookEvent(strings.ToUpper(strings.ReplaceAll(eventType, "-", "_")))
+}
+
+func webhookModel(webhook entity.Webhook) *model.Webhook {
+	result := &model.Webhook{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    make([]model.WebhookEvent, 0, len(webhook.Events)),
+		Countries: append([]string{}, webhook.Countries...),
+		CreatedAt: webhook.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	for _, eventType := range webhook.Events {
+		result.Events = append(result.Events, webhookEvent(eventType))
+	}
+	return result
+}
+
+func webhookDeliveryModel(delivery entity.WebhookDelivery) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookId,
+		Event:          webhookEvent(delivery.Event),
+		Payload:        delivery.Payload,
+		Status:         model.WebhookDeliveryStatus(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		NextAttemptAt:  formatTime(delivery.NextAttemptAt),
+		CreatedAt:      delivery.CreatedAt.UTC().Format(time.RFC3339),
+		DeliveredAt:    formatTime(delivery.DeliveredAt),
+	}
+}
+
+// formatTime formats the optional time in RFC 3339.
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
+}
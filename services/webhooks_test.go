@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/events"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph/model"
+	"github.com/FaresAbuIram/COVID19-Statistics/services"
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
+)
+
+// webhookReceiver answers the given statuses in turn, then 200, and keeps the
+// requests it got.
+type webhookReceiver struct {
+	mutex    sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (w *webhookReceiver) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	w.requests = append(w.requests, r)
+	w.bodies = append(w.bodies, body)
+	status := http.StatusOK
+	if len(w.statuses) > 0 {
+		status, w.statuses = w.statuses[0], w.statuses[1:]
+	}
+	rw.WriteHeader(status)
+}
+
+func newWebhookTestService(t *testing.T, countries ...string) (*services.Covid19Service, int) {
+	covid19Service, _, userId := newMemoryTestService(t, countries...)
+	covid19Service.Webhooks = webhooks.Config{Timeout: time.Second, MaxAttempts: 2, PollInterval: time.Second}
+	return covid19Service, userId
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, userId := newWebhookTestService(t, "Palestine", "Jordan")
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	covid19Service.WebhookClient = server.Client()
+
+	alertsOnly, err := covid19Service.CreateWebhook(ctx, userId, server.URL+"/alerts", []model.WebhookEvent{model.WebhookEventThresholdAlert}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	palestine, err := covid19Service.CreateWebhook(ctx, userId, server.URL+"/palestine", nil, []string{"Palestine"})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	covid19Service.QueueWebhooks(ctx, events.Event{ID: 1, Type: events.TypeStatisticsChanged, Country: "Palestine", Data: events.StatisticsUpdated{Country: "Palestine", Confirmed: 10}})
+	covid19Service.QueueWebhooks(ctx, events.Event{ID: 2, Type: events.TypeStatisticsChanged, Country: "Jordan"})
+	covid19Service.QueueWebhooks(ctx, events.Event{ID: 3, Type: events.TypeThresholdAlert, Country: "Palestine", UserID: userId + 1})
+	covid19Service.DeliverDueWebhooks(ctx)
+	covid19Service.DeliverDueWebhooks(ctx)
+
+	// Test cases
+	if len(receiver.requests) != 2 || receiver.requests[0].URL.Path != "/palestine" || receiver.requests[1].URL.Path != "/palestine" {
+		t.Fatalf("expected the statistics of Palestine to be sent to its webhook then retried; got %d requests", len(receiver.requests))
+	}
+
+	request, body := receiver.requests[1], receiver.bodies[1]
+	timestamp, _ := strconv.ParseInt(request.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+
+	// Test cases
+	if !webhooks.Verify(*palestine.Secret, request.Header.Get(webhooks.HeaderSignature), time.Unix(timestamp, 0), body) || request.Header.Get(webhooks.HeaderEvent) != events.TypeStatisticsChanged {
+		t.Errorf("expected the delivery to be signed with the secret of the webhook; got %v", request.Header)
+	}
+
+	// Test cases
+	if string(body) != `{"id":1,"type":"statistics-changed","country":"Palestine","data":{"country":"Palestine","confirmed":10,"deaths":0,"recovered":0,"updatedAt":"0001-01-01T00:00:00Z"}}` {
+		t.Errorf("expected the event as payload; got %s", body)
+	}
+
+	deliveries, err := covid19Service.GetWebhookDeliveries(ctx, userId, palestine.ID)
+
+	// Test cases
+	if err != nil || len(deliveries) != 1 || deliveries[0].Status != model.WebhookDeliveryStatusSucceeded || deliveries[0].Attempts != 2 ||
+		*deliveries[0].ResponseStatus != http.StatusOK || deliveries[0].Error != nil || deliveries[0].DeliveredAt == nil {
+		t.Errorf("expected a delivery succeeding on its second attempt; got %+v and %v", deliveries, err)
+	}
+
+	deliveries, err = covid19Service.GetWebhookDeliveries(ctx, userId, alertsOnly.ID)
+
+	// Test cases
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("expected no deliveries for the alerts of another user; got %v and %v", deliveries, err)
+	}
+
+	hooks, err := covid19Service.GetWebhooks(ctx, userId)
+
+	// Test cases
+	if err != nil || len(hooks) != 2 || hooks[0].Secret != nil || len(hooks[0].Events) != 1 || hooks[0].Events[0] != model.WebhookEventThresholdAlert {
+		t.Errorf("expected the webhooks without their secret; got %v and %v", hooks, err)
+	}
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, userId := newWebhookTestService(t, "Palestine")
+	receiver := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	covid19Service.WebhookClient = server.Client()
+
+	webhook, err := covid19Service.CreateWebhook(ctx, userId, server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+
+	covid19Service.QueueWebhooks(ctx, events.Event{ID: 1, Type: events.TypeStatisticsChanged, Country: "Palestine"})
+	for i := 0; i < 3; i++ {
+		covid19Service.DeliverDueWebhooks(ctx)
+	}
+	deliveries, err := covid19Service.GetWebhookDeliveries(ctx, userId, webhook.ID)
+
+	// Test cases
+	if err != nil || len(deliveries) != 1 || deliveries[0].Status != model.WebhookDeliveryStatusFailed || deliveries[0].Attempts != 2 ||
+		deliveries[0].NextAttemptAt != nil || deliveries[0].Error == nil || *deliveries[0].Error != "webhook answered 503 Service Unavailable" {
+		t.Errorf("expected the delivery to be given up after 2 attempts; got %+v and %v", deliveries, err)
+	}
+
+	redelivery, err := covid19Service.RedeliverWebhook(ctx, userId, deliveries[0].ID)
+	covid19Service.DeliverDueWebhooks(ctx)
+	deliveries, _ = covid19Service.GetWebhookDeliveries(ctx, userId, webhook.ID)
+
+	// Test cases
+	if err != nil || len(deliveries) != 2 || deliveries[0].ID != redelivery.ID || deliveries[0].Status != model.WebhookDeliveryStatusSucceeded || deliveries[0].Payload != deliveries[1].Payload {
+		t.Errorf("expected the payload to be delivered again; got %+v and %v", deliveries, err)
+	}
+}
+
+func TestNegativeCreateWebhook(t *testing.T) {
+	// prapare data
+	ctx := context.Background()
+	covid19Service, userId := newWebhookTestService(t, "Palestine")
+
+	_, err := covid19Service.CreateWebhook(ctx, userId, "ftp://example.com", nil, nil)
+
+	// Test cases
+	var validationError *services.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("expected a validation error; got %v", err)
+	}
+
+	_, err = covid19Service.CreateWebhook(ctx, userId, "https://example.com", nil, []string{"Jordan"})
+
+	// Test cases
+	var forbiddenError *services.ForbiddenError
+	if !errors.As(err, &forbiddenError) {
+		t.Errorf("expected a forbidden error; got %v", err)
+	}
+
+	_, err = covid19Service.RedeliverWebhook(ctx, userId, 42)
+
+	// Test cases
+	var notFoundError *services.NotFoundError
+	if !errors.As(err, &notFoundError) {
+		t.Errorf("expected a not found error; got %v", err)
+	}
+
+	_, err = covid19Service.GetWebhookDeliveries(ctx, userId, 42)
+
+	// Test cases
+	if !errors.As(err, &notFoundError) {
+		t.Errorf("expected a not found error; got %v", err)
+	}
+}
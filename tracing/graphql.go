@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GraphQL returns the gqlgen extension tracing the operations and the fields
+// computed by a resolver, the other fields being plain struct reads.
+func GraphQL() graphql.HandlerExtension {
+	return graphQLExtension{}
+}
+
+type graphQLExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = graphQLExtension{}
+
+func (graphQLExtension) ExtensionName() string {
+	return "Tracing"
+}
+
+func (graphQLExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (graphQLExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	operationContext := graphql.GetOperationContext(ctx)
+	name := "graphql"
+	var attributes []trace.SpanStartOption
+	if operation := operationContext.Operation; operation != nil {
+		name = "graphql " + string(operation.Operation)
+		attributes = append(attributes, trace.WithAttributes(semconv.GraphqlOperationTypeKey.String(string(operation.Operation))))
+	}
+	if operationContext.OperationName != "" {
+		name += " " + operationContext.OperationName
+		attributes = append(attributes, trace.WithAttributes(semconv.GraphqlOperationName(operationContext.OperationName)))
+	}
+
+	ctx, span := Tracer().Start(ctx, name, attributes...)
+	defer span.End()
+	response := next(ctx)
+	if response != nil && len(response.Errors) != 0 {
+		span.SetStatus(codes.Error, response.Errors.Error())
+	}
+	return response
+}
+
+func (graphQLExtension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fieldContext := graphql.GetFieldContext(ctx)
+	if fieldContext == nil || !fieldContext.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := Tracer().Start(ctx, fieldContext.Object+"."+fieldContext.Field.Name)
+	defer span.End()
+	result, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return result, err
+}
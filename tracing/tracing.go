@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the app in the traces.
+const ServiceName = "covid19-statistics"
+
+// Exporters the spans may be sent to.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Config tells where the spans are exported.
+type Config struct {
+	// Exporter is ExporterNone, ExporterStdout, ExporterFile or ExporterOTLP.
+	Exporter string
+	// File is the file the spans are appended to by ExporterFile.
+	File string
+	// OTLPEndpoint is the URL of the OTLP/HTTP collector, such as
+	// http://localhost:4318, the OTEL_EXPORTER_OTLP_* variables apply when
+	// empty.
+	OTLPEndpoint string
+	// SampleRatio is the part of the traces started by the app that are
+	// recorded, the ones started by the callers follow their decision.
+	SampleRatio float64
+}
+
+// Validate checks the exporter and its settings.
+func (c Config) Validate() error {
+	switch c.Exporter {
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+	case ExporterFile:
+		if c.File == "" {
+			return errors.New("TRACING_FILE is required by the file exporter")
+		}
+	default:
+		return fmt.Errorf("invalid TRACING_EXPORTER %s, expected %s, %s, %s or %s", c.Exporter, ExporterNone, ExporterStdout, ExporterFile, ExporterOTLP)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("invalid TRACING_SAMPLE_RATIO %v, expected a number between 0 and 1", c.SampleRatio)
+	}
+	if c.OTLPEndpoint != "" {
+		endpoint, err := url.Parse(c.OTLPEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("invalid TRACING_OTLP_ENDPOINT %s, expected an http or https URL", c.OTLPEndpoint)
+		}
+	}
+	return nil
+}
+
+// Setup installs the global tracer provider exporting the spans as
+// configured, and the W3C trace context propagator. The returned function
+// flushes the pending spans and releases the exporter.
+func Setup(ctx context.Context, config Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if config.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closer, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch config.Exporter {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case ExporterFile:
+		file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening tracing file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file, nil
+	default:
+		var options []otlptracehttp.Option
+		if config.OTLPEndpoint != "" {
+			endpoint, _ := url.Parse(config.OTLPEndpoint)
+			options = append(options, otlptracehttp.WithEndpoint(endpoint.Host))
+			if endpoint.Scheme == "http" {
+				options = append(options, otlptracehttp.WithInsecure())
+			}
+			if endpoint.Path != "" && endpoint.Path != "/" {
+				options = append(options, otlptracehttp.WithURLPath(endpoint.Path))
+			}
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		return exporter, nil, err
+	}
+}
+
+// Tracer returns the tracer of the app, taken from the global provider so
+// that nothing is recorded until Setup is called.
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/FaresAbuIram/COVID19-Statistics")
+}
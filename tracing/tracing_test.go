@@ -0,0 +1,81 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/FaresAbuIram/COVID19-Statistics/graph"
+	"github.com/FaresAbuIram/COVID19-Statistics/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestGraphQL(t *testing.T) {
+	// prapare data
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	h := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	h.Use(tracing.GraphQL())
+	request := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query": "query Probe { __typename }", "operationName": "Probe"}`))
+	request.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+
+	// Test cases
+	if len(spans) != 1 || spans[0].Name() != "graphql query Probe" {
+		names := make([]string, 0, len(spans))
+		for _, span := range spans {
+			names = append(names, span.Name())
+		}
+		t.Errorf("expected a single graphql query Probe span; got %v", names)
+	}
+}
+
+func TestSetupFile(t *testing.T) {
+	// prapare data
+	path := filepath.Join(t.TempDir(), "spans.json")
+	stop, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterFile, File: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("expected nil error; got %v", err)
+	}
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	_, span := tracing.Tracer().Start(context.Background(), "refresh statistics")
+	span.End()
+	err = stop(context.Background())
+
+	// Test cases
+	if err != nil {
+		t.Errorf("expected nil error; got %v", err)
+	}
+
+	// Test cases
+	content, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(content), `"Name":"refresh statistics"`) {
+		t.Errorf("expected the span to be written to the file; got %s and %v", content, err)
+	}
+}
+
+func TestNegativeConfigValidate(t *testing.T) {
+	// Test cases
+	for _, config := range []tracing.Config{
+		{Exporter: "jaeger", SampleRatio: 1},
+		{Exporter: tracing.ExporterFile, SampleRatio: 1},
+		{Exporter: tracing.ExporterOTLP, SampleRatio: 2},
+		{Exporter: tracing.ExporterOTLP, OTLPEndpoint: "localhost:4318", SampleRatio: 1},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("expected invalid config error for %+v; got nil", config)
+		}
+	}
+}
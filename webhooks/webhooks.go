@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Headers of the deliveries.
+const (
+	// HeaderSignature holds sha256= and the hex HMAC-SHA256 of the timestamp,
+	// a dot and the body, keyed by the secret of the webhook.
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderTimestamp holds the Unix time the delivery was signed at, so that
+	// receivers can refuse the old ones.
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	// HeaderDelivery holds the delivery ID, the same across its retries.
+	HeaderDelivery = "X-Webhook-Delivery"
+)
+
+// Config tells how the deliveries are sent and retried.
+type Config struct {
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery is given up.
+	MaxAttempts int
+	// RetryDelay is the wait after the first failed attempt, doubled after
+	// each of the next ones up to MaxRetryDelay.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// PollInterval is how often the due deliveries are looked up.
+	PollInterval time.Duration
+}
+
+// Validate checks the settings.
+func (c Config) Validate() error {
+	if c.Timeout <= 0 {
+		return fmt.Errorf("invalid WEBHOOK_TIMEOUT %s, expected a positive duration", c.Timeout)
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %d, expected at least 1", c.MaxAttempts)
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("invalid WEBHOOK_POLL_INTERVAL %s, expected a positive duration", c.PollInterval)
+	}
+	return nil
+}
+
+// Backoff returns the wait before the next attempt once attempts failed.
+func (c Config) Backoff(attempts int) time.Duration {
+	delay := c.RetryDelay
+	for i := 1; i < attempts && (c.MaxRetryDelay <= 0 || delay < c.MaxRetryDelay); i++ {
+		delay *= 2
+	}
+	if c.MaxRetryDelay > 0 && delay > c.MaxRetryDelay {
+		return c.MaxRetryDelay
+	}
+	return delay
+}
+
+// NewSecret returns a random secret to sign the deliveries with.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// ValidateURL checks that deliveries can be sent to rawURL.
+func ValidateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook URL %s, expected an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// Sign returns the value of HeaderSignature.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify tells whether signature is the one of the body sent at timestamp,
+// for the receivers.
+func Verify(secret, signature string, timestamp time.Time, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// Delivery is a payload to send to a webhook.
+type Delivery struct {
+	ID     int
+	Event  string
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+// StatusError is returned when the webhook answers with a status other than
+// 2xx.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook answered %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Send posts the signed delivery with client and returns the status code of
+// the response, 0 when there is none. The attempt is bounded by ctx.
+func Send(ctx context.Context, client *http.Client, delivery Delivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "covid19-statistics-webhooks")
+	request.Header.Set(HeaderSignature, Sign(delivery.Secret, now, delivery.Body))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	request.Header.Set(HeaderEvent, delivery.Event)
+	request.Header.Set(HeaderDelivery, strconv.Itoa(delivery.ID))
+
+	resp, err := client.Do(request)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			// without the URL, which may hold credentials
+			err = urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drained so that the connection is reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,149 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/FaresAbuIram/COVID19-Statistics/webhooks"
+)
+
+func TestSend(t *testing.T) {
+	// prapare data
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	status, err := webhooks.Send(context.Background(), server.Client(), webhooks.Delivery{
+		ID:     7,
+		Event:  "threshold-alert",
+		URL:    server.URL,
+		Secret: "secret",
+		Body:   []byte(`{"type":"threshold-alert"}`),
+	})
+
+	// Test cases
+	if status != http.StatusNoContent || err != nil {
+		t.Fatalf("expected 204 and nil error; got %d and %v", status, err)
+	}
+
+	// Test cases
+	if request.Header.Get(webhooks.HeaderEvent) != "threshold-alert" || request.Header.Get(webhooks.HeaderDelivery) != "7" || string(body) != `{"type":"threshold-alert"}` {
+		t.Errorf("expected the event, the delivery ID and the body to be sent; got %v and %s", request.Header, body)
+	}
+
+	timestamp, err := strconv.ParseInt(request.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+
+	// Test cases
+	if err != nil || !webhooks.Verify("secret", request.Header.Get(webhooks.HeaderSignature), time.Unix(timestamp, 0), body) {
+		t.Errorf("expected a valid signature; got %s at %s", request.Header.Get(webhooks.HeaderSignature), request.Header.Get(webhooks.HeaderTimestamp))
+	}
+
+	// Test cases
+	if webhooks.Verify("other", request.Header.Get(webhooks.HeaderSignature), time.Unix(timestamp, 0), body) {
+		t.Errorf("expected the signature not to match another secret")
+	}
+}
+
+func TestSendFailure(t *testing.T) {
+	// prapare data
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	status, err := webhooks.Send(context.Background(), server.Client(), webhooks.Delivery{URL: server.URL})
+	var statusErr *webhooks.StatusError
+
+	// Test cases
+	if status != http.StatusBadGateway || !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected 502 and a *StatusError; got %d and %v", status, err)
+	}
+
+	server.Close()
+	status, err = webhooks.Send(context.Background(), server.Client(), webhooks.Delivery{URL: server.URL})
+
+	// Test cases
+	if status != 0 || err == nil {
+		t.Errorf("expected 0 and an error once the server is closed; got %d and %v", status, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	// prapare data
+	config := webhooks.Config{RetryDelay: time.Minute, MaxRetryDelay: 5 * time.Minute}
+
+	// Test cases
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 3: 4 * time.Minute, 4: 5 * time.Minute, 30: 5 * time.Minute} {
+		if delay := config.Backoff(attempts); delay != want {
+			t.Errorf("expected %s after %d attempts; got %s", want, attempts, delay)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	// Test cases
+	for rawURL, valid := range map[string]bool{"https://example.com/hook": true, "http://localhost:9000": true, "ftp://example.com": false, "example.com": false, "": false} {
+		if err := webhooks.ValidateURL(rawURL); (err == nil) != valid {
+			t.Errorf("expected %q valid to be %v; got %v", rawURL, valid, err)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	// prapare data
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := webhooks.NewClient(time.Second)
+
+	status, err := webhooks.Send(context.Background(), client, webhooks.Delivery{ID: 1, URL: server.URL})
+
+	// Test cases
+	if err == nil || status != 0 {
+		t.Errorf("expected the loopback server to be refused; got %d and %v", status, err)
+	}
+
+	// Test cases
+	if err := client.CheckRedirect(httptest.NewRequest(http.MethodPost, "/", nil), nil); !errors.Is(err, http.ErrUseLastResponse) {
+		t.Errorf("expected the redirects not to be followed; got %v", err)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	// Test cases
+	for address, want := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"100.64.0.1":           false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"0.0.0.0":              false,
+		"::":                   false,
+		"224.0.0.1":            false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.216.34": true,
+	} {
+		if got := webhooks.IsPublicAddr(netip.MustParseAddr(address)); got != want {
+			t.Errorf("%s: expected %v; got %v", address, want, got)
+		}
+	}
+}